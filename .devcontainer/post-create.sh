@@ -20,7 +20,7 @@ p2=$!
     eval $(grep -F = backend/ctrlplane/monolith/.env | sed 's/^/export /')
 
     ( cd backend/ctrlplane/monolith; PREMISES_MODE=web go run . migrate )
-    ( cd backend/ctrlplane/pmctl; go run . user add -u admin -p password --initialized )
+    ( cd backend/ctrlplane/pmctl; go run . user add -u admin -p password --initialized --scope admin )
 ) &
 p3=$!
 
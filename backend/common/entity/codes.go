@@ -13,14 +13,33 @@ const (
 	ErrRequiresAuth     ErrorCode = 10
 	ErrBackup           ErrorCode = 11
 	ErrAgain            ErrorCode = 12
+	ErrNotFound         ErrorCode = 13
+	ErrInvalidWorld     ErrorCode = 14
+	ErrWorldExists      ErrorCode = 15
+	ErrInvalidDatapack  ErrorCode = 16
+	ErrInvalidModSet    ErrorCode = 17
 )
 
 const (
-	InfoSnapshotDone     InfoCode = 1
-	InfoSnapshotError    InfoCode = 2
-	InfoNoSnapshot       InfoCode = 3
-	InfoErrRunnerPrepare InfoCode = 100
-	InfoErrRunnerStop    InfoCode = 101
+	InfoSnapshotDone           InfoCode = 1
+	InfoSnapshotError          InfoCode = 2
+	InfoNoSnapshot             InfoCode = 3
+	InfoReconfigureDone        InfoCode = 4
+	InfoReconfigureError       InfoCode = 5
+	InfoScheduledLaunch        InfoCode = 6
+	InfoScheduledStop          InfoCode = 7
+	InfoScheduledLaunchSkipped InfoCode = 8
+	InfoAutoBackupStarted      InfoCode = 9
+	InfoAutoBackupDone         InfoCode = 10
+	InfoAutoBackupError        InfoCode = 11
+	InfoDatapackError          InfoCode = 12
+	InfoStopScheduled          InfoCode = 13
+	InfoStopCancelled          InfoCode = 14
+	InfoNoPendingStop          InfoCode = 15
+	InfoErrRunnerPrepare       InfoCode = 100
+	InfoErrRunnerStop          InfoCode = 101
+	InfoErrScheduledLaunch     InfoCode = 102
+	InfoErrScheduledStop       InfoCode = 103
 )
 
 const (
@@ -7,10 +7,14 @@ import (
 type EventType string
 
 const (
-	EventHello   EventType = "hello"
-	EventStatus  EventType = "status"
-	EventInfo    EventType = "info"
-	EventStarted EventType = "started"
+	EventHello     EventType = "hello"
+	EventStatus    EventType = "status"
+	EventInfo      EventType = "info"
+	EventStarted   EventType = "started"
+	EventPlayers   EventType = "players"
+	EventConsole   EventType = "console"
+	EventLogs      EventType = "logs"
+	EventDatapacks EventType = "datapacks"
 )
 
 func (ev EventType) String() string {
@@ -20,6 +24,8 @@ func (ev EventType) String() string {
 type StatusExtra struct {
 	EventCode entity.EventCode `json:"eventCode"`
 	Progress  int              `json:"progress"`
+	// CrashReport is the ID of the crash report uploaded with EventCrashed, if any.
+	CrashReport string `json:"crashReport,omitempty"`
 }
 
 type InfoExtra struct {
@@ -43,6 +49,39 @@ type StartedExtra struct {
 		Name string `json:"name"`
 		Seed string `json:"seed"`
 	} `json:"world"`
+	// CommandLine is the command which the server was launched with.
+	CommandLine []string `json:"commandLine,omitempty"`
+}
+
+type PlayersExtra struct {
+	MaxPlayers int      `json:"maxPlayers"`
+	Players    []string `json:"players"`
+}
+
+type ConsoleExtra struct {
+	ID       string `json:"id"`
+	Response string `json:"response"`
+	Error    string `json:"error,omitempty"`
+}
+
+type LogLine struct {
+	Time    int64  `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type LogsExtra struct {
+	Lines []LogLine `json:"lines"`
+}
+
+type DatapackState struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// DatapacksExtra reports datapacks which the runner installed into the world.
+type DatapacksExtra struct {
+	Datapacks []DatapackState `json:"datapacks"`
 }
 
 type RequestMeta struct {
@@ -50,12 +89,16 @@ type RequestMeta struct {
 }
 
 type Event struct {
-	Type     EventType     `json:"type"`
-	Metadata RequestMeta   `json:"metadata"`
-	Hello    *HelloExtra   `json:"hello,omitempty"`
-	Status   *StatusExtra  `json:"status,omitempty"`
-	Info     *InfoExtra    `json:"info,omitempty"`
-	Started  *StartedExtra `json:"started,omitempty"`
+	Type      EventType       `json:"type"`
+	Metadata  RequestMeta     `json:"metadata"`
+	Hello     *HelloExtra     `json:"hello,omitempty"`
+	Status    *StatusExtra    `json:"status,omitempty"`
+	Info      *InfoExtra      `json:"info,omitempty"`
+	Started   *StartedExtra   `json:"started,omitempty"`
+	Players   *PlayersExtra   `json:"players,omitempty"`
+	Console   *ConsoleExtra   `json:"console,omitempty"`
+	Logs      *LogsExtra      `json:"logs,omitempty"`
+	Datapacks *DatapacksExtra `json:"datapacks,omitempty"`
 }
 
 type ActionType string
@@ -70,23 +113,38 @@ const (
 	ActionUndo        ActionType = "undo"
 	ActionReconfigure ActionType = "reconfigure"
 	ActionConnReq     ActionType = "connectionRequest"
+	ActionConsole     ActionType = "console"
+	ActionCancelStop  ActionType = "cancelStop"
 )
 
 type SnapshotConfig struct {
 	Slot int `json:"slot"`
 }
 
+// StopConfig configures how the server stops.
+type StopConfig struct {
+	// Delay is the number of seconds until the server stops. Players are notified of it in game.
+	Delay int `json:"delay"`
+}
+
 type ConnReqInfo struct {
 	ConnectionID string `json:"connectionId"`
 	Endpoint     string `json:"endpoint"`
 	ServerCert   string `json:"serverCert"`
 }
 
+type ConsoleRequest struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+}
+
 type Action struct {
 	Type     ActionType      `json:"type"`
 	Actor    int             `json:"actor"`
 	Metadata RequestMeta     `json:"metadata"`
 	Config   *GameConfig     `json:"config,omitempty"`
 	Snapshot *SnapshotConfig `json:"snapshot,omitempty"`
+	Stop     *StopConfig     `json:"stop,omitempty"`
 	ConnReq  *ConnReqInfo    `json:"connectionRequestInfo,omitempty"`
+	Console  *ConsoleRequest `json:"console,omitempty"`
 }
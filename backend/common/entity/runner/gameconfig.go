@@ -10,6 +10,17 @@ type GameConfig struct {
 		ServerPropOverride map[string]string `json:"serverPropOverride"`
 		JavaVersion        int               `json:"javaVersion"`
 		InactiveTimeout    int               `json:"inactiveTimeout"`
+		// Flavor is the server distribution to launch. Empty means FlavorVanilla.
+		Flavor string `json:"flavor"`
+		// ModSet is the name of the mod set installed into mods, or plugins for Paper. Empty means none.
+		ModSet string `json:"modSet"`
+		// MaxCrashLoops is the number of consecutive crashes after which the server is not restarted anymore.
+		// Zero means the default of the runner.
+		MaxCrashLoops int `json:"maxCrashLoops"`
+		// JVMProfile is the set of JVM options to launch the server with. Empty means JVMProfileDefault.
+		JVMProfile string `json:"jvmProfile"`
+		// JVMArgs is the extra JVM options used with JVMProfileCustom.
+		JVMArgs []string `json:"jvmArgs"`
 	} `json:"server"`
 	World struct {
 		ShouldGenerate bool   `json:"shouldGenerate"`
@@ -18,6 +29,10 @@ type GameConfig struct {
 		Seed           string `json:"seed"`
 		LevelType      string `json:"levelType"`
 		Difficulty     string `json:"difficulty"`
+		BackupFormat   string `json:"backupFormat"`
+		// AutoBackupInterval is the interval in minutes between backups taken while the server is running.
+		// Zero disables them.
+		AutoBackupInterval int `json:"autoBackupInterval"`
 	} `json:"world"`
 	Motd      string   `json:"motd"`
 	Operators []string `json:"operators"`
@@ -35,3 +50,55 @@ type Config struct {
 	Observability ObservabilityConfig `json:"observability"`
 	GameConfig    GameConfig          `json:"gameConfig"`
 }
+
+const (
+	// BackupFormatArchive uploads the whole world as a .tar.zst archive.
+	BackupFormatArchive = "archive"
+	// BackupFormatIncremental uploads only changed files with a manifest.
+	BackupFormatIncremental = "incremental"
+)
+
+const (
+	FlavorVanilla  = "vanilla"
+	FlavorFabric   = "fabric"
+	FlavorQuilt    = "quilt"
+	FlavorPaper    = "paper"
+	FlavorForge    = "forge"
+	FlavorNeoForge = "neoforge"
+)
+
+// IsValidFlavor reports whether flavor is a server distribution which runners can install.
+func IsValidFlavor(flavor string) bool {
+	switch flavor {
+	case FlavorVanilla, FlavorFabric, FlavorQuilt, FlavorPaper, FlavorForge, FlavorNeoForge:
+		return true
+	}
+	return false
+}
+
+const (
+	// JVMProfileDefault launches the server only with the heap size.
+	JVMProfileDefault = "default"
+	// JVMProfileAikar uses the G1 GC tuned with Aikar's flags.
+	JVMProfileAikar = "aikar"
+	// JVMProfileZGC uses ZGC, which suits large heaps.
+	JVMProfileZGC = "zgc"
+	// JVMProfileCustom uses the extra JVM options given by users.
+	JVMProfileCustom = "custom"
+)
+
+const (
+	// ZGCMinJavaVersion is the Java version from which generational ZGC is available.
+	ZGCMinJavaVersion = 21
+	// ZGCMinHeapSize is the heap size in MiB below which ZGC is not worth using.
+	ZGCMinHeapSize = 8 * 1024
+)
+
+// IsValidJVMProfile reports whether profile is a JVM profile which runners can launch the server with.
+func IsValidJVMProfile(profile string) bool {
+	switch profile {
+	case JVMProfileDefault, JVMProfileAikar, JVMProfileZGC, JVMProfileCustom:
+		return true
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/kofuk/premises/backend/common/entity"
 )
@@ -27,8 +28,9 @@ type SessionState struct {
 }
 
 type SessionData struct {
-	LoggedIn    bool   `json:"loggedIn"`
-	AccessToken string `json:"accessToken"`
+	LoggedIn    bool     `json:"loggedIn"`
+	AccessToken string   `json:"accessToken"`
+	Scopes      []string `json:"scopes"`
 }
 
 type MCVersion struct {
@@ -43,10 +45,112 @@ type PasswordCredential struct {
 	Password string `json:"password"`
 }
 
+type AddUserReq struct {
+	UserName string   `json:"userName"`
+	Password string   `json:"password"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+type CreatePersonalAccessTokenReq struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type PersonalAccessToken struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+type CreatedPersonalAccessToken struct {
+	PersonalAccessToken
+	// Token is only returned once on creation.
+	Token string `json:"token"`
+}
+
+type Schedule struct {
+	ID           uint          `json:"id"`
+	ServerID     string        `json:"serverId"`
+	Name         string        `json:"name"`
+	LaunchAt     string        `json:"launchAt"`
+	StopAt       string        `json:"stopAt"`
+	Timezone     string        `json:"timezone"`
+	Enabled      bool          `json:"enabled"`
+	Config       PendingConfig `json:"config"`
+	NextLaunchAt *time.Time    `json:"nextLaunchAt"`
+	NextStopAt   *time.Time    `json:"nextStopAt"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
+
+// StopReq describes how the server stops. The body can be omitted to stop immediately.
+type StopReq struct {
+	// Delay is the number of seconds to count down in game before the server stops.
+	Delay int `json:"delay"`
+}
+
+// CreateScheduleReq describes a new schedule.
+// LaunchAt and StopAt are cron specs (e.g. "0 19 * * 1-5") evaluated in Timezone.
+// If Config is omitted, the current config of the server is used.
+type CreateScheduleReq struct {
+	ServerID string         `json:"serverId"`
+	Name     string         `json:"name"`
+	LaunchAt string         `json:"launchAt"`
+	StopAt   string         `json:"stopAt"`
+	Timezone string         `json:"timezone"`
+	Enabled  *bool          `json:"enabled,omitempty"`
+	Config   *PendingConfig `json:"config,omitempty"`
+}
+
+// UpdateScheduleReq describes changes to a schedule.
+// Omitted fields are left unchanged.
+type UpdateScheduleReq struct {
+	Name     *string        `json:"name,omitempty"`
+	LaunchAt *string        `json:"launchAt,omitempty"`
+	StopAt   *string        `json:"stopAt,omitempty"`
+	Timezone *string        `json:"timezone,omitempty"`
+	Enabled  *bool          `json:"enabled,omitempty"`
+	Config   *PendingConfig `json:"config,omitempty"`
+}
+
 type WorldGeneration struct {
-	Gen       string `json:"gen"`
-	ID        string `json:"id"`
-	Timestamp int    `json:"timestamp"`
+	Gen              string `json:"gen"`
+	ID               string `json:"id"`
+	Timestamp        int    `json:"timestamp"`
+	Size             int64  `json:"size"`
+	Format           string `json:"format"`
+	MinecraftVersion string `json:"minecraftVersion,omitempty"`
+	CreatedBy        string `json:"createdBy,omitempty"`
+	Pinned           bool   `json:"pinned"`
+	AutoBackup       bool   `json:"autoBackup"`
+	// Corrupt is true if the object doesn't match the checksum recorded on upload.
+	Corrupt bool `json:"corrupt"`
+	// Level is read from level.dat on upload. It is nil for generations uploaded before it was recorded.
+	Level *LevelInfo `json:"level,omitempty"`
+}
+
+type BlockPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+// LevelInfo is metadata of a world in level.dat.
+type LevelInfo struct {
+	DataVersion      int    `json:"dataVersion"`
+	MinecraftVersion string `json:"minecraftVersion"`
+	// Seed is a decimal string because it may not fit in a JavaScript number.
+	Seed       string `json:"seed"`
+	GameMode   string `json:"gameMode"`
+	Difficulty string `json:"difficulty"`
+	Hardcore   bool   `json:"hardcore"`
+	// LastPlayed is a Unix time in milliseconds.
+	LastPlayed int64    `json:"lastPlayed"`
+	Spawn      BlockPos `json:"spawn"`
+	Datapacks  []string `json:"datapacks"`
 }
 
 type World struct {
@@ -63,12 +167,50 @@ type SystemInfo struct {
 	PremisesVersion string  `json:"premisesVersion"`
 	HostOS          string  `json:"hostOs"`
 	IPAddress       *string `json:"ipAddr"`
+	// CommandLine is the command which the server was launched with, or empty if it has not started yet.
+	CommandLine []string `json:"commandLine"`
+}
+
+type PlayerList struct {
+	MaxPlayers int      `json:"maxPlayers"`
+	Players    []string `json:"players"`
+}
+
+type ServerLogLine struct {
+	Time    int64  `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type ServerLogBatch struct {
+	Lines []ServerLogLine `json:"lines"`
+}
+
+type Server struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Running  bool   `json:"running"`
+}
+
+type CreateServerReq struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
 }
 
 type WorldInfo struct {
 	Version   string `json:"version"`
 	WorldName string `json:"worldName"`
 	Seed      string `json:"seed"`
+	// Datapacks are the attached datapacks installed by the runner. They are reported after the server starts.
+	Datapacks []InstalledDatapack `json:"datapacks"`
+}
+
+type InstalledDatapack struct {
+	Name string `json:"name"`
+	// Enabled is false if the server didn't load the datapack.
+	Enabled bool `json:"enabled"`
 }
 
 func StringP(s string) *string {
@@ -86,15 +228,22 @@ func IntP(i int) *int {
 type PendingConfig struct {
 	MachineType             *string            `json:"machineType,omitempty"`
 	ServerVersion           *string            `json:"serverVersion,omitempty"`
+	ServerFlavor            *string            `json:"serverFlavor,omitempty"`
+	ModSet                  *string            `json:"modSet,omitempty"`
 	GuessVersion            *bool              `json:"guessServerVersion,omitempty"`
 	WorldSource             *string            `json:"worldSource,omitempty"`
 	WorldName               *string            `json:"worldName,omitempty"`
 	BackupGen               *string            `json:"backupGen,omitempty"`
+	BackupFormat            *string            `json:"backupFormat,omitempty"`
+	AutoBackupInterval      *int               `json:"autoBackupInterval,omitempty"`
 	LevelType               *string            `json:"levelType,omitempty"`
 	Seed                    *string            `json:"seed,omitempty"`
 	Motd                    *string            `json:"motd,omitempty"`
 	ServerPropOverride      *map[string]string `json:"serverPropOverride,omitempty"`
 	InactiveTimeout         *int               `json:"inactiveTimeout,omitempty"`
+	MaxCrashLoops           *int               `json:"maxCrashLoops,omitempty"`
+	JVMProfile              *string            `json:"jvmProfile,omitempty"`
+	JVMArgs                 *[]string          `json:"jvmArgs,omitempty"`
 	OtlpEndpoint            *string            `json:"otlpEndpoint,omitempty"`
 	MetricExportIntervalSec *int               `json:"metricExportIntervalSec,omitempty"`
 }
@@ -108,6 +257,31 @@ type CreateConfigReq struct {
 	ConfigShareId *string `json:"configShareId"`
 }
 
+// UpdateRunningConfigReq describes changes to the configuration of the running server.
+// Omitted fields are left unchanged.
+type UpdateRunningConfigReq struct {
+	Motd               *string           `json:"motd,omitempty"`
+	Difficulty         *string           `json:"difficulty,omitempty"`
+	Operators          []string          `json:"operators,omitempty"`
+	Whitelist          []string          `json:"whitelist,omitempty"`
+	ServerPropOverride map[string]string `json:"serverPropOverride,omitempty"`
+}
+
+// CrashReport is the crash report and the log tail which the runner uploaded when the server crashed last time.
+type CrashReport struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	Timestamp int    `json:"timestamp"`
+}
+
+type ConsoleReq struct {
+	Command string `json:"command"`
+}
+
+type ConsoleResult struct {
+	Response string `json:"response"`
+}
+
 type CreateWorldLinkReq struct {
 	ID string `json:"id"`
 }
@@ -117,10 +291,140 @@ type CreateWorldUploadLinkReq struct {
 	MimeType  string `json:"mimeType"`
 }
 
+type WorldUploadLink struct {
+	URL string `json:"url"`
+	// ID should be passed to the completion endpoint after the archive is uploaded to URL.
+	ID string `json:"id"`
+}
+
+type CompleteWorldUploadLinkReq struct {
+	ID string `json:"id"`
+}
+
+const (
+	WorldUploadValidating = "validating"
+	WorldUploadCompleted  = "completed"
+	WorldUploadFailed     = "failed"
+)
+
+// WorldUploadStatus is the state of validation of an uploaded world, which runs in background.
+type WorldUploadStatus struct {
+	// Status is one of WorldUploadValidating, WorldUploadCompleted and WorldUploadFailed.
+	Status string `json:"status"`
+	// Generation is the added generation if Status is WorldUploadCompleted.
+	Generation *WorldGeneration `json:"generation,omitempty"`
+	// ErrorCode is set if Status is WorldUploadFailed.
+	ErrorCode entity.ErrorCode `json:"errorCode,omitempty"`
+}
+
 type DeleteWorldReq struct {
 	ID string `json:"id"`
 }
 
+type CopyWorldReq struct {
+	WorldName    string `json:"worldName"`
+	NewWorldName string `json:"newWorldName"`
+}
+
+type ForkWorldReq struct {
+	ID           string `json:"id"`
+	NewWorldName string `json:"newWorldName"`
+}
+
+// Datapack is a datapack in the storage which can be attached to worlds.
+type Datapack struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Timestamp int    `json:"timestamp"`
+}
+
+type CreateDatapackUploadLinkReq struct {
+	Name string `json:"name"`
+}
+
+type DatapackUploadLink struct {
+	URL string `json:"url"`
+	// ID should be passed to the completion endpoint after the datapack is uploaded to URL.
+	ID string `json:"id"`
+}
+
+type CompleteDatapackUploadLinkReq struct {
+	ID string `json:"id"`
+}
+
+type DeleteDatapackReq struct {
+	Name string `json:"name"`
+}
+
+// WorldDatapacks is the set of datapacks installed into the world on launch.
+type WorldDatapacks struct {
+	WorldName string   `json:"worldName"`
+	Datapacks []string `json:"datapacks"`
+}
+
+// ModSet is a named set of mods or plugins which can be installed on launch.
+type ModSet struct {
+	Name  string       `json:"name"`
+	Files []ModSetFile `json:"files"`
+}
+
+type ModSetFile struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Sha256    string `json:"sha256"`
+	Timestamp int    `json:"timestamp"`
+}
+
+type CreateModSetUploadLinkReq struct {
+	ModSet string `json:"modSet"`
+	Name   string `json:"name"`
+}
+
+type ModSetUploadLink struct {
+	URL string `json:"url"`
+	// ID should be passed to the completion endpoint after the file is uploaded to URL.
+	ID string `json:"id"`
+}
+
+type CompleteModSetUploadLinkReq struct {
+	ID string `json:"id"`
+}
+
+type DeleteModSetFileReq struct {
+	ModSet string `json:"modSet"`
+	Name   string `json:"name"`
+}
+
+type DeleteModSetReq struct {
+	Name string `json:"name"`
+}
+
+type PinWorldReq struct {
+	ID     string `json:"id"`
+	Pinned bool   `json:"pinned"`
+}
+
+type WorldRetentionPolicy struct {
+	WorldName  string `json:"worldName"`
+	KeepLast   int    `json:"keepLast"`
+	KeepDaily  int    `json:"keepDaily"`
+	KeepWeekly int    `json:"keepWeekly"`
+	IsDefault  bool   `json:"isDefault"`
+}
+
+type UpdateWorldRetentionPolicyReq struct {
+	WorldName  string `json:"worldName"`
+	KeepLast   int    `json:"keepLast"`
+	KeepDaily  int    `json:"keepDaily"`
+	KeepWeekly int    `json:"keepWeekly"`
+}
+
+// PrunedWorld lists generations of the world which the next prune will delete.
+type PrunedWorld struct {
+	WorldName   string            `json:"worldName"`
+	Generations []WorldGeneration `json:"generations"`
+}
+
 type DelegatedURL struct {
 	URL string `json:"url"`
 }
@@ -156,3 +460,87 @@ type SysstatMessage struct {
 type SnapshotConfiguration struct {
 	Slot int `json:"slot"`
 }
+
+type AuditLogEntry struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Result    string    `json:"result"`
+}
+
+type AuditLogPage struct {
+	Entries []AuditLogEntry `json:"entries"`
+	Total   int             `json:"total"`
+	Page    int             `json:"page"`
+	PerPage int             `json:"perPage"`
+}
+
+type Webhook struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Format     string    `json:"format"`
+	EventCodes []int     `json:"eventCodes"`
+	InfoCodes  []int     `json:"infoCodes"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type CreatedWebhook struct {
+	Webhook
+	// Secret is only returned once on creation.
+	Secret string `json:"secret"`
+}
+
+type CreateWebhookReq struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Format     string `json:"format"`
+	EventCodes []int  `json:"eventCodes"`
+	InfoCodes  []int  `json:"infoCodes"`
+	Enabled    *bool  `json:"enabled,omitempty"`
+}
+
+// UpdateWebhookReq describes changes to a webhook.
+// Omitted fields are left unchanged.
+type UpdateWebhookReq struct {
+	Name       *string `json:"name,omitempty"`
+	URL        *string `json:"url,omitempty"`
+	Format     *string `json:"format,omitempty"`
+	EventCodes []int   `json:"eventCodes,omitempty"`
+	InfoCodes  []int   `json:"infoCodes,omitempty"`
+	Enabled    *bool   `json:"enabled,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID         uint            `json:"id"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	StatusCode int             `json:"statusCode"`
+	Error      string          `json:"error"`
+	Succeeded  bool            `json:"succeeded"`
+	FinishedAt *time.Time      `json:"finishedAt"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"perPage"`
+}
+
+// WebhookPayload is the body POSTed to webhooks in json format.
+type WebhookPayload struct {
+	ServerID   string           `json:"serverId"`
+	ServerName string           `json:"serverName"`
+	Hostname   string           `json:"hostname"`
+	Type       string           `json:"type"`
+	EventCode  entity.EventCode `json:"eventCode,omitempty"`
+	InfoCode   entity.InfoCode  `json:"infoCode,omitempty"`
+	IsError    bool             `json:"isError"`
+	Text       string           `json:"text"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
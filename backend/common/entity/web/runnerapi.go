@@ -10,13 +10,72 @@ type CreateWorldDownloadURLRequest struct {
 
 type CreateWorldDownloadURLResponse struct {
 	URL string `json:"url"`
+	// Checksum is the hex-encoded SHA-256 of the world, or empty if it is unknown.
+	Checksum string `json:"checksum,omitempty"`
 }
 
 type CreateWorldUploadURLRequest struct {
 	WorldName string `json:"worldName"`
+	// Format is the backup format in runner.BackupFormat*. Empty means archive.
+	Format string `json:"format,omitempty"`
 }
 
 type CreateWorldUploadURLResponse struct {
 	URL     string `json:"url"`
 	WorldID string `json:"worldId"`
 }
+
+type CompleteWorldUploadRequest struct {
+	WorldID string `json:"worldId"`
+	Size    int64  `json:"size"`
+	// AutoBackup is true if the world was uploaded while the server was running.
+	AutoBackup bool `json:"autoBackup"`
+	// Checksum is the hex-encoded SHA-256 of the uploaded object.
+	Checksum string `json:"checksum"`
+	// Level is nil if level.dat couldn't be read.
+	Level *LevelInfo `json:"level,omitempty"`
+}
+
+type CreateBlobURLsRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// CreateBlobURLsResponse maps hashes of blobs to presigned URLs.
+// For uploads, blobs which already exist are omitted.
+type CreateBlobURLsResponse struct {
+	URLs map[string]string `json:"urls"`
+}
+
+type GetWorldDatapacksRequest struct {
+	WorldName string `json:"worldName"`
+}
+
+type DatapackDownload struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// GetWorldDatapacksResponse lists datapacks attached to the world with presigned URLs to download them.
+type GetWorldDatapacksResponse struct {
+	Datapacks []DatapackDownload `json:"datapacks"`
+}
+
+type GetModSetRequest struct {
+	Name string `json:"name"`
+}
+
+type ModSetFileDownload struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Sha256 string `json:"sha256"`
+}
+
+// GetModSetResponse lists files in the mod set with presigned URLs to download them.
+type GetModSetResponse struct {
+	Files []ModSetFileDownload `json:"files"`
+}
+
+type CreateCrashReportUploadURLResponse struct {
+	URL      string `json:"url"`
+	ReportID string `json:"reportId"`
+}
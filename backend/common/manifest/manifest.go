@@ -0,0 +1,109 @@
+// Package manifest defines the format of incremental world backups.
+//
+// An incremental backup consists of a manifest object, which lists files of the world,
+// and blob objects, which hold zstd-compressed contents of the files.
+// Blobs are addressed by SHA-256 of the uncompressed contents so that generations share unchanged files.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	Version = 1
+
+	// Extension is the suffix of keys of manifest objects.
+	Extension = ".manifest.json"
+
+	// BlobPrefix is the prefix of keys of blob objects.
+	// World names can't start with "@", so this never conflicts with worlds.
+	BlobPrefix = "@blobs/"
+
+	// maxManifestSize limits the size of a manifest to read.
+	maxManifestSize = 64 << 20
+)
+
+type File struct {
+	Path string `json:"path"`
+	Dir  bool   `json:"dir,omitempty"`
+	Size int64  `json:"size,omitempty"`
+	Hash string `json:"hash,omitempty"`
+}
+
+type Manifest struct {
+	Version int    `json:"version"`
+	Files   []File `json:"files"`
+}
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func IsValidHash(hash string) bool {
+	return hashPattern.MatchString(hash)
+}
+
+// BlobKey returns the key of the blob object which has the hash.
+func BlobKey(hash string) string {
+	return BlobPrefix + hash[:2] + "/" + hash
+}
+
+// HashFromBlobKey returns the hash of the blob object, or false if the key isn't a blob.
+func HashFromBlobKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, BlobPrefix) {
+		return "", false
+	}
+	hash := path.Base(key)
+	if !IsValidHash(hash) || BlobKey(hash) != key {
+		return "", false
+	}
+	return hash, true
+}
+
+func isValidPath(p string) bool {
+	return p != "" && !path.IsAbs(p) && path.Clean(p) == p && p != ".." && !strings.HasPrefix(p, "../")
+}
+
+func (m *Manifest) Validate() error {
+	if m.Version != Version {
+		return fmt.Errorf("unsupported manifest version: %d", m.Version)
+	}
+	for _, f := range m.Files {
+		if !isValidPath(f.Path) {
+			return fmt.Errorf("invalid path in manifest: %q", f.Path)
+		}
+		if !f.Dir && !IsValidHash(f.Hash) {
+			return fmt.Errorf("invalid hash in manifest: %q", f.Hash)
+		}
+	}
+	return nil
+}
+
+// Blobs returns hashes of blobs which the manifest refers to without duplicates.
+func (m *Manifest) Blobs() []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, f := range m.Files {
+		if f.Dir || seen[f.Hash] {
+			continue
+		}
+		seen[f.Hash] = true
+		result = append(result, f.Hash)
+	}
+	return result
+}
+
+// Read reads and validates a manifest.
+func Read(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(io.LimitReader(r, maxManifestSize)).Decode(&m); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,66 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	hash1 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	hash2 = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+)
+
+var _ = Describe("Manifest", func() {
+	It("should build blob key", func() {
+		Expect(BlobKey(hash1)).To(Equal("@blobs/2c/" + hash1))
+	})
+
+	DescribeTable("HashFromBlobKey", func(key, hash string, ok bool) {
+		actualHash, actualOk := HashFromBlobKey(key)
+		Expect(actualOk).To(Equal(ok))
+		Expect(actualHash).To(Equal(hash))
+	},
+		Entry("blob", "@blobs/2c/"+hash1, hash1, true),
+		Entry("wrong shard", "@blobs/fc/"+hash1, "", false),
+		Entry("world", "foo/bar.tar.zst", "", false),
+		Entry("not a hash", "@blobs/2c/2c26b4", "", false),
+	)
+
+	DescribeTable("Read", func(content string, valid bool) {
+		_, err := Read(strings.NewReader(content))
+		if valid {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("valid", `{"version":1,"files":[{"path":"world","dir":true},{"path":"world/level.dat","size":3,"hash":"`+hash1+`"}]}`, true),
+		Entry("unsupported version", `{"version":2,"files":[]}`, false),
+		Entry("path traversal", `{"version":1,"files":[{"path":"../level.dat","hash":"`+hash1+`"}]}`, false),
+		Entry("absolute path", `{"version":1,"files":[{"path":"/etc/passwd","hash":"`+hash1+`"}]}`, false),
+		Entry("unclean path", `{"version":1,"files":[{"path":"world/../../level.dat","hash":"`+hash1+`"}]}`, false),
+		Entry("invalid hash", `{"version":1,"files":[{"path":"world/level.dat","hash":"foo"}]}`, false),
+		Entry("broken json", `{"version":1`, false),
+	)
+
+	It("should list blobs without duplicates", func() {
+		m := Manifest{
+			Version: Version,
+			Files: []File{
+				{Path: "world", Dir: true},
+				{Path: "world/a", Hash: hash1},
+				{Path: "world/b", Hash: hash2},
+				{Path: "world/c", Hash: hash1},
+			},
+		}
+		Expect(m.Blobs()).To(Equal([]string{hash1, hash2}))
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Manifest Suite")
+}
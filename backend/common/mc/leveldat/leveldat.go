@@ -0,0 +1,122 @@
+package leveldat
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/mc/nbt"
+)
+
+const (
+	maxSize  = 4 * 1024 * 1024
+	maxDepth = 20
+)
+
+var ErrNotWorld = errors.New("level.dat doesn't describe a world")
+
+var (
+	gameModes    = []string{"survival", "creative", "adventure", "spectator"}
+	difficulties = []string{"peaceful", "easy", "normal", "hard"}
+)
+
+type LevelDat struct {
+	Data struct {
+		DataVersion int32
+		LevelName   string
+		Version     struct {
+			Name string
+		}
+		WorldGenSettings struct {
+			Seed int64 `nbt:"seed"`
+		}
+		// Seed of worlds created before 1.16.
+		RandomSeed int64
+		GameType   int32
+		Difficulty int8
+		Hardcore   int8 `nbt:"hardcore"`
+		LastPlayed int64
+		// Spawn point of worlds created before 1.21.9.
+		SpawnX, SpawnY, SpawnZ int32
+		Spawn                  struct {
+			Pos []int32 `nbt:"pos"`
+		} `nbt:"spawn"`
+		DataPacks struct {
+			Enabled []string
+		}
+	}
+}
+
+// Read reads gzipped level.dat from r.
+func Read(r io.Reader) (*LevelDat, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	decoder := nbt.NewDecoderWithDepthLimit(io.LimitReader(gzipReader, maxSize), maxDepth)
+	var levelDat LevelDat
+	if err := decoder.Decode(&levelDat); err != nil {
+		return nil, err
+	}
+	if levelDat.Data.LevelName == "" {
+		return nil, ErrNotWorld
+	}
+	return &levelDat, nil
+}
+
+func ReadFile(path string) (*LevelDat, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+func (l *LevelDat) Seed() int64 {
+	if l.Data.WorldGenSettings.Seed != 0 {
+		return l.Data.WorldGenSettings.Seed
+	}
+	return l.Data.RandomSeed
+}
+
+func nameOf(names []string, index int) string {
+	if 0 <= index && index < len(names) {
+		return names[index]
+	}
+	return strconv.Itoa(index)
+}
+
+func (l *LevelDat) Info() *web.LevelInfo {
+	spawn := web.BlockPos{
+		X: int(l.Data.SpawnX),
+		Y: int(l.Data.SpawnY),
+		Z: int(l.Data.SpawnZ),
+	}
+	if pos := l.Data.Spawn.Pos; len(pos) == 3 {
+		spawn = web.BlockPos{X: int(pos[0]), Y: int(pos[1]), Z: int(pos[2])}
+	}
+
+	datapacks := l.Data.DataPacks.Enabled
+	if datapacks == nil {
+		datapacks = []string{}
+	}
+
+	return &web.LevelInfo{
+		DataVersion:      int(l.Data.DataVersion),
+		MinecraftVersion: l.Data.Version.Name,
+		Seed:             strconv.FormatInt(l.Seed(), 10),
+		GameMode:         nameOf(gameModes, int(l.Data.GameType)),
+		Difficulty:       nameOf(difficulties, int(l.Data.Difficulty)),
+		Hardcore:         l.Data.Hardcore != 0,
+		LastPlayed:       l.Data.LastPlayed,
+		Spawn:            spawn,
+		Datapacks:        datapacks,
+	}
+}
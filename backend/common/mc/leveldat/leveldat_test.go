@@ -0,0 +1,52 @@
+package leveldat_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/mc/leveldat"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LevelDat", func() {
+	It("should read metadata of the world", func() {
+		levelDat, err := leveldat.ReadFile("testdata/level.dat")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(levelDat.Info()).To(Equal(&web.LevelInfo{
+			DataVersion:      3700,
+			MinecraftVersion: "1.20.4",
+			Seed:             "3416194518646519871",
+			GameMode:         "survival",
+			Difficulty:       "normal",
+			Hardcore:         false,
+			LastPlayed:       1708256997133,
+			Spawn:            web.BlockPos{X: 0, Y: 111, Z: 0},
+			Datapacks:        []string{"vanilla"},
+		}))
+	})
+
+	It("should reject level.dat which is not a world", func() {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		// An empty compound
+		gw.Write([]byte{0x0a, 0x00, 0x00, 0x00})
+		gw.Close()
+
+		_, err := leveldat.Read(&buf)
+		Expect(err).To(MatchError(leveldat.ErrNotWorld))
+	})
+
+	It("should reject files which are not gzipped", func() {
+		_, err := leveldat.Read(bytes.NewReader([]byte("level.dat")))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LevelDat Suite")
+}
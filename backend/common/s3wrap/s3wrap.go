@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,6 +19,8 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
 )
 
+var ErrNotFound = errors.New("object not found")
+
 type Client struct {
 	s3 *s3.Client
 }
@@ -61,6 +65,7 @@ func New(ctx context.Context, forcePathStyle bool) (*Client, error) {
 type ObjectMetaData struct {
 	Key       string
 	Timestamp time.Time
+	Size      int64
 }
 
 type ListObjectsOption func(*s3.ListObjectsV2Input)
@@ -102,6 +107,7 @@ func (client *Client) ListObjects(ctx context.Context, bucket string, opts ...Li
 			result = append(result, ObjectMetaData{
 				Key:       *obj.Key,
 				Timestamp: *obj.LastModified,
+				Size:      aws.ToInt64(obj.Size),
 			})
 		}
 	}
@@ -109,7 +115,19 @@ func (client *Client) ListObjects(ctx context.Context, bucket string, opts ...Li
 	return result, nil
 }
 
+// maxDeleteObjects is the maximum number of keys which a DeleteObjects request can have.
+const maxDeleteObjects = 1000
+
 func (client *Client) DeleteObjects(ctx context.Context, bucket string, keys []string) error {
+	for batch := range slices.Chunk(keys, maxDeleteObjects) {
+		if err := client.deleteObjectBatch(ctx, bucket, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (client *Client) deleteObjectBatch(ctx context.Context, bucket string, keys []string) error {
 	var objectIds []types.ObjectIdentifier
 	for _, key := range keys {
 		objectIds = append(objectIds, types.ObjectIdentifier{
@@ -164,6 +182,10 @@ func (client *Client) GetObject(ctx context.Context, bucket, key string) (*Objec
 	}
 	resp, err := client.s3.GetObject(ctx, params, otelInstrument)
 	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 
@@ -186,6 +208,25 @@ func (client *Client) PutObject(ctx context.Context, bucket, key string, body io
 	return nil
 }
 
+func (client *Client) CopyObject(ctx context.Context, bucket, src, dst string) error {
+	// CopySource must be URL-encoded.
+	segments := strings.Split(bucket+"/"+src, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	source := strings.Join(segments, "/")
+	params := &s3.CopyObjectInput{
+		Bucket:     &bucket,
+		Key:        &dst,
+		CopySource: &source,
+	}
+	if _, err := client.s3.CopyObject(ctx, params, otelInstrument); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (client *Client) GetPresignedGetURL(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
 	presignClient := s3.NewPresignClient(client.s3)
 	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+type Action string
+
+const (
+	ActionLaunch             Action = "launch"
+	ActionStop               Action = "stop"
+	ActionCancelStop         Action = "stop:cancel"
+	ActionReconfigure        Action = "reconfigure"
+	ActionConsole            Action = "console"
+	ActionSnapshot           Action = "snapshot"
+	ActionUndo               Action = "undo"
+	ActionDeleteWorld        Action = "world:delete"
+	ActionPinWorld           Action = "world:pin"
+	ActionRenameWorld        Action = "world:rename"
+	ActionDuplicateWorld     Action = "world:duplicate"
+	ActionForkWorld          Action = "world:fork"
+	ActionUpdateRetention    Action = "world:retention"
+	ActionUpdateDatapacks    Action = "world:datapacks"
+	ActionCreateDownloadLink Action = "world-link:download"
+	ActionCreateUploadLink   Action = "world-link:upload"
+	ActionCompleteUpload     Action = "world-link:upload-complete"
+	ActionUploadDatapack     Action = "datapack:upload"
+	ActionDeleteDatapack     Action = "datapack:delete"
+	ActionUploadModSetFile   Action = "modset:upload"
+	ActionDeleteModSetFile   Action = "modset:delete-file"
+	ActionDeleteModSet       Action = "modset:delete"
+	ActionAddUser            Action = "user:add"
+	ActionCreateServer       Action = "server:create"
+	ActionDeleteServer       Action = "server:delete"
+	ActionCreateSchedule     Action = "schedule:create"
+	ActionUpdateSchedule     Action = "schedule:update"
+	ActionDeleteSchedule     Action = "schedule:delete"
+	ActionCreateWebhook      Action = "webhook:create"
+	ActionUpdateWebhook      Action = "webhook:update"
+	ActionDeleteWebhook      Action = "webhook:delete"
+)
+
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+)
+
+func ResultOf(err error) Result {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+func WorldTarget(id string) string {
+	return "world:" + id
+}
+
+func DatapackTarget(name string) string {
+	return "datapack:" + name
+}
+
+func ModSetTarget(name string) string {
+	return "modset:" + name
+}
+
+func ModSetFileTarget(modSet, name string) string {
+	return "modset:" + modSet + "/" + name
+}
+
+func SlotTarget(slot int) string {
+	return fmt.Sprintf("slot:%d", slot)
+}
+
+func ServerTarget(id string) string {
+	return "server:" + id
+}
+
+func ScheduleTarget(id uint) string {
+	return fmt.Sprintf("schedule:%d", id)
+}
+
+func WebhookTarget(id uint) string {
+	return fmt.Sprintf("webhook:%d", id)
+}
+
+func UserTarget(name string) string {
+	return "user:" + name
+}
+
+type AuditService struct {
+	db *bun.DB
+}
+
+func New(db *bun.DB) *AuditService {
+	return &AuditService{
+		db: db,
+	}
+}
+
+// Record appends an entry to the audit log.
+// Failing to record must not make the audited operation fail, so errors are only logged.
+func (a *AuditService) Record(ctx context.Context, actor uint, action Action, target string, result Result) {
+	entry := &model.AuditLog{
+		Action: string(action),
+		Target: target,
+		Result: string(result),
+	}
+	if actor != 0 {
+		entry.ActorUserID = &actor
+	}
+
+	if _, err := a.db.NewInsert().Model(entry).Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to record audit log", slog.Any("error", err), slog.String("action", string(action)))
+	}
+}
+
+// List returns audit log entries in reverse chronological order along with the total number of entries.
+func (a *AuditService) List(ctx context.Context, limit, offset int) ([]model.AuditLog, int, error) {
+	entries := make([]model.AuditLog, 0)
+	total, err := a.db.NewSelect().Model(&entries).Relation("Actor", func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Column("name")
+	}).Order("audit_log.id DESC").Limit(limit).Offset(offset).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/base32"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gorilla/securecookie"
 	"github.com/kofuk/premises/backend/ctrlplane/common/kvs"
+	"github.com/uptrace/bun"
 )
 
 var ErrNoAuthorization = errors.New("not a bearer header")
@@ -19,12 +22,64 @@ const headerAuthorization = "Authorization"
 type Scope string
 
 const (
-	NoScope    Scope = ""
-	ScopeAdmin Scope = "admin"
+	NoScope            Scope = ""
+	ScopeAdmin         Scope = "admin"
+	ScopeServerRead    Scope = "server:read"
+	ScopeServerControl Scope = "server:control"
+	ScopeServerConsole Scope = "server:console"
+	ScopeWorldRead     Scope = "world:read"
+	ScopeWorldWrite    Scope = "world:write"
+	ScopeUsersAdmin    Scope = "users:admin"
 )
 
+// AllScopes lists every scope that can be granted to a user.
+var AllScopes = []Scope{
+	ScopeAdmin,
+	ScopeServerRead,
+	ScopeServerControl,
+	ScopeServerConsole,
+	ScopeWorldRead,
+	ScopeWorldWrite,
+	ScopeUsersAdmin,
+}
+
+// DefaultUserScopes are granted to users who are added without explicit scopes.
+// They allow to operate the server but not to modify worlds or users.
+var DefaultUserScopes = []Scope{
+	ScopeServerRead,
+	ScopeServerControl,
+	ScopeWorldRead,
+}
+
+func IsValidScope(scope Scope) bool {
+	return slices.Contains(AllScopes, scope)
+}
+
+func ParseScopes(scopes []string) ([]Scope, error) {
+	result := make([]Scope, 0, len(scopes))
+	for _, s := range scopes {
+		scope := Scope(s)
+		if !IsValidScope(scope) {
+			return nil, fmt.Errorf("unknown scope: %s", s)
+		}
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+func ScopesToStrings(scopes []Scope) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		result = append(result, string(scope))
+	}
+	return result
+}
+
 type AuthService struct {
 	kvs kvs.KeyValueStore
+	db  *bun.DB
 }
 
 type Token struct {
@@ -32,7 +87,19 @@ type Token struct {
 	UserID    uint      `json:"userID"`
 	Scopes    []Scope   `json:"scopes"`
 	CreatedAt time.Time `json:"createdAt"`
-	scopeMap  map[Scope]struct{}
+	// ID of the personal access token if the token is a personal access token, 0 otherwise.
+	PersonalAccessTokenID uint `json:"personalAccessTokenID,omitempty"`
+	scopeMap              map[Scope]struct{}
+}
+
+func (t *Token) buildScopeMap() {
+	t.scopeMap = make(map[Scope]struct{})
+	for _, scope := range t.Scopes {
+		if scope == NoScope {
+			continue
+		}
+		t.scopeMap[scope] = struct{}{}
+	}
 }
 
 func (t *Token) HasScope(scope Scope) bool {
@@ -40,13 +107,18 @@ func (t *Token) HasScope(scope Scope) bool {
 		return true
 	}
 
-	_, isAdminScope := t.scopeMap[ScopeAdmin]
-	return isAdminScope
+	if _, isAdminScope := t.scopeMap[ScopeAdmin]; isAdminScope {
+		return true
+	}
+
+	_, ok := t.scopeMap[scope]
+	return ok
 }
 
-func New(kvs kvs.KeyValueStore) *AuthService {
+func New(kvs kvs.KeyValueStore, db *bun.DB) *AuthService {
 	return &AuthService{
 		kvs: kvs,
+		db:  db,
 	}
 }
 
@@ -57,6 +129,7 @@ func (a *AuthService) CreateToken(ctx context.Context, userID uint, scopes []Sco
 		Scopes:    scopes,
 		CreatedAt: time.Now(),
 	}
+	token.buildScopeMap()
 
 	if err := a.kvs.Set(ctx, "token:"+token.Token, token, 30*24*time.Hour); err != nil {
 		return nil, err
@@ -66,18 +139,16 @@ func (a *AuthService) CreateToken(ctx context.Context, userID uint, scopes []Sco
 }
 
 func (a *AuthService) Get(ctx context.Context, token string) (*Token, error) {
+	if strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return a.getPersonalAccessToken(ctx, token)
+	}
+
 	var t Token
 	if err := a.kvs.Get(ctx, "token:"+token, &t); err != nil {
 		return nil, err
 	}
 
-	t.scopeMap = make(map[Scope]struct{})
-	for _, scope := range t.Scopes {
-		if scope == NoScope {
-			continue
-		}
-		t.scopeMap[scope] = struct{}{}
-	}
+	t.buildScopeMap()
 
 	return &t, nil
 }
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auth", func() {
+	DescribeTable("HasScope", func(granted []Scope, required Scope, expected bool) {
+		token := &Token{Scopes: granted}
+		token.buildScopeMap()
+		Expect(token.HasScope(required)).To(Equal(expected))
+	},
+		Entry("no scope is required", []Scope{}, NoScope, true),
+		Entry("admin has every scope", []Scope{ScopeAdmin}, ScopeWorldWrite, true),
+		Entry("granted scope", []Scope{ScopeServerRead, ScopeServerControl}, ScopeServerControl, true),
+		Entry("not granted scope", []Scope{ScopeServerRead, ScopeServerControl}, ScopeWorldWrite, false),
+		Entry("no scope granted", nil, ScopeServerRead, false),
+	)
+
+	It("should parse known scopes", func() {
+		scopes, err := ParseScopes([]string{"server:read", "world:write", "server:read"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scopes).To(Equal([]Scope{ScopeServerRead, ScopeWorldWrite}))
+	})
+
+	It("should reject unknown scopes", func() {
+		_, err := ParseScopes([]string{"server:read", "everything"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("clampScopes", func(scopes, ownerScopes, expected []Scope) {
+		Expect(clampScopes(scopes, ownerScopes)).To(Equal(expected))
+	},
+		Entry("owner has every scope", []Scope{ScopeServerRead, ScopeWorldWrite}, []Scope{ScopeServerRead, ScopeWorldWrite}, []Scope{ScopeServerRead, ScopeWorldWrite}),
+		Entry("owner lost a scope", []Scope{ScopeServerRead, ScopeWorldWrite}, []Scope{ScopeServerRead}, []Scope{ScopeServerRead}),
+		Entry("owner is admin", []Scope{ScopeWorldWrite}, []Scope{ScopeAdmin}, []Scope{ScopeWorldWrite}),
+		Entry("admin token of non-admin owner", []Scope{ScopeAdmin}, []Scope{ScopeServerRead}, nil),
+		Entry("owner lost every scope", []Scope{ScopeServerRead}, nil, nil),
+	)
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Auth Suite")
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+const PersonalAccessTokenPrefix = "pmpat_"
+
+var (
+	ErrTokenExpired  = errors.New("token expired")
+	ErrTokenNotFound = errors.New("token not found")
+)
+
+// Updating last-used timestamp on every request is wasteful, so we only update it with this granularity.
+const lastUsedUpdateInterval = time.Minute
+
+func hashPersonalAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func (a *AuthService) CreatePersonalAccessToken(ctx context.Context, userID uint, name string, scopes []Scope, expiresAt *time.Time) (*model.PersonalAccessToken, string, error) {
+	secret := PersonalAccessTokenPrefix + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(securecookie.GenerateRandomKey(32))
+
+	pat := &model.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashPersonalAccessToken(secret),
+		Scopes:    ScopesToStrings(scopes),
+	}
+	if expiresAt != nil {
+		pat.ExpiresAt = bun.NullTime{Time: *expiresAt}
+	}
+
+	if _, err := a.db.NewInsert().Model(pat).Returning("*").Exec(ctx); err != nil {
+		return nil, "", err
+	}
+
+	return pat, secret, nil
+}
+
+func (a *AuthService) ListPersonalAccessTokens(ctx context.Context, userID uint) ([]model.PersonalAccessToken, error) {
+	tokens := make([]model.PersonalAccessToken, 0)
+	if err := a.db.NewSelect().Model(&tokens).Where("user_id = ?", userID).Order("id ASC").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (a *AuthService) RevokePersonalAccessToken(ctx context.Context, userID uint, id uint) error {
+	result, err := a.db.NewDelete().Model((*model.PersonalAccessToken)(nil)).Where("id = ? AND user_id = ?", id, userID).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+// clampScopes drops scopes which the owner doesn't have.
+// The token can't be more powerful than its owner, even if the owner lost some scopes after the token was created.
+func clampScopes(scopes []Scope, ownerScopes []Scope) []Scope {
+	owner := &Token{Scopes: ownerScopes}
+	owner.buildScopeMap()
+
+	var result []Scope
+	for _, scope := range scopes {
+		if owner.HasScope(scope) {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+func (a *AuthService) getPersonalAccessToken(ctx context.Context, secret string) (*Token, error) {
+	var pat model.PersonalAccessToken
+	if err := a.db.NewSelect().Model(&pat).Where("token_hash = ?", hashPersonalAccessToken(secret)).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if !pat.ExpiresAt.IsZero() && pat.ExpiresAt.Before(now) {
+		return nil, ErrTokenExpired
+	}
+
+	var user model.User
+	if err := a.db.NewSelect().Model(&user).Column("id", "scopes").Where("id = ? AND deleted_at IS NULL", pat.UserID).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	userScopes, err := ParseScopes(user.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	patScopes, err := ParseScopes(pat.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := clampScopes(patScopes, userScopes)
+
+	if pat.LastUsedAt.IsZero() || pat.LastUsedAt.Add(lastUsedUpdateInterval).Before(now) {
+		if _, err := a.db.NewUpdate().Model((*model.PersonalAccessToken)(nil)).Set("last_used_at = ?", now).Where("id = ?", pat.ID).Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	token := &Token{
+		Token:                 secret,
+		UserID:                pat.UserID,
+		Scopes:                scopes,
+		CreatedAt:             pat.CreatedAt,
+		PersonalAccessTokenID: pat.ID,
+	}
+	token.buildScopeMap()
+
+	return token, nil
+}
@@ -1,6 +1,10 @@
 package config
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 type Config struct {
 	DevMode               bool     `envconfig:"PREMISES_DEV_MODE"`
@@ -17,6 +21,9 @@ type Config struct {
 	ConohaNameTag         string   `envconfig:"PREMISES_CONOHA_NAME_TAG"`
 	S3Bucket              string   `envconfig:"PREMISES_S3_BUCKET"`
 	S3ForcePathStyle      bool     `envconfig:"PREMISES_S3_FORCE_PATH_STYLE"`
+	StorageBackend        string   `envconfig:"PREMISES_STORAGE_BACKEND" default:"s3"` // s3, local
+	StorageLocalPath      string   `envconfig:"PREMISES_STORAGE_LOCAL_PATH"`
+	UploadTempDir         string   `envconfig:"PREMISES_UPLOAD_TEMP_DIR"` // os.TempDir() if empty
 	Operators             []string `envconfig:"PREMISES_GAME_OPERATORS"`
 	Whitelist             []string `envconfig:"PREMISES_GAME_WHITELIST"`
 	Secret                string   `envconfig:"PREMISES_SECRET"`
@@ -35,6 +42,8 @@ type Config struct {
 	ProxyBackendAddr      string   `envconfig:"PREMISES_PROXY_BACKEND_ADDRESS"`
 	GameDomain            string   `envconfig:"PREMISES_GAME_DOMAIN"`
 	IconURL               string   `envconfig:"PREMISES_ICON_URL"`
+	ConsoleAllowCommands  []string `envconfig:"PREMISES_CONSOLE_ALLOW_COMMANDS"` // all commands are allowed if empty
+	ConsoleDenyCommands   []string `envconfig:"PREMISES_CONSOLE_DENY_COMMANDS" default:"stop,save-off"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -42,5 +51,20 @@ func LoadConfig() (*Config, error) {
 	if err := envconfig.Process("", &result); err != nil {
 		return nil, err
 	}
+	result.ConsoleAllowCommands = normalizeCommandNames(result.ConsoleAllowCommands)
+	result.ConsoleDenyCommands = normalizeCommandNames(result.ConsoleDenyCommands)
 	return &result, nil
 }
+
+// normalizeCommandNames converts command names to lower case without leading slash,
+// so that they can be compared with ones in console commands.
+func normalizeCommandNames(names []string) []string {
+	var result []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "/"))
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -5,21 +5,37 @@ import (
 	"errors"
 	"log/slog"
 	"math/rand"
+	"slices"
 	"time"
 
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
 	"github.com/kofuk/premises/backend/ctrlplane/common/config"
 	"github.com/kofuk/premises/backend/ctrlplane/common/conoha"
+	"github.com/kofuk/premises/backend/ctrlplane/common/launcher"
+	"github.com/kofuk/premises/backend/ctrlplane/common/longpoll"
+	"github.com/kofuk/premises/backend/ctrlplane/common/mcversions"
+	"github.com/kofuk/premises/backend/ctrlplane/common/schedule"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
+	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
 	"github.com/kofuk/premises/backend/ctrlplane/common/world"
 	"github.com/robfig/cron/v3"
 )
 
 type CronService struct {
-	conoha       *conoha.Client
-	nameTag      string
-	worldService *world.WorldService
+	config        *config.Config
+	conoha        *conoha.Client
+	nameTag       string
+	worldService  *world.WorldService
+	serverService *servers.ServerService
+	schedules     *schedule.ScheduleService
+	launcher      *launcher.LauncherService
+	versions      *mcversions.MCVersionsService
+	action        *longpoll.LongPollService
+	streaming     *streaming.StreamingService
+	audit         *audit.AuditService
 }
 
-func NewCronService(config *config.Config, worldService *world.WorldService) *CronService {
+func NewCronService(config *config.Config, worldService *world.WorldService, serverService *servers.ServerService, schedules *schedule.ScheduleService, launcher *launcher.LauncherService, versions *mcversions.MCVersionsService, action *longpoll.LongPollService, streaming *streaming.StreamingService, audit *audit.AuditService) *CronService {
 	identity := conoha.Identity{
 		User:     config.ConohaUser,
 		Password: config.ConohaPassword,
@@ -34,9 +50,17 @@ func NewCronService(config *config.Config, worldService *world.WorldService) *Cr
 	conoha := conoha.NewClient(identity, endpoints, nil)
 
 	return &CronService{
-		conoha:       conoha,
-		nameTag:      config.ConohaNameTag,
-		worldService: worldService,
+		config:        config,
+		conoha:        conoha,
+		nameTag:       config.ConohaNameTag,
+		worldService:  worldService,
+		serverService: serverService,
+		schedules:     schedules,
+		launcher:      launcher,
+		versions:      versions,
+		action:        action,
+		streaming:     streaming,
+		audit:         audit,
 	}
 }
 
@@ -87,16 +111,30 @@ func (cr *CronService) runSaveStorageJob(ctx context.Context) error {
 	return nil
 }
 
+// runCreateStorageJob creates a volume for each server which doesn't have one.
+// All servers share the image saved from the default server's volume.
 func (cr *CronService) runCreateStorageJob(ctx context.Context) error {
+	serverList, err := cr.serverService.List(ctx)
+	if err != nil {
+		return err
+	}
+
 	volumes, err := cr.conoha.ListVolumes(ctx)
 	if err != nil {
 		return err
 	}
-	for _, v := range volumes.Volumes {
-		if v.Name == cr.nameTag {
-			slog.InfoContext(ctx, "Volume already exists. Skip creating a new volume.")
-			return nil
+
+	var missingVolumes []string
+	for _, server := range serverList {
+		name := servers.ResourceNameTag(cr.nameTag, server.ID)
+		if slices.ContainsFunc(volumes.Volumes, func(v conoha.Volume) bool { return v.Name == name }) {
+			slog.InfoContext(ctx, "Volume already exists. Skip creating a new volume.", slog.String("server_id", server.ID))
+			continue
 		}
+		missingVolumes = append(missingVolumes, name)
+	}
+	if len(missingVolumes) == 0 {
+		return nil
 	}
 
 	images, err := cr.conoha.ListImages(ctx)
@@ -118,19 +156,37 @@ func (cr *CronService) runCreateStorageJob(ctx context.Context) error {
 		return errors.New("image is not active")
 	}
 
-	_, err = cr.conoha.CreateBootVolume(ctx, conoha.CreateBootVolumeInput{
-		ImageID: image.ID,
-		Name:    cr.nameTag,
-	})
-	if err != nil {
-		return err
+	for _, name := range missingVolumes {
+		_, err = cr.conoha.CreateBootVolume(ctx, conoha.CreateBootVolumeInput{
+			ImageID: image.ID,
+			Name:    name,
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func (cr *CronService) runPruneWorldsJob(ctx context.Context) error {
-	return cr.worldService.Prune(ctx, 3)
+	// Make sure that retention policies are applied to every generation in the bucket.
+	if err := cr.worldService.Reconcile(ctx); err != nil {
+		return err
+	}
+	return cr.worldService.Prune(ctx)
+}
+
+func (cr *CronService) runReconcileWorldsJob(ctx context.Context) error {
+	return cr.worldService.Reconcile(ctx)
+}
+
+func (cr *CronService) runVerifyWorldsJob(ctx context.Context) error {
+	corrupt, err := cr.worldService.VerifyGenerations(ctx)
+	for _, key := range corrupt {
+		slog.WarnContext(ctx, "Corrupt world generation found", slog.String("key", key))
+	}
+	return err
 }
 
 func withDelay(ctx context.Context, fn func(context.Context) error) func() {
@@ -157,8 +213,36 @@ func (cr *CronService) Run(ctx context.Context) error {
 	// Prune old worlds
 	c.AddFunc("0 19 * * *", withDelay(ctx, cr.runPruneWorldsJob))
 
+	// Every 6 hours.
+	// Synchronize world catalog with the bucket
+	c.AddFunc("15 */6 * * *", withDelay(ctx, cr.runReconcileWorldsJob))
+
+	// Every 6 hours.
+	// Verify checksums of stored worlds
+	c.AddFunc("45 */6 * * *", withDelay(ctx, cr.runVerifyWorldsJob))
+
+	// Every minute.
+	// Launch or stop servers according to user-defined schedules.
+	lastScheduleCheck := time.Now()
+	c.AddJob("* * * * *", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(func() {
+		now := time.Now()
+		if err := cr.runSchedules(ctx, lastScheduleCheck, now); err != nil {
+			// Check the same period again next time so that schedules are not missed.
+			slog.ErrorContext(ctx, "cron job failed", slog.Any("error", err))
+			return
+		}
+		lastScheduleCheck = now
+	})))
+
 	c.Start()
 
+	// The catalog may be out of date while cron is not running.
+	go func() {
+		if err := cr.runReconcileWorldsJob(ctx); err != nil {
+			slog.ErrorContext(ctx, "cron job failed", slog.Any("error", err))
+		}
+	}()
+
 	<-ctx.Done()
 	<-c.Stop().Done()
 	return nil
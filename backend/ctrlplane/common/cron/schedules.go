@@ -0,0 +1,93 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/gameconfig"
+	"github.com/kofuk/premises/backend/ctrlplane/common/launcher"
+	"github.com/kofuk/premises/backend/ctrlplane/common/schedule"
+	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+)
+
+func (cr *CronService) launchScheduled(ctx context.Context, s *model.Schedule) {
+	var config web.PendingConfig
+	if err := json.Unmarshal(s.Config, &config); err != nil {
+		slog.ErrorContext(ctx, "Invalid config in schedule", slog.Any("error", err), slog.Any("schedule_id", s.ID))
+		cr.streaming.PublishEvent(ctx, s.ServerID, streaming.NewInfoMessage(entity.InfoErrScheduledLaunch, true))
+		return
+	}
+
+	target := audit.ServerTarget(s.ServerID)
+	if config.WorldName != nil {
+		target = audit.WorldTarget(*config.WorldName)
+	}
+
+	launchConfig, err := gameconfig.FromPendingConfig(ctx, cr.versions, config, cr.config)
+	if err == nil {
+		err = cr.launcher.Launch(ctx, s.ServerID, launchConfig)
+	}
+	if errors.Is(err, launcher.ErrAlreadyRunning) {
+		slog.InfoContext(ctx, "Skipping scheduled launch because the server is running", slog.Any("schedule_id", s.ID))
+		cr.streaming.PublishEvent(ctx, s.ServerID, streaming.NewInfoMessage(entity.InfoScheduledLaunchSkipped, false))
+		return
+	}
+	cr.audit.Record(ctx, 0, audit.ActionLaunch, target, audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to launch server by schedule", slog.Any("error", err), slog.Any("schedule_id", s.ID))
+		cr.streaming.PublishEvent(ctx, s.ServerID, streaming.NewInfoMessage(entity.InfoErrScheduledLaunch, true))
+		return
+	}
+
+	cr.streaming.PublishEvent(ctx, s.ServerID, streaming.NewInfoMessage(entity.InfoScheduledLaunch, false))
+}
+
+func (cr *CronService) stopScheduled(ctx context.Context, s *model.Schedule) {
+	if !cr.launcher.IsRunning(ctx, s.ServerID) {
+		return
+	}
+
+	err := cr.action.Push(ctx, s.ServerID, runner.Action{
+		Type: runner.ActionStop,
+	})
+	cr.audit.Record(ctx, 0, audit.ActionStop, audit.ServerTarget(s.ServerID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to stop server by schedule", slog.Any("error", err), slog.Any("schedule_id", s.ID))
+		cr.streaming.PublishEvent(ctx, s.ServerID, streaming.NewInfoMessage(entity.InfoErrScheduledStop, true))
+		return
+	}
+
+	cr.streaming.PublishEvent(ctx, s.ServerID, streaming.NewInfoMessage(entity.InfoScheduledStop, false))
+}
+
+// runSchedules launches or stops servers whose schedules fire within (from, to].
+func (cr *CronService) runSchedules(ctx context.Context, from, to time.Time) error {
+	schedules, err := cr.schedules.ListEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range schedules {
+		if due, err := schedule.IsDue(s.StopAt, s.Timezone, from, to); err != nil {
+			slog.ErrorContext(ctx, "Invalid stop time in schedule", slog.Any("error", err), slog.Any("schedule_id", s.ID))
+		} else if due {
+			cr.stopScheduled(ctx, &s)
+		}
+
+		if due, err := schedule.IsDue(s.LaunchAt, s.Timezone, from, to); err != nil {
+			slog.ErrorContext(ctx, "Invalid launch time in schedule", slog.Any("error", err), slog.Any("schedule_id", s.ID))
+		} else if due {
+			cr.launchScheduled(ctx, &s)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		// Existing users were allowed to do everything, so we grant them admin scope to keep the behavior.
+		if _, err := db.ExecContext(ctx, "ALTER TABLE users ADD COLUMN IF NOT EXISTS scopes text[] NOT NULL DEFAULT '{admin}'"); err != nil {
+			return err
+		}
+		if _, err := db.ExecContext(ctx, "ALTER TABLE users ALTER COLUMN scopes SET DEFAULT '{}'"); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.ExecContext(ctx, "ALTER TABLE users DROP COLUMN IF EXISTS scopes"); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.PersonalAccessToken)(nil)).ForeignKey(`("user_id") REFERENCES "users" ("id") ON DELETE CASCADE`).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewCreateIndex().IfNotExists().Model((*model.PersonalAccessToken)(nil)).Index("personal_access_tokens_user_id_idx").Column("user_id").Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.PersonalAccessToken)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.AuditLog)(nil)).ForeignKey(`("actor_user_id") REFERENCES "users" ("id") ON DELETE SET NULL`).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewCreateIndex().IfNotExists().Model((*model.AuditLog)(nil)).Index("audit_logs_created_at_idx").Column("created_at").Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.AuditLog)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
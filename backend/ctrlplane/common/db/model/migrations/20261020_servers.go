@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.Server)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewCreateIndex().IfNotExists().Unique().Model((*model.Server)(nil)).Index("servers_hostname_idx").Column("hostname").Where("hostname <> ''").Exec(ctx); err != nil {
+			return err
+		}
+
+		// Servers launched before servers became first-class are identified as "default".
+		// Empty hostname means the one configured with PREMISES_GAME_DOMAIN.
+		defaultServer := &model.Server{
+			ID:   "default",
+			Name: "Default",
+		}
+		if _, err := db.NewInsert().Model(defaultServer).On("CONFLICT (id) DO NOTHING").Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.Server)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.Schedule)(nil)).
+			ForeignKey(`("server_id") REFERENCES "servers" ("id") ON DELETE CASCADE`).
+			ForeignKey(`("created_by_user_id") REFERENCES "users" ("id") ON DELETE SET NULL`).
+			Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.Schedule)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.Webhook)(nil)).ForeignKey(`("created_by_user_id") REFERENCES "users" ("id") ON DELETE SET NULL`).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.WebhookDelivery)(nil)).ForeignKey(`("webhook_id") REFERENCES "webhooks" ("id") ON DELETE CASCADE`).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewCreateIndex().IfNotExists().Model((*model.WebhookDelivery)(nil)).Index("webhook_deliveries_webhook_id_idx").Column("webhook_id", "id").Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.WebhookDelivery)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewDropTable().IfExists().Model((*model.Webhook)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
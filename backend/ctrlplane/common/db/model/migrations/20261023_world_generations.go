@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.WorldGeneration)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := db.NewCreateIndex().IfNotExists().Model((*model.WorldGeneration)(nil)).
+			Index("world_generations_world_name_idx").
+			Column("world_name", "created_at").
+			Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.WorldGeneration)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewAddColumn().IfNotExists().Model((*model.WorldGeneration)(nil)).
+			ColumnExpr("pinned BOOLEAN NOT NULL DEFAULT false").
+			Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := db.NewCreateTable().IfNotExists().Model((*model.WorldRetentionPolicy)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropTable().IfExists().Model((*model.WorldRetentionPolicy)(nil)).Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewDropColumn().Model((*model.WorldGeneration)(nil)).Column("pinned").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
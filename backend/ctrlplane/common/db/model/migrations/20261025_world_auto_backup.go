@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewAddColumn().IfNotExists().Model((*model.WorldGeneration)(nil)).
+			ColumnExpr("auto_backup BOOLEAN NOT NULL DEFAULT false").
+			Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropColumn().Model((*model.WorldGeneration)(nil)).Column("auto_backup").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		for _, column := range []string{
+			"checksum VARCHAR(64) NOT NULL DEFAULT ''",
+			"corrupt BOOLEAN NOT NULL DEFAULT false",
+			"verified_at TIMESTAMPTZ",
+		} {
+			if _, err := db.NewAddColumn().IfNotExists().Model((*model.WorldGeneration)(nil)).ColumnExpr(column).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropColumn().Model((*model.WorldGeneration)(nil)).Column("checksum", "corrupt", "verified_at").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
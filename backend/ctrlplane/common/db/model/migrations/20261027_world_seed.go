@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewAddColumn().IfNotExists().Model((*model.WorldGeneration)(nil)).ColumnExpr("seed BIGINT").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewDropColumn().Model((*model.WorldGeneration)(nil)).Column("seed").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+func init() {
+	Migrations.MustRegister(func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewAddColumn().IfNotExists().Model((*model.WorldGeneration)(nil)).ColumnExpr("level JSONB").Exec(ctx); err != nil {
+			return err
+		}
+		// Seeds were recorded for uploaded worlds before other metadata was.
+		if _, err := db.NewUpdate().Model((*model.WorldGeneration)(nil)).
+			Set("level = jsonb_build_object('seed', seed::text)").
+			Where("seed IS NOT NULL").
+			Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewDropColumn().Model((*model.WorldGeneration)(nil)).Column("seed").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	}, func(ctx context.Context, db *bun.DB) error {
+		if _, err := db.NewAddColumn().IfNotExists().Model((*model.WorldGeneration)(nil)).ColumnExpr("seed BIGINT").Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewUpdate().Model((*model.WorldGeneration)(nil)).
+			Set("seed = (level->>'seed')::bigint").
+			Where("level->>'seed' IS NOT NULL").
+			Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := db.NewDropColumn().Model((*model.WorldGeneration)(nil)).Column("level").Exec(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/uptrace/bun"
@@ -17,4 +18,145 @@ type User struct {
 	Password      string       `bun:"password,type:varchar(64),notnull"`
 	AddedByUserID *uint        `bun:"added_by_user_id"`
 	Initialized   bool         `bun:"initialized,notnull"`
+	Scopes        []string     `bun:"scopes,type:text[],array,nullzero,notnull,default:'{}'"`
+}
+
+type PersonalAccessToken struct {
+	bun.BaseModel `bun:"table:personal_access_tokens"`
+
+	ID         uint         `bun:"id,pk,autoincrement"`
+	CreatedAt  time.Time    `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UserID     uint         `bun:"user_id,notnull"`
+	Name       string       `bun:"name,type:varchar(64),notnull"`
+	TokenHash  string       `bun:"token_hash,type:varchar(64),notnull,unique"`
+	Scopes     []string     `bun:"scopes,type:text[],array,nullzero,notnull,default:'{}'"`
+	ExpiresAt  bun.NullTime `bun:"expires_at"`
+	LastUsedAt bun.NullTime `bun:"last_used_at"`
+}
+
+type AuditLog struct {
+	bun.BaseModel `bun:"table:audit_logs"`
+
+	ID          uint      `bun:"id,pk,autoincrement"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	ActorUserID *uint     `bun:"actor_user_id"`
+	Actor       *User     `bun:"rel:belongs-to,join:actor_user_id=id"`
+	Action      string    `bun:"action,type:varchar(32),notnull"`
+	Target      string    `bun:"target,type:varchar(255),notnull,default:''"`
+	Result      string    `bun:"result,type:varchar(16),notnull"`
+}
+
+type Server struct {
+	bun.BaseModel `bun:"table:servers"`
+
+	ID        string    `bun:"id,pk,type:varchar(32)"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	Name      string    `bun:"name,type:varchar(64),notnull"`
+	Hostname  string    `bun:"hostname,type:varchar(255),notnull,default:''"`
+}
+
+type Schedule struct {
+	bun.BaseModel `bun:"table:schedules"`
+
+	ID              uint            `bun:"id,pk,autoincrement"`
+	CreatedAt       time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	ServerID        string          `bun:"server_id,type:varchar(32),notnull"`
+	Name            string          `bun:"name,type:varchar(64),notnull"`
+	LaunchAt        string          `bun:"launch_at,type:varchar(64),notnull,default:''"`
+	StopAt          string          `bun:"stop_at,type:varchar(64),notnull,default:''"`
+	Timezone        string          `bun:"timezone,type:varchar(64),notnull,default:'UTC'"`
+	Config          json.RawMessage `bun:"config,type:jsonb"`
+	Enabled         bool            `bun:"enabled,notnull"`
+	CreatedByUserID *uint           `bun:"created_by_user_id"`
+}
+
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks"`
+
+	ID              uint      `bun:"id,pk,autoincrement"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	Name            string    `bun:"name,type:varchar(64),notnull"`
+	URL             string    `bun:"url,type:varchar(2048),notnull"`
+	Secret          string    `bun:"secret,type:varchar(64),notnull"`
+	Format          string    `bun:"format,type:varchar(16),notnull,default:'json'"`
+	EventCodes      []int     `bun:"event_codes,type:integer[],array,nullzero,notnull,default:'{}'"`
+	InfoCodes       []int     `bun:"info_codes,type:integer[],array,nullzero,notnull,default:'{}'"`
+	Enabled         bool      `bun:"enabled,notnull"`
+	CreatedByUserID *uint     `bun:"created_by_user_id"`
+}
+
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries"`
+
+	ID         uint            `bun:"id,pk,autoincrement"`
+	CreatedAt  time.Time       `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	WebhookID  uint            `bun:"webhook_id,notnull"`
+	Payload    json.RawMessage `bun:"payload,type:jsonb"`
+	Attempts   int             `bun:"attempts,notnull"`
+	StatusCode int             `bun:"status_code,notnull"`
+	Error      string          `bun:"error,type:text,notnull,default:''"`
+	Succeeded  bool            `bun:"succeeded,notnull"`
+	FinishedAt bun.NullTime    `bun:"finished_at"`
+}
+
+type WorldGeneration struct {
+	bun.BaseModel `bun:"table:world_generations"`
+
+	ID        uint      `bun:"id,pk,autoincrement"`
+	Key       string    `bun:"key,type:varchar(1024),notnull,unique"`
+	WorldName string    `bun:"world_name,type:varchar(255),notnull"`
+	Gen       string    `bun:"gen,type:varchar(255),notnull"`
+	Size      int64     `bun:"size,notnull"`
+	Format    string    `bun:"format,type:varchar(16),notnull,default:''"`
+	MCVersion string    `bun:"mc_version,type:varchar(64),notnull,default:''"`
+	CreatedBy string    `bun:"created_by,type:varchar(128),notnull,default:''"`
+	CreatedAt time.Time `bun:"created_at,notnull"`
+	Pinned    bool      `bun:"pinned,notnull,default:false"`
+	// AutoBackup is true if the generation was uploaded while the server was running.
+	AutoBackup bool `bun:"auto_backup,notnull,default:false"`
+	// Checksum is the hex-encoded SHA-256 of the object, or empty if it is unknown.
+	Checksum   string    `bun:"checksum,type:varchar(64),notnull,default:''"`
+	Corrupt    bool      `bun:"corrupt,notnull,default:false"`
+	VerifiedAt time.Time `bun:"verified_at,nullzero"`
+	// Level is web.LevelInfo read from level.dat, or nil if it is unknown.
+	Level json.RawMessage `bun:"level,type:jsonb"`
+}
+
+type WorldRetentionPolicy struct {
+	bun.BaseModel `bun:"table:world_retention_policies"`
+
+	WorldName  string    `bun:"world_name,pk,type:varchar(255)"`
+	KeepLast   int       `bun:"keep_last,notnull"`
+	KeepDaily  int       `bun:"keep_daily,notnull"`
+	KeepWeekly int       `bun:"keep_weekly,notnull"`
+	UpdatedAt  time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// WorldDatapack attaches a datapack in the storage to a world.
+type WorldDatapack struct {
+	bun.BaseModel `bun:"table:world_datapacks"`
+
+	WorldName string    `bun:"world_name,pk,type:varchar(255)"`
+	Name      string    `bun:"name,pk,type:varchar(255)"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// ModSetFile is a mod or a plugin in a mod set, which is stored under @modsets/<mod set>/ in the storage.
+type ModSetFile struct {
+	bun.BaseModel `bun:"table:mod_set_files"`
+
+	ModSet    string    `bun:"mod_set,pk,type:varchar(255)"`
+	Name      string    `bun:"name,pk,type:varchar(255)"`
+	Size      int64     `bun:"size,notnull"`
+	Sha256    string    `bun:"sha256,notnull,type:varchar(64)"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// BlobLease keeps a blob of incremental backups from being collected
+// while a runner is uploading a generation which refers to it.
+type BlobLease struct {
+	bun.BaseModel `bun:"table:blob_leases"`
+
+	Hash      string    `bun:"hash,pk,type:varchar(64)"`
+	ExpiresAt time.Time `bun:"expires_at,notnull"`
 }
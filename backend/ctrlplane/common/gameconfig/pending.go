@@ -0,0 +1,107 @@
+package gameconfig
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/config"
+	"github.com/kofuk/premises/backend/ctrlplane/common/launcher"
+	"github.com/kofuk/premises/backend/ctrlplane/common/mcversions"
+)
+
+// FromPendingConfig builds a launch config from the config edited by users.
+func FromPendingConfig(ctx context.Context, versions *mcversions.MCVersionsService, config web.PendingConfig, cfg *config.Config) (*launcher.LaunchConfig, error) {
+	if config.ServerVersion == nil || *config.ServerVersion == "" {
+		return nil, errors.New("server version is not set")
+	}
+	result := New()
+
+	if config.MachineType == nil {
+		return nil, errors.New("machine type is not set")
+	}
+	result.C.MachineType = *config.MachineType
+
+	serverInfo, err := versions.GetServerInfo(ctx, *config.ServerVersion)
+	if err != nil {
+		return nil, err
+	}
+	result.SetServer(*config.ServerVersion, serverInfo.DownloadURL)
+	result.SetDetectServerVersion(*config.GuessVersion)
+	result.C.Server.ManifestOverride = versions.GetOverridenManifestURL()
+	result.C.Server.CustomCommand = serverInfo.LaunchCommand
+	result.C.Server.JavaVersion = serverInfo.JavaVersion
+	if config.ServerFlavor != nil {
+		result.C.Server.Flavor = *config.ServerFlavor
+	}
+	if config.ModSet != nil {
+		result.C.Server.ModSet = *config.ModSet
+	}
+	if config.MaxCrashLoops != nil {
+		result.C.Server.MaxCrashLoops = *config.MaxCrashLoops
+	}
+	if config.JVMProfile != nil {
+		result.C.Server.JVMProfile = *config.JVMProfile
+		if *config.JVMProfile == runner.JVMProfileCustom && config.JVMArgs != nil {
+			result.C.Server.JVMArgs = *config.JVMArgs
+		}
+	}
+	if config.InactiveTimeout != nil {
+		result.C.Server.InactiveTimeout = *config.InactiveTimeout
+	} else {
+		result.C.Server.InactiveTimeout = -1
+	}
+
+	if config.WorldSource != nil && *config.WorldSource == "backups" {
+		if config.WorldName == nil || config.BackupGen == nil {
+			return nil, errors.New("both worldName and backupGen must be set if worldSource is backups")
+		}
+
+		if err := result.SetWorld(*config.WorldName, *config.BackupGen); err != nil {
+			return nil, err
+		}
+	} else {
+		if config.WorldName == nil || *config.WorldName == "" {
+			return nil, errors.New("world name is not set")
+		}
+		seed := ""
+		if config.Seed != nil {
+			seed = *config.Seed
+		}
+		levelType := "default"
+		if config.LevelType != nil {
+			levelType = *config.LevelType
+		}
+		result.GenerateWorld(*config.WorldName, seed)
+		if err := result.SetLevelType(levelType); err != nil {
+			return nil, err
+		}
+	}
+	if config.BackupFormat != nil {
+		result.C.World.BackupFormat = *config.BackupFormat
+	}
+	if config.AutoBackupInterval != nil {
+		result.C.World.AutoBackupInterval = *config.AutoBackupInterval
+	}
+	if config.ServerPropOverride != nil {
+		result.C.Server.ServerPropOverride = *config.ServerPropOverride
+	}
+	if config.Motd != nil {
+		result.SetMotd(*config.Motd)
+	}
+
+	result.SetOperators(cfg.Operators)
+	result.SetWhitelist(cfg.Whitelist)
+
+	if config.OtlpEndpoint != nil {
+		result.C.Observability.OtlpEndpoint = *config.OtlpEndpoint
+		metricExportIntervalSec := 10
+		if config.MetricExportIntervalSec != nil {
+			metricExportIntervalSec = *config.MetricExportIntervalSec
+		}
+		result.C.Observability.MetricExportIntervalMs = metricExportIntervalSec * 1000
+	}
+
+	return &result.C, nil
+}
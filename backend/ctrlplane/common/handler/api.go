@@ -8,29 +8,33 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"dario.cat/mergo"
 	"github.com/kofuk/premises/backend/common/entity"
 	"github.com/kofuk/premises/backend/common/entity/runner"
 	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/manifest"
 	potel "github.com/kofuk/premises/backend/common/otel"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
 	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
-	"github.com/kofuk/premises/backend/ctrlplane/common/config"
 	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
 	"github.com/kofuk/premises/backend/ctrlplane/common/gameconfig"
 	"github.com/kofuk/premises/backend/ctrlplane/common/launcher"
 	"github.com/kofuk/premises/backend/ctrlplane/common/monitor"
 	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
 	"github.com/labstack/echo/v5"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	CacheKeyWorlds           = "worlds"
 	CacheKeyMCVersions       = "mcversions"
 	CacheKeySystemInfoPrefix = "system-info"
 )
@@ -39,6 +43,7 @@ func (h *Handler) handleStream(c *echo.Context) error {
 	jsonMode := c.Request().Header.Get("Accept") == "application/json"
 
 	userID := c.Get("access_token").(*auth.Token).UserID
+	serverID := c.Get("server-id").(string)
 
 	writeEvent := func(eventName string, message []byte) error {
 		if jsonMode {
@@ -70,7 +75,7 @@ func (h *Handler) handleStream(c *echo.Context) error {
 		return nil
 	}
 
-	subscription, err := h.StreamingService.SubscribeEvent(c.Request().Context())
+	subscription, err := h.StreamingService.SubscribeEvent(c.Request().Context(), serverID)
 	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "Failed to connect to stream", slog.Any("error", err))
 		return c.String(http.StatusInternalServerError, "")
@@ -102,6 +107,11 @@ out:
 	for {
 		select {
 		case status := <-eventChannel:
+			if status.Type == streaming.LogsMessage {
+				// Server logs are delivered through a dedicated stream.
+				continue
+			}
+
 			body, _ := json.Marshal(status.Body)
 			if err := writeEvent(status.Type.String(), body); err != nil {
 				slog.ErrorContext(c.Request().Context(), "Failed to write server-sent event", slog.Any("error", err))
@@ -116,82 +126,16 @@ out:
 	return nil
 }
 
-func (h *Handler) convertToLaunchConfig(ctx context.Context, config web.PendingConfig, cfg *config.Config) (*launcher.LaunchConfig, error) {
-	if config.ServerVersion == nil || *config.ServerVersion == "" {
-		return nil, errors.New("server version is not set")
-	}
-	result := gameconfig.New()
-
-	if config.MachineType == nil {
-		return nil, errors.New("machine type is not set")
-	}
-	result.C.MachineType = *config.MachineType
-
-	serverInfo, err := h.MCVersionsService.GetServerInfo(ctx, *config.ServerVersion)
-	if err != nil {
-		return nil, err
-	}
-	result.SetServer(*config.ServerVersion, serverInfo.DownloadURL)
-	result.SetDetectServerVersion(*config.GuessVersion)
-	result.C.Server.ManifestOverride = h.MCVersionsService.GetOverridenManifestURL()
-	result.C.Server.CustomCommand = serverInfo.LaunchCommand
-	result.C.Server.JavaVersion = serverInfo.JavaVersion
-	if config.InactiveTimeout != nil {
-		result.C.Server.InactiveTimeout = *config.InactiveTimeout
-	} else {
-		result.C.Server.InactiveTimeout = -1
-	}
-
-	if config.WorldSource != nil && *config.WorldSource == "backups" {
-		if config.WorldName == nil || config.BackupGen == nil {
-			return nil, errors.New("both worldName and backupGen must be set if worldSource is backups")
-		}
-
-		if err := result.SetWorld(*config.WorldName, *config.BackupGen); err != nil {
-			return nil, err
-		}
-	} else {
-		if config.WorldName == nil || *config.WorldName == "" {
-			return nil, errors.New("world name is not set")
-		}
-		seed := ""
-		if config.Seed != nil {
-			seed = *config.Seed
-		}
-		levelType := "default"
-		if config.LevelType != nil {
-			levelType = *config.LevelType
-		}
-		result.GenerateWorld(*config.WorldName, seed)
-		if err := result.SetLevelType(levelType); err != nil {
-			return nil, err
-		}
-	}
-	if config.ServerPropOverride != nil {
-		result.C.Server.ServerPropOverride = *config.ServerPropOverride
-	}
-	if config.Motd != nil {
-		result.SetMotd(*config.Motd)
-	}
-
-	result.SetOperators(cfg.Operators)
-	result.SetWhitelist(cfg.Whitelist)
-
-	if config.OtlpEndpoint != nil {
-		result.C.Observability.OtlpEndpoint = *config.OtlpEndpoint
-		metricExportIntervalSec := 10
-		if config.MetricExportIntervalSec != nil {
-			metricExportIntervalSec = *config.MetricExportIntervalSec
-		}
-		result.C.Observability.MetricExportIntervalMs = metricExportIntervalSec * 1000
-	}
-
-	return &result.C, nil
+func pendingConfigKey(serverID string) string {
+	return fmt.Sprintf("pending-config:%s", serverID)
 }
 
 func (h *Handler) handleApiLaunch(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+	serverID := c.Get("server-id").(string)
+
 	var config web.PendingConfig
-	if err := h.KVS.Get(c.Request().Context(), "pending-config", &config); err != nil {
+	if err := h.KVS.Get(c.Request().Context(), pendingConfigKey(serverID), &config); err != nil {
 		slog.ErrorContext(c.Request().Context(), "Failed to get pending config", slog.Any("error", err))
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
 			Success:   false,
@@ -199,7 +143,7 @@ func (h *Handler) handleApiLaunch(c *echo.Context) error {
 		})
 	}
 
-	launchConfig, err := h.convertToLaunchConfig(c.Request().Context(), config, h.cfg)
+	launchConfig, err := gameconfig.FromPendingConfig(c.Request().Context(), h.MCVersionsService, config, h.cfg)
 	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "Failed to convert to launch config", slog.Any("error", err))
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
@@ -208,7 +152,15 @@ func (h *Handler) handleApiLaunch(c *echo.Context) error {
 		})
 	}
 
-	if err := h.launcherService.Launch(c.Request().Context(), launchConfig); err != nil {
+	err = h.launcherService.Launch(c.Request().Context(), serverID, launchConfig)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionLaunch, audit.WorldTarget(*config.WorldName), audit.ResultOf(err))
+	if errors.Is(err, launcher.ErrAlreadyRunning) {
+		return c.JSON(http.StatusConflict, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrServerRunning,
+		})
+	}
+	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "Failed to launch server", slog.Any("error", err))
 		// TODO: Check error types and return appropriate error codes.
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
@@ -222,13 +174,39 @@ func (h *Handler) handleApiLaunch(c *echo.Context) error {
 	})
 }
 
+// maxStopDelay is the longest countdown in seconds which users can choose when stopping the server.
+const maxStopDelay = 60 * 60
+
 func (h *Handler) handleApiStop(c *echo.Context) error {
-	if err := h.runnerActionService.Push(c.Request().Context(), "default", runner.Action{
+	userID := c.Get("access_token").(*auth.Token).UserID
+	serverID := c.Get("server-id").(string)
+
+	var req web.StopReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+	if req.Delay < 0 || req.Delay > maxStopDelay {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.runnerActionService.Push(c.Request().Context(), serverID, runner.Action{
 		Type: runner.ActionStop,
 		Metadata: runner.RequestMeta{
 			Traceparent: potel.TraceContextFromContext(c.Request().Context()),
 		},
-	}); err != nil {
+		Actor: int(userID),
+		Stop: &runner.StopConfig{
+			Delay: req.Delay,
+		},
+	})
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionStop, audit.ServerTarget(serverID), audit.ResultOf(err))
+	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "Unable to write action", slog.Any("error", err))
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -241,55 +219,90 @@ func (h *Handler) handleApiStop(c *echo.Context) error {
 	})
 }
 
-func (h *Handler) handleApiListWorlds(c *echo.Context) error {
-	if val, err := h.redis.Get(c.Request().Context(), CacheKeyWorlds).Result(); err == nil {
-		return c.JSONBlob(http.StatusOK, []byte(val))
-	} else if err != redis.Nil {
-		slog.ErrorContext(c.Request().Context(), "Error retrieving backups from cache", slog.Any("error", err))
-	}
-
-	slog.InfoContext(c.Request().Context(), "cache miss", slog.String("cache_key", CacheKeyWorlds))
+func (h *Handler) handleApiCancelStop(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+	serverID := c.Get("server-id").(string)
 
-	worlds, err := h.worldService.GetWorlds(c.Request().Context())
+	err := h.runnerActionService.Push(c.Request().Context(), serverID, runner.Action{
+		Type: runner.ActionCancelStop,
+		Metadata: runner.RequestMeta{
+			Traceparent: potel.TraceContextFromContext(c.Request().Context()),
+		},
+		Actor: int(userID),
+	})
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCancelStop, audit.ServerTarget(serverID), audit.ResultOf(err))
 	if err != nil {
-		slog.ErrorContext(c.Request().Context(), "Failed to retrieve backup list", slog.Any("error", err))
+		slog.ErrorContext(c.Request().Context(), "Unable to write action", slog.Any("error", err))
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
-			ErrorCode: entity.ErrBackup,
+			ErrorCode: entity.ErrRemote,
 		})
 	}
 
-	resp := web.SuccessfulResponse[[]web.World]{
+	return c.JSON(http.StatusAccepted, web.SuccessfulResponse[any]{
 		Success: true,
-		Data:    worlds,
+	})
+}
+
+func (h *Handler) handleApiListWorlds(c *echo.Context) error {
+	opts := world.ListOptions{
+		Sort: c.QueryParam("sort"),
+		Desc: c.QueryParam("order") == "desc",
+	}
+	if opts.Sort == "" {
+		opts.Sort = world.SortByName
+	}
+	if !world.IsValidSort(opts.Sort) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	// Without page parameter, all worlds are returned for compatibility.
+	if c.QueryParam("page") != "" {
+		page, perPage, ok := parsePagination(c.QueryParam("page"), c.QueryParam("perPage"))
+		if !ok {
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrBadRequest,
+			})
+		}
+		opts.Limit = perPage
+		opts.Offset = (page - 1) * perPage
 	}
 
-	jsonResp, err := json.Marshal(resp)
+	worlds, total, err := h.worldService.ListWorlds(c.Request().Context(), opts)
 	if err != nil {
-		slog.ErrorContext(c.Request().Context(), "Failed to marshal backup list", slog.Any("error", err))
+		slog.ErrorContext(c.Request().Context(), "Failed to retrieve backup list", slog.Any("error", err))
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
-			ErrorCode: entity.ErrInternal,
+			ErrorCode: entity.ErrBackup,
 		})
 	}
 
-	if _, err := h.redis.Set(c.Request().Context(), CacheKeyWorlds, jsonResp, 5*time.Second).Result(); err != nil {
-		slog.ErrorContext(c.Request().Context(), "Failed to store backup list", slog.Any("error", err))
-	}
-
-	return c.JSON(http.StatusOK, resp)
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(total))
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.World]{
+		Success: true,
+		Data:    worlds,
+	})
 }
 
 func (h *Handler) handleApiDeleteWorld(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
 	var req web.DeleteWorldReq
-	if err := c.Bind(&req); err != nil {
+	if err := c.Bind(&req); err != nil || !world.IsGenerationKey(req.ID) {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrBadRequest,
 		})
 	}
 
-	if err := h.worldService.DeleteWorld(c.Request().Context(), req.ID); err != nil {
+	err := h.worldService.DeleteWorld(c.Request().Context(), req.ID)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteWorld, audit.WorldTarget(req.ID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to delete world", slog.Any("error", err))
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrInternal,
@@ -342,7 +355,7 @@ func (h *Handler) handleApiMcversions(c *echo.Context) error {
 }
 
 func (h *Handler) handleApiSystemInfo(c *echo.Context) error {
-	data, err := monitor.GetSystemInfo(c.Request().Context(), h.cfg, &h.KVS)
+	data, err := monitor.GetSystemInfo(c.Request().Context(), h.cfg, &h.KVS, c.Get("server-id").(string))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -355,8 +368,39 @@ func (h *Handler) handleApiSystemInfo(c *echo.Context) error {
 	})
 }
 
+func (h *Handler) handleApiCrashReport(c *echo.Context) error {
+	report, err := monitor.GetCrashReport(c.Request().Context(), h.cfg, &h.KVS, c.Get("server-id").(string))
+	if errors.Is(err, redis.Nil) {
+		return c.JSON(http.StatusNotFound, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrNotFound,
+		})
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to get crash report", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	report.URL, err = h.worldService.GetPresignedGetURL(c.Request().Context(), report.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to get presigned URL", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.CrashReport]{
+		Success: true,
+		Data:    *report,
+	})
+}
+
 func (h *Handler) handleApiWorldInfo(c *echo.Context) error {
-	data, err := monitor.GetWorldInfo(c.Request().Context(), h.cfg, &h.KVS)
+	data, err := monitor.GetWorldInfo(c.Request().Context(), h.cfg, &h.KVS, c.Get("server-id").(string))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -369,6 +413,113 @@ func (h *Handler) handleApiWorldInfo(c *echo.Context) error {
 	})
 }
 
+func (h *Handler) handleApiPlayers(c *echo.Context) error {
+	data, err := monitor.GetPlayers(c.Request().Context(), h.cfg, &h.KVS, c.Get("server-id").(string))
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.ErrorContext(c.Request().Context(), "Failed to retrieve player list", slog.Any("error", err))
+			return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrInternal,
+			})
+		}
+
+		// The server is not running or hasn't reported players yet.
+		data = &web.PlayerList{
+			Players: []string{},
+		}
+	}
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.PlayerList]{
+		Success: true,
+		Data:    *data,
+	})
+}
+
+var playerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]{1,16}$`)
+
+func isValidPlayerName(name string) bool {
+	return playerNameRegexp.MatchString(name)
+}
+
+// Bounds of the auto backup interval in minutes.
+const (
+	minAutoBackupInterval = 10
+	maxAutoBackupInterval = 24 * 60
+)
+
+// maxCrashLoops is the largest number of consecutive crashes which users can allow.
+const maxCrashLoops = 100
+
+// Bounds of the extra JVM options of the custom JVM profile.
+const (
+	maxJVMArgs      = 32
+	maxJVMArgLength = 256
+)
+
+// machineHeapSize returns the heap size in MiB which runners on the machine type give to the server.
+// Runners leave 1 GiB for the system.
+func machineHeapSize(machineType string) int {
+	memSizeGB, _ := strconv.Atoi(strings.TrimSuffix(machineType, "g"))
+	return memSizeGB*1024 - 1024
+}
+
+// allowedJVMXXOptions are the names of -XX options which users can set.
+// Options which run commands or load code, such as OnError or Flags, are not included.
+var allowedJVMXXOptions = []string{
+	"AlwaysPreTouch",
+	"ConcGCThreads",
+	"DisableExplicitGC",
+	"G1HeapRegionSize",
+	"G1HeapWastePercent",
+	"G1MaxNewSizePercent",
+	"G1MixedGCCountTarget",
+	"G1MixedGCLiveThresholdPercent",
+	"G1NewSizePercent",
+	"G1RSetUpdatingPauseIntervalPercent",
+	"G1ReservePercent",
+	"InitiatingHeapOccupancyPercent",
+	"MaxGCPauseMillis",
+	"MaxMetaspaceSize",
+	"MaxTenuringThreshold",
+	"MetaspaceSize",
+	"ParallelGCThreads",
+	"ParallelRefProcEnabled",
+	"PerfDisableSharedMem",
+	"ReservedCodeCacheSize",
+	"ShenandoahGCMode",
+	"ShenandoahGCHeuristics",
+	"SurvivorRatio",
+	"UnlockExperimentalVMOptions",
+	"UseCompressedOops",
+	"UseG1GC",
+	"UseLargePages",
+	"UseNUMA",
+	"UseParallelGC",
+	"UseShenandoahGC",
+	"UseStringDeduplication",
+	"UseTransparentHugePages",
+	"UseZGC",
+	"ZGenerational",
+}
+
+// isValidJVMArg reports whether arg can be passed to the JVM as an extra option.
+// Only system properties and the -XX options in allowedJVMXXOptions are accepted,
+// because other options (e.g. -javaagent or -XX:OnError) can run arbitrary code on the runner.
+func isValidJVMArg(arg string) bool {
+	if arg == "" || maxJVMArgLength < len(arg) || strings.ContainsFunc(arg, unicode.IsControl) {
+		return false
+	}
+	if property, ok := strings.CutPrefix(arg, "-D"); ok {
+		name, _, _ := strings.Cut(property, "=")
+		return name != ""
+	}
+	if option, ok := strings.CutPrefix(arg, "-XX:"); ok {
+		name, _, _ := strings.Cut(strings.TrimLeft(option, "+-"), "=")
+		return slices.Contains(allowedJVMXXOptions, name)
+	}
+	return false
+}
+
 func (h *Handler) validateAndNormalizeConfig(config *web.PendingConfig) bool {
 	if config.MachineType == nil || !slices.Contains([]string{"2g", "4g", "12g", "24g", "48g", "96g", "128g"}, *config.MachineType) {
 		config.MachineType = nil
@@ -378,6 +529,14 @@ func (h *Handler) validateAndNormalizeConfig(config *web.PendingConfig) bool {
 		config.ServerVersion = nil
 		return false
 	}
+	if config.ServerFlavor != nil && !runner.IsValidFlavor(*config.ServerFlavor) {
+		config.ServerFlavor = nil
+		return false
+	}
+	if config.ModSet != nil && *config.ModSet != "" && !world.IsValidModSetName(*config.ModSet) {
+		config.ModSet = nil
+		return false
+	}
 	if config.GuessVersion == nil {
 		config.GuessVersion = web.BoolP(false)
 	}
@@ -389,7 +548,7 @@ func (h *Handler) validateAndNormalizeConfig(config *web.PendingConfig) bool {
 	}
 	if config.WorldName == nil {
 		return false
-	} else if *config.WorldName == "" {
+	} else if *config.WorldName == "" || strings.HasPrefix(*config.WorldName, "@") || strings.ContainsRune(*config.WorldName, '/') {
 		config.WorldName = nil
 		return false
 	}
@@ -400,6 +559,31 @@ func (h *Handler) validateAndNormalizeConfig(config *web.PendingConfig) bool {
 			return false
 		}
 	}
+	if config.BackupFormat != nil && !slices.Contains([]string{runner.BackupFormatArchive, runner.BackupFormatIncremental}, *config.BackupFormat) {
+		config.BackupFormat = nil
+		return false
+	}
+	if config.AutoBackupInterval != nil && *config.AutoBackupInterval != 0 && (*config.AutoBackupInterval < minAutoBackupInterval || maxAutoBackupInterval < *config.AutoBackupInterval) {
+		config.AutoBackupInterval = nil
+		return false
+	}
+	if config.MaxCrashLoops != nil && (*config.MaxCrashLoops < 0 || maxCrashLoops < *config.MaxCrashLoops) {
+		config.MaxCrashLoops = nil
+		return false
+	}
+	if config.JVMProfile != nil && !runner.IsValidJVMProfile(*config.JVMProfile) {
+		config.JVMProfile = nil
+		return false
+	}
+	if config.JVMProfile != nil && *config.JVMProfile == runner.JVMProfileZGC && machineHeapSize(*config.MachineType) < runner.ZGCMinHeapSize {
+		// ZGC doesn't pay off on small heaps.
+		config.JVMProfile = nil
+		return false
+	}
+	if config.JVMArgs != nil && (len(*config.JVMArgs) > maxJVMArgs || slices.ContainsFunc(*config.JVMArgs, func(arg string) bool { return !isValidJVMArg(arg) })) {
+		config.JVMArgs = nil
+		return false
+	}
 	if *config.WorldSource == "new-world" {
 		if config.LevelType != nil && !slices.Contains([]string{"default", "flat", "largeBiomes", "amplified", "buffet"}, *config.LevelType) {
 			config.LevelType = nil
@@ -417,9 +601,10 @@ func (h *Handler) validateAndNormalizeConfig(config *web.PendingConfig) bool {
 }
 
 func (h *Handler) handleApiGetConfig(c *echo.Context) error {
-	var config web.PendingConfig
+	serverID := c.Get("server-id").(string)
 
-	if err := h.KVS.Get(c.Request().Context(), "pending-config", &config); err != nil {
+	var config web.PendingConfig
+	if err := h.KVS.Get(c.Request().Context(), pendingConfigKey(serverID), &config); err != nil {
 		config = web.PendingConfig{
 			MachineType:     web.StringP("4g"),
 			GuessVersion:    web.BoolP(true),
@@ -429,7 +614,7 @@ func (h *Handler) handleApiGetConfig(c *echo.Context) error {
 
 	isValid := h.validateAndNormalizeConfig(&config)
 
-	if err := h.KVS.Set(c.Request().Context(), "pending-config", config, 30*24*time.Hour); err != nil {
+	if err := h.KVS.Set(c.Request().Context(), pendingConfigKey(serverID), config, 30*24*time.Hour); err != nil {
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrInternal,
@@ -445,6 +630,8 @@ func (h *Handler) handleApiGetConfig(c *echo.Context) error {
 }
 
 func (h *Handler) handleApiUpdateConfig(c *echo.Context) error {
+	serverID := c.Get("server-id").(string)
+
 	var newConfig web.PendingConfig
 	if err := c.Bind(&newConfig); err != nil {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
@@ -454,7 +641,7 @@ func (h *Handler) handleApiUpdateConfig(c *echo.Context) error {
 	}
 
 	var config web.PendingConfig
-	if err := h.KVS.Get(c.Request().Context(), "pending-config", &config); err != nil {
+	if err := h.KVS.Get(c.Request().Context(), pendingConfigKey(serverID), &config); err != nil {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrBadRequest,
@@ -471,7 +658,7 @@ func (h *Handler) handleApiUpdateConfig(c *echo.Context) error {
 
 	isValid := h.validateAndNormalizeConfig(&config)
 
-	if err := h.KVS.Set(c.Request().Context(), "pending-config", config, 30*24*time.Hour); err != nil {
+	if err := h.KVS.Set(c.Request().Context(), pendingConfigKey(serverID), config, 30*24*time.Hour); err != nil {
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrInternal,
@@ -487,7 +674,68 @@ func (h *Handler) handleApiUpdateConfig(c *echo.Context) error {
 	})
 }
 
+func (h *Handler) handleApiUpdateRunningConfig(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+	serverID := c.Get("server-id").(string)
+
+	var req web.UpdateRunningConfigReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	var config runner.GameConfig
+	if req.Motd != nil {
+		config.Motd = *req.Motd
+	}
+	if req.Difficulty != nil {
+		if !slices.Contains([]string{"peaceful", "easy", "normal", "hard"}, *req.Difficulty) {
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrBadRequest,
+			})
+		}
+		config.World.Difficulty = *req.Difficulty
+	}
+	for _, player := range slices.Concat(req.Operators, req.Whitelist) {
+		if !isValidPlayerName(player) {
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrBadRequest,
+			})
+		}
+	}
+	config.Operators = req.Operators
+	config.Whitelist = req.Whitelist
+	config.Server.ServerPropOverride = req.ServerPropOverride
+
+	err := h.runnerActionService.Push(c.Request().Context(), serverID, runner.Action{
+		Type: runner.ActionReconfigure,
+		Metadata: runner.RequestMeta{
+			Traceparent: potel.TraceContextFromContext(c.Request().Context()),
+		},
+		Actor:  int(userID),
+		Config: &config,
+	})
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionReconfigure, audit.ServerTarget(serverID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to write action", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrRemote,
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
 func (h *Handler) handleApiCreateWorldDownloadLink(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
 	var req web.CreateWorldLinkReq
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
@@ -496,7 +744,16 @@ func (h *Handler) handleApiCreateWorldDownloadLink(c *echo.Context) error {
 		})
 	}
 
+	if strings.HasSuffix(req.ID, manifest.Extension) {
+		// Incremental generations can't be downloaded as a single archive.
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
 	url, err := h.worldService.GetPresignedGetURLWithLifetime(c.Request().Context(), req.ID, time.Minute)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateDownloadLink, audit.WorldTarget(req.ID), audit.ResultOf(err))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -513,6 +770,8 @@ func (h *Handler) handleApiCreateWorldDownloadLink(c *echo.Context) error {
 }
 
 func (h *Handler) handleApiCreateWorldUploadLink(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
 	var req web.CreateWorldUploadLinkReq
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
@@ -521,7 +780,7 @@ func (h *Handler) handleApiCreateWorldUploadLink(c *echo.Context) error {
 		})
 	}
 
-	if strings.ContainsAny(req.WorldName, "@/\\") {
+	if !world.IsValidWorldName(req.WorldName) {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrBadRequest,
@@ -543,9 +802,11 @@ func (h *Handler) handleApiCreateWorldUploadLink(c *echo.Context) error {
 		})
 	}
 
-	fileName := fmt.Sprintf("%s/user_uploaded_world%s", req.WorldName, ext)
+	// The archive is uploaded aside, and moved to the world after it is validated.
+	fileName := fmt.Sprintf("%s%s/user_uploaded_world%s", world.UploadPrefix, req.WorldName, ext)
 
 	url, err := h.worldService.GetPresignedPutURLWithLifetime(c.Request().Context(), fileName, time.Minute)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateUploadLink, audit.WorldTarget(req.WorldName), audit.ResultOf(err))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -553,14 +814,137 @@ func (h *Handler) handleApiCreateWorldUploadLink(c *echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.DelegatedURL]{
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldUploadLink]{
 		Success: true,
-		Data: web.DelegatedURL{
+		Data: web.WorldUploadLink{
 			URL: url,
+			ID:  fileName,
 		},
 	})
 }
 
+func (h *Handler) handleApiCompleteWorldUploadLink(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CompleteWorldUploadLinkReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	var userName string
+	if err := h.db.NewSelect().Model((*model.User)(nil)).Column("name").Where("id = ?", userID).Scan(c.Request().Context(), &userName); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to get user name", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	if err := h.worldService.CheckUpload(c.Request().Context(), req.ID); err != nil {
+		if errors.Is(err, world.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Unable to check world upload", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	status := web.WorldUploadStatus{Status: web.WorldUploadValidating}
+	var previous web.WorldUploadStatus
+	if err := h.KVS.GetSet(c.Request().Context(), worldUploadStatusKey(req.ID), status, worldUploadStatusLifetime, &previous); err != nil && !errors.Is(err, redis.Nil) {
+		slog.ErrorContext(c.Request().Context(), "Unable to save world upload status", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+	if previous.Status != web.WorldUploadValidating {
+		// Validation reads the whole archive, so it runs in background. The result is saved for handleApiGetWorldUploadStatus.
+		go h.validateWorldUpload(context.WithoutCancel(c.Request().Context()), userID, userName, req.ID)
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldUploadStatus]{
+		Success: true,
+		Data:    status,
+	})
+}
+
+func worldUploadStatusKey(key string) string {
+	return fmt.Sprintf("world-upload:%s", key)
+}
+
+// worldUploadStatusLifetime is how long the result of validation is kept for the user to check.
+const worldUploadStatusLifetime = 24 * time.Hour
+
+func (h *Handler) validateWorldUpload(ctx context.Context, userID uint, userName, key string) {
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+
+	gen, err := h.worldService.CompleteUpload(ctx, key, audit.UserTarget(userName))
+	h.auditService.Record(ctx, userID, audit.ActionCompleteUpload, audit.WorldTarget(strings.TrimPrefix(key, world.UploadPrefix)), audit.ResultOf(err))
+
+	status := web.WorldUploadStatus{
+		Status:     web.WorldUploadCompleted,
+		Generation: gen,
+	}
+	if err != nil {
+		status = web.WorldUploadStatus{
+			Status:    web.WorldUploadFailed,
+			ErrorCode: entity.ErrInternal,
+		}
+		if errors.Is(err, world.ErrNotFound) {
+			status.ErrorCode = entity.ErrNotFound
+		} else if errors.Is(err, world.ErrInvalidArchive) {
+			slog.InfoContext(ctx, "Rejected uploaded world", slog.Any("error", err))
+			status.ErrorCode = entity.ErrInvalidWorld
+		} else {
+			slog.ErrorContext(ctx, "Unable to complete world upload", slog.Any("error", err))
+		}
+	}
+
+	if err := h.KVS.Set(ctx, worldUploadStatusKey(key), status, worldUploadStatusLifetime); err != nil {
+		slog.ErrorContext(ctx, "Unable to save world upload status", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) handleApiGetWorldUploadStatus(c *echo.Context) error {
+	var req web.CompleteWorldUploadLinkReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	var status web.WorldUploadStatus
+	if err := h.KVS.Get(c.Request().Context(), worldUploadStatusKey(req.ID), &status); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Unable to get world upload status", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldUploadStatus]{
+		Success: true,
+		Data:    status,
+	})
+}
+
 func (h *Handler) handleApiQuickUndoSnapshot(c *echo.Context) error {
 	userID := c.Get("access_token").(*auth.Token).UserID
 
@@ -579,7 +963,7 @@ func (h *Handler) handleApiQuickUndoSnapshot(c *echo.Context) error {
 		})
 	}
 
-	if err := h.runnerActionService.Push(c.Request().Context(), "default", runner.Action{
+	err := h.runnerActionService.Push(c.Request().Context(), c.Get("server-id").(string), runner.Action{
 		Type: runner.ActionSnapshot,
 		Metadata: runner.RequestMeta{
 			Traceparent: potel.TraceContextFromContext(c.Request().Context()),
@@ -588,7 +972,9 @@ func (h *Handler) handleApiQuickUndoSnapshot(c *echo.Context) error {
 		Snapshot: &runner.SnapshotConfig{
 			Slot: config.Slot,
 		},
-	}); err != nil {
+	})
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionSnapshot, audit.SlotTarget(config.Slot), audit.ResultOf(err))
+	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "Unable to write action", slog.Any("error", err))
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -619,7 +1005,7 @@ func (h *Handler) handleApiQuickUndoUndo(c *echo.Context) error {
 		})
 	}
 
-	if err := h.runnerActionService.Push(c.Request().Context(), "default", runner.Action{
+	err := h.runnerActionService.Push(c.Request().Context(), c.Get("server-id").(string), runner.Action{
 		Type: runner.ActionUndo,
 		Metadata: runner.RequestMeta{
 			Traceparent: potel.TraceContextFromContext(c.Request().Context()),
@@ -628,7 +1014,9 @@ func (h *Handler) handleApiQuickUndoUndo(c *echo.Context) error {
 		Snapshot: &runner.SnapshotConfig{
 			Slot: config.Slot,
 		},
-	}); err != nil {
+	})
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUndo, audit.SlotTarget(config.Slot), audit.ResultOf(err))
+	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "Unable to write action", slog.Any("error", err))
 		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
 			Success:   false,
@@ -642,8 +1030,8 @@ func (h *Handler) handleApiQuickUndoUndo(c *echo.Context) error {
 }
 
 func setupApiQuickUndoRoutes(h *Handler, group *echo.Group) {
-	group.POST("/snapshot", h.handleApiQuickUndoSnapshot, scope(auth.ScopeAdmin))
-	group.POST("/undo", h.handleApiQuickUndoUndo, scope(auth.ScopeAdmin))
+	group.POST("/snapshot", h.handleApiQuickUndoSnapshot, scope(auth.ScopeServerControl))
+	group.POST("/undo", h.handleApiQuickUndoUndo, scope(auth.ScopeServerControl, auth.ScopeWorldWrite))
 }
 
 func (h *Handler) handleApiUsersChangePassword(c *echo.Context) error {
@@ -702,9 +1090,10 @@ func (h *Handler) handleApiUsersChangePassword(c *echo.Context) error {
 }
 
 func (h *Handler) handleApiUsersAdd(c *echo.Context) error {
-	userID := c.Get("access_token").(*auth.Token).UserID
+	token := c.Get("access_token").(*auth.Token)
+	userID := token.UserID
 
-	var req web.PasswordCredential
+	var req web.AddUserReq
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
 			Success:   false,
@@ -725,6 +1114,27 @@ func (h *Handler) handleApiUsersAdd(c *echo.Context) error {
 		})
 	}
 
+	scopes := auth.DefaultUserScopes
+	if len(req.Scopes) != 0 {
+		var err error
+		scopes, err = auth.ParseScopes(req.Scopes)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrBadRequest,
+			})
+		}
+	}
+	for _, s := range scopes {
+		// Users can't grant scopes which they don't have.
+		if !token.HasScope(s) {
+			return c.JSON(http.StatusForbidden, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrCredential,
+			})
+		}
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "error hashing password", slog.Any("error", err))
@@ -739,9 +1149,12 @@ func (h *Handler) handleApiUsersAdd(c *echo.Context) error {
 		Password:      string(hashedPassword),
 		AddedByUserID: &userID,
 		Initialized:   false,
+		Scopes:        auth.ScopesToStrings(scopes),
 	}
 
-	if _, err := h.db.NewInsert().Model(user).Exec(c.Request().Context()); err != nil {
+	_, err = h.db.NewInsert().Model(user).Exec(c.Request().Context())
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionAddUser, audit.UserTarget(req.UserName), audit.ResultOf(err))
+	if err != nil {
 		slog.ErrorContext(c.Request().Context(), "error registering user", slog.Any("error", err))
 		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
 			Success:   false,
@@ -755,8 +1168,8 @@ func (h *Handler) handleApiUsersAdd(c *echo.Context) error {
 }
 
 func setupApiUsersRoutes(h *Handler, group *echo.Group) {
-	group.POST("/change-password", h.handleApiUsersChangePassword, scope(auth.ScopeAdmin))
-	group.POST("/add", h.handleApiUsersAdd, scope(auth.ScopeAdmin))
+	group.POST("/change-password", h.handleApiUsersChangePassword, scope(auth.NoScope))
+	group.POST("/add", h.handleApiUsersAdd, scope(auth.ScopeUsersAdmin))
 }
 
 func (h *Handler) accessTokenMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
@@ -798,21 +1211,58 @@ func scope(scopes ...auth.Scope) func(echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
+func setupApiServerRoutes(h *Handler, group *echo.Group) {
+	group.GET("/streaming", h.handleStream, scope(auth.ScopeServerRead))
+	group.POST("/launch", h.handleApiLaunch, scope(auth.ScopeServerControl))
+	group.POST("/stop", h.handleApiStop, scope(auth.ScopeServerControl))
+	group.POST("/stop/cancel", h.handleApiCancelStop, scope(auth.ScopeServerControl))
+	group.GET("/systeminfo", h.handleApiSystemInfo, scope(auth.ScopeServerRead))
+	group.GET("/worldinfo", h.handleApiWorldInfo, scope(auth.ScopeServerRead))
+	group.GET("/crashreport", h.handleApiCrashReport, scope(auth.ScopeServerConsole))
+	group.GET("/players", h.handleApiPlayers, scope(auth.ScopeServerRead))
+	group.GET("/config", h.handleApiGetConfig, scope(auth.ScopeServerRead))
+	group.PUT("/config", h.handleApiUpdateConfig, scope(auth.ScopeServerControl))
+	group.PUT("/running-config", h.handleApiUpdateRunningConfig, scope(auth.ScopeServerControl))
+	setupApiQuickUndoRoutes(h, group.Group("/quickundo"))
+	setupApiConsoleRoutes(h, group.Group("/console"))
+	setupApiLogsRoutes(h, group.Group("/logs"))
+}
+
 func (h *Handler) setupApiRoutes(group *echo.Group) {
 	needsAuth := group.Group("")
 	needsAuth.Use(h.accessTokenMiddleware)
-	needsAuth.GET("/streaming", h.handleStream, scope(auth.ScopeAdmin))
-	needsAuth.POST("/launch", h.handleApiLaunch, scope(auth.ScopeAdmin))
-	needsAuth.POST("/stop", h.handleApiStop, scope(auth.ScopeAdmin))
-	needsAuth.GET("/worlds", h.handleApiListWorlds, scope(auth.ScopeAdmin))
-	needsAuth.DELETE("/worlds", h.handleApiDeleteWorld, scope(auth.ScopeAdmin))
-	needsAuth.GET("/mcversions", h.handleApiMcversions, scope(auth.ScopeAdmin))
-	needsAuth.GET("/systeminfo", h.handleApiSystemInfo, scope(auth.ScopeAdmin))
-	needsAuth.GET("/worldinfo", h.handleApiWorldInfo, scope(auth.ScopeAdmin))
-	needsAuth.GET("/config", h.handleApiGetConfig, scope(auth.ScopeAdmin))
-	needsAuth.PUT("/config", h.handleApiUpdateConfig, scope(auth.ScopeAdmin))
-	needsAuth.POST("/world-link/download", h.handleApiCreateWorldDownloadLink, scope(auth.ScopeAdmin))
-	needsAuth.POST("/world-link/upload", h.handleApiCreateWorldUploadLink, scope(auth.ScopeAdmin))
-	setupApiQuickUndoRoutes(h, needsAuth.Group("/quickundo"))
+	needsAuth.GET("/worlds", h.handleApiListWorlds, scope(auth.ScopeWorldRead))
+	needsAuth.DELETE("/worlds", h.handleApiDeleteWorld, scope(auth.ScopeWorldWrite))
+	needsAuth.PUT("/worlds/pin", h.handleApiPinWorld, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/worlds/rename", h.handleApiRenameWorld, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/worlds/duplicate", h.handleApiDuplicateWorld, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/worlds/fork", h.handleApiForkWorld, scope(auth.ScopeWorldWrite))
+	needsAuth.GET("/worlds/retention", h.handleApiGetRetentionPolicy, scope(auth.ScopeWorldRead))
+	needsAuth.PUT("/worlds/retention", h.handleApiUpdateRetentionPolicy, scope(auth.ScopeWorldWrite))
+	needsAuth.GET("/worlds/prune-preview", h.handleApiPrunePreview, scope(auth.ScopeWorldRead))
+	needsAuth.GET("/worlds/datapacks", h.handleApiGetWorldDatapacks, scope(auth.ScopeWorldRead))
+	needsAuth.PUT("/worlds/datapacks", h.handleApiUpdateWorldDatapacks, scope(auth.ScopeWorldWrite))
+	needsAuth.GET("/datapacks", h.handleApiListDatapacks, scope(auth.ScopeWorldRead))
+	needsAuth.DELETE("/datapacks", h.handleApiDeleteDatapack, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/datapacks/upload", h.handleApiCreateDatapackUploadLink, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/datapacks/upload/complete", h.handleApiCompleteDatapackUpload, scope(auth.ScopeWorldWrite))
+	needsAuth.GET("/modsets", h.handleApiListModSets, scope(auth.ScopeWorldRead))
+	needsAuth.DELETE("/modsets", h.handleApiDeleteModSet, scope(auth.ScopeWorldWrite))
+	needsAuth.DELETE("/modsets/files", h.handleApiDeleteModSetFile, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/modsets/upload", h.handleApiCreateModSetUploadLink, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/modsets/upload/complete", h.handleApiCompleteModSetUpload, scope(auth.ScopeWorldWrite))
+	needsAuth.GET("/mcversions", h.handleApiMcversions, scope(auth.ScopeServerRead))
+	needsAuth.POST("/world-link/download", h.handleApiCreateWorldDownloadLink, scope(auth.ScopeWorldRead))
+	needsAuth.POST("/world-link/upload", h.handleApiCreateWorldUploadLink, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/world-link/upload/complete", h.handleApiCompleteWorldUploadLink, scope(auth.ScopeWorldWrite))
+	needsAuth.POST("/world-link/upload/status", h.handleApiGetWorldUploadStatus, scope(auth.ScopeWorldWrite))
 	setupApiUsersRoutes(h, needsAuth.Group("/users"))
+	setupApiTokensRoutes(h, needsAuth.Group("/tokens"))
+	setupApiAuditRoutes(h, needsAuth.Group("/audit"))
+	setupApiServersRoutes(h, needsAuth.Group("/servers"))
+	setupApiSchedulesRoutes(h, needsAuth.Group("/schedules"))
+	setupApiWebhooksRoutes(h, needsAuth.Group("/webhooks"))
+
+	// Routes without server ID operate on the default server for compatibility.
+	setupApiServerRoutes(h, needsAuth.Group("", h.defaultServerMiddleware))
 }
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/labstack/echo/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Api", func() {
+	DescribeTable("isValidPlayerName", func(name string, valid bool) {
+		Expect(isValidPlayerName(name)).To(Equal(valid))
+	},
+		Entry("alphanumeric", "Steve_01", true),
+		Entry("16 chars", "abcdefghijklmnop", true),
+		Entry("17 chars", "abcdefghijklmnopq", false),
+		Entry("empty", "", false),
+		Entry("with space", "foo bar", false),
+		Entry("with command separator", "foo;op", false),
+	)
+
+	DescribeTable("isValidJVMArg", func(arg string, valid bool) {
+		Expect(isValidJVMArg(arg)).To(Equal(valid))
+	},
+		Entry("XX option", "-XX:+UseStringDeduplication", true),
+		Entry("XX option with value", "-XX:MaxGCPauseMillis=200", true),
+		Entry("system property", "-Dlog4j2.formatMsgNoLookups=true", true),
+		Entry("system property without name", "-D=foo", false),
+		Entry("empty", "", false),
+		Entry("not an option", "nogui", false),
+		Entry("argument file", "@args.txt", false),
+		Entry("heap size", "-Xmx4G", false),
+		Entry("initial heap size", "-Xms4G", false),
+		Entry("jar", "-jar", false),
+		Entry("class path", "-cp", false),
+		Entry("with newline", "-Dfoo=bar\nbaz", false),
+		Entry("unknown XX option", "-XX:+UseFooGC", false),
+		Entry("on error hook", "-XX:OnError=sh -c id", false),
+		Entry("on out of memory hook", "-XX:OnOutOfMemoryError=sh -c id", false),
+		Entry("flags file", "-XX:Flags=/tmp/flags", false),
+		Entry("java agent", "-javaagent:/tmp/agent.jar", false),
+		Entry("agent path", "-agentpath:/tmp/agent.so", false),
+		Entry("agent lib", "-agentlib:jdwp=transport=dt_socket", false),
+		Entry("other X option", "-Xss1M", false),
+	)
+
+	DescribeTable("machineHeapSize", func(machineType string, size int) {
+		Expect(machineHeapSize(machineType)).To(Equal(size))
+	},
+		Entry("2g", "2g", 1024),
+		Entry("12g", "12g", 11*1024),
+	)
+
+	DescribeTable("handleApiDeleteWorld rejects keys which are not of generations", func(key string) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/worlds", strings.NewReader(`{"id":"`+key+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		c := e.NewContext(req, rec)
+		c.Set("access_token", &auth.Token{UserID: 1})
+
+		Expect((&Handler{}).handleApiDeleteWorld(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	},
+		Entry("blob", "@blobs/ab/abcdef"),
+		Entry("datapack", "@datapacks/foo.zip"),
+		Entry("mod set", "@modsets/foo/bar.jar"),
+		Entry("empty", ""),
+	)
+})
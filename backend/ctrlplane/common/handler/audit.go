@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/labstack/echo/v5"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+// parsePagination parses 1-origin page number and page size from query parameters.
+func parsePagination(pageStr, perPageStr string) (int, int, bool) {
+	page := 1
+	perPage := defaultPerPage
+
+	if pageStr != "" {
+		var err error
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return 0, 0, false
+		}
+	}
+	if perPageStr != "" {
+		var err error
+		perPage, err = strconv.Atoi(perPageStr)
+		if err != nil || perPage < 1 || maxPerPage < perPage {
+			return 0, 0, false
+		}
+	}
+
+	return page, perPage, true
+}
+
+func (h *Handler) handleApiAuditList(c *echo.Context) error {
+	page, perPage, ok := parsePagination(c.QueryParam("page"), c.QueryParam("perPage"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	entries, total, err := h.auditService.List(c.Request().Context(), perPage, (page-1)*perPage)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to retrieve audit log", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	result := make([]web.AuditLogEntry, 0, len(entries))
+	for _, e := range entries {
+		actor := ""
+		if e.Actor != nil {
+			actor = e.Actor.Name
+		}
+		result = append(result, web.AuditLogEntry{
+			ID:        e.ID,
+			CreatedAt: e.CreatedAt,
+			Actor:     actor,
+			Action:    e.Action,
+			Target:    e.Target,
+			Result:    e.Result,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.AuditLogPage]{
+		Success: true,
+		Data: web.AuditLogPage{
+			Entries: result,
+			Total:   total,
+			Page:    page,
+			PerPage: perPage,
+		},
+	})
+}
+
+func setupApiAuditRoutes(h *Handler, group *echo.Group) {
+	group.GET("", h.handleApiAuditList, scope(auth.ScopeAdmin))
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+	DescribeTable("parsePagination", func(pageStr, perPageStr string, expectedPage, expectedPerPage int, expectedOk bool) {
+		page, perPage, ok := parsePagination(pageStr, perPageStr)
+		Expect(ok).To(Equal(expectedOk))
+		if expectedOk {
+			Expect(page).To(Equal(expectedPage))
+			Expect(perPage).To(Equal(expectedPerPage))
+		}
+	},
+		Entry("defaults", "", "", 1, defaultPerPage, true),
+		Entry("explicit", "3", "20", 3, 20, true),
+		Entry("zero page", "0", "", 0, 0, false),
+		Entry("non-numeric page", "a", "", 0, 0, false),
+		Entry("too large page size", "", "201", 0, 0, false),
+		Entry("zero page size", "", "0", 0, 0, false),
+	)
+})
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	potel "github.com/kofuk/premises/backend/common/otel"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/labstack/echo/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	consoleTimeout      = 15 * time.Second
+	consolePollInterval = 200 * time.Millisecond
+	maxConsoleCommand   = 255
+)
+
+// normalizeConsoleCommandName returns the name of the command without leading slash and namespace, in lower case.
+func normalizeConsoleCommandName(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "/"))
+	if _, unqualified, ok := strings.Cut(name, ":"); ok {
+		// e.g. minecraft:stop
+		name = unqualified
+	}
+	return name
+}
+
+// consoleCommandNames returns the names of the commands which the console command runs,
+// i.e. the command itself and ones run by `execute ... run`.
+func consoleCommandNames(command string) []string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	names := []string{normalizeConsoleCommandName(fields[0])}
+	if names[0] == "execute" {
+		// Every word after `run` may be a command, because `run` can also be an argument like a player name.
+		// This also covers nested `execute`.
+		for i := 1; i+1 < len(fields); i++ {
+			if strings.ToLower(fields[i]) == "run" {
+				names = append(names, normalizeConsoleCommandName(fields[i+1]))
+			}
+		}
+	}
+	return names
+}
+
+// isConsoleCommandAllowed reports whether every command which the console command runs is allowed.
+// allow and deny must be in lower case, which LoadConfig takes care of.
+func isConsoleCommandAllowed(command string, allow, deny []string) bool {
+	names := consoleCommandNames(command)
+	if len(names) == 0 {
+		return false
+	}
+	for _, name := range names {
+		if name == "" {
+			return false
+		}
+		if len(allow) != 0 && !slices.Contains(allow, name) {
+			return false
+		}
+		if slices.Contains(deny, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Handler) waitConsoleResult(ctx context.Context, id string) (*runner.ConsoleExtra, error) {
+	ctx, cancel := context.WithTimeout(ctx, consoleTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(consolePollInterval)
+	defer ticker.Stop()
+
+	key := fmt.Sprintf("console-result:%s", id)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		var result runner.ConsoleExtra
+		if err := h.KVS.Get(ctx, key, &result); err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			return nil, err
+		}
+
+		if err := h.KVS.Del(ctx, key); err != nil {
+			slog.ErrorContext(ctx, "Failed to remove console result", slog.Any("error", err))
+		}
+
+		return &result, nil
+	}
+}
+
+func (h *Handler) handleApiConsole(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.ConsoleReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if len(req.Command) > maxConsoleCommand || strings.ContainsAny(req.Command, "\r\n") {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if !isConsoleCommandAllowed(req.Command, h.cfg.ConsoleAllowCommands, h.cfg.ConsoleDenyCommands) {
+		return c.JSON(http.StatusForbidden, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrCredential,
+		})
+	}
+
+	id := uuid.NewString()
+
+	if err := h.runnerActionService.Push(c.Request().Context(), c.Get("server-id").(string), runner.Action{
+		Type: runner.ActionConsole,
+		Metadata: runner.RequestMeta{
+			Traceparent: potel.TraceContextFromContext(c.Request().Context()),
+		},
+		Actor: int(userID),
+		Console: &runner.ConsoleRequest{
+			ID:      id,
+			Command: req.Command,
+		},
+	}); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to write action", slog.Any("error", err))
+		h.auditService.Record(c.Request().Context(), userID, audit.ActionConsole, req.Command, audit.ResultFailure)
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrRemote,
+		})
+	}
+
+	result, err := h.waitConsoleResult(c.Request().Context(), id)
+	if err == nil && result.Error != "" {
+		err = errors.New(result.Error)
+	}
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionConsole, req.Command, audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to execute console command", slog.Any("error", err))
+		return c.JSON(http.StatusBadGateway, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrRemote,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.ConsoleResult]{
+		Success: true,
+		Data: web.ConsoleResult{
+			Response: result.Response,
+		},
+	})
+}
+
+func setupApiConsoleRoutes(h *Handler, group *echo.Group) {
+	group.POST("", h.handleApiConsole, scope(auth.ScopeServerConsole))
+}
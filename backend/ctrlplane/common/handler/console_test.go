@@ -0,0 +1,40 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Console", func() {
+	DescribeTable("isConsoleCommandAllowed", func(command string, allow, deny []string, allowed bool) {
+		Expect(isConsoleCommandAllowed(command, allow, deny)).To(Equal(allowed))
+	},
+		Entry("no restriction", "tp foo bar", nil, nil, true),
+		Entry("leading slash", "/gamerule keepInventory true", []string{"gamerule"}, nil, true),
+		Entry("not in allowlist", "op foo", []string{"tp", "gamerule"}, nil, false),
+		Entry("in denylist", "stop", nil, []string{"stop"}, false),
+		Entry("denylist is case insensitive", "STOP", nil, []string{"stop"}, false),
+		Entry("denylist wins", "stop", []string{"stop"}, []string{"stop"}, false),
+		Entry("empty command", "  ", nil, nil, false),
+		Entry("namespaced command", "minecraft:stop", nil, []string{"stop"}, false),
+		Entry("namespaced command with slash", "/minecraft:op x", nil, []string{"op"}, false),
+		Entry("namespaced command in allowlist", "minecraft:tp foo bar", []string{"tp"}, nil, true),
+		Entry("execute running denied command", "execute as @a run stop", nil, []string{"stop"}, false),
+		Entry("execute running namespaced command", "execute at @p run minecraft:stop", nil, []string{"stop"}, false),
+		Entry("nested execute", "execute as @a run execute at @s run stop", nil, []string{"stop"}, false),
+		Entry("execute with player named run", "execute as run run stop", nil, []string{"stop"}, false),
+		Entry("execute running allowed command", "execute as @a run tp @s 0 64 0", []string{"execute", "tp"}, nil, true),
+		Entry("execute running command not in allowlist", "execute as @a run op foo", []string{"execute", "tp"}, nil, false),
+		Entry("execute without run", "execute if block 0 64 0 stone", nil, []string{"stop"}, true),
+	)
+
+	DescribeTable("normalizeConsoleCommandName", func(name, expected string) {
+		Expect(normalizeConsoleCommandName(name)).To(Equal(expected))
+	},
+		Entry("plain", "stop", "stop"),
+		Entry("upper case", "STOP", "stop"),
+		Entry("leading slash", "/stop", "stop"),
+		Entry("namespaced", "Minecraft:Stop", "stop"),
+		Entry("surrounding spaces", " save-off ", "save-off"),
+	)
+})
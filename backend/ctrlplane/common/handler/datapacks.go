@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/labstack/echo/v5"
+)
+
+func (h *Handler) handleApiListDatapacks(c *echo.Context) error {
+	datapacks, err := h.worldService.ListDatapacks(c.Request().Context())
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list datapacks", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.Datapack]{
+		Success: true,
+		Data:    datapacks,
+	})
+}
+
+func (h *Handler) handleApiCreateDatapackUploadLink(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CreateDatapackUploadLinkReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if !world.IsValidDatapackName(req.Name) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	key := world.DatapackUploadKey(req.Name)
+	url, err := h.worldService.GetPresignedPutURLWithLifetime(c.Request().Context(), key, time.Minute)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateUploadLink, audit.DatapackTarget(req.Name), audit.ResultOf(err))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.DatapackUploadLink]{
+		Success: true,
+		Data: web.DatapackUploadLink{
+			URL: url,
+			ID:  key,
+		},
+	})
+}
+
+func (h *Handler) handleApiCompleteDatapackUpload(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CompleteDatapackUploadLinkReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	datapack, err := h.worldService.CompleteDatapackUpload(c.Request().Context(), req.ID)
+	target := strings.TrimSuffix(strings.TrimPrefix(req.ID, world.UploadPrefix+world.DatapackPrefix), ".zip")
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUploadDatapack, audit.DatapackTarget(target), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrDatapackNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		if errors.Is(err, world.ErrInvalidDatapack) {
+			slog.InfoContext(c.Request().Context(), "Rejected uploaded datapack", slog.Any("error", err))
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrInvalidDatapack,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Unable to complete datapack upload", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.Datapack]{
+		Success: true,
+		Data:    *datapack,
+	})
+}
+
+func (h *Handler) handleApiDeleteDatapack(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.DeleteDatapackReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.DeleteDatapack(c.Request().Context(), req.Name)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteDatapack, audit.DatapackTarget(req.Name), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrDatapackNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to delete datapack", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleApiGetWorldDatapacks(c *echo.Context) error {
+	worldName := c.QueryParam("worldName")
+	if worldName == "" {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	datapacks, err := h.worldService.GetWorldDatapacks(c.Request().Context(), worldName)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to get datapacks of world", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldDatapacks]{
+		Success: true,
+		Data: web.WorldDatapacks{
+			WorldName: worldName,
+			Datapacks: datapacks,
+		},
+	})
+}
+
+func (h *Handler) handleApiUpdateWorldDatapacks(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.WorldDatapacks
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.SetWorldDatapacks(c.Request().Context(), req.WorldName, req.Datapacks)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUpdateDatapacks, audit.WorldTarget(req.WorldName), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrInvalidWorldName) || errors.Is(err, world.ErrDatapackNotFound) {
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrBadRequest,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to update datapacks of world", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	datapacks, err := h.worldService.GetWorldDatapacks(c.Request().Context(), req.WorldName)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to get datapacks of world", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldDatapacks]{
+		Success: true,
+		Data: web.WorldDatapacks{
+			WorldName: req.WorldName,
+			Datapacks: datapacks,
+		},
+	})
+}
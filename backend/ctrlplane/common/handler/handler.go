@@ -11,13 +11,17 @@ import (
 
 	"github.com/kofuk/premises/backend/common/entity"
 	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
 	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
 	"github.com/kofuk/premises/backend/ctrlplane/common/config"
 	"github.com/kofuk/premises/backend/ctrlplane/common/kvs"
 	"github.com/kofuk/premises/backend/ctrlplane/common/launcher"
 	"github.com/kofuk/premises/backend/ctrlplane/common/longpoll"
 	"github.com/kofuk/premises/backend/ctrlplane/common/mcversions"
+	"github.com/kofuk/premises/backend/ctrlplane/common/schedule"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
 	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	"github.com/kofuk/premises/backend/ctrlplane/common/webhook"
 	"github.com/kofuk/premises/backend/ctrlplane/common/world"
 	echootel "github.com/labstack/echo-opentelemetry"
 	"github.com/labstack/echo/v5"
@@ -42,6 +46,10 @@ type Handler struct {
 	runnerActionService *longpoll.LongPollService
 	authService         *auth.AuthService
 	launcherService     *launcher.LauncherService
+	auditService        *audit.AuditService
+	serverService       *servers.ServerService
+	scheduleService     *schedule.ScheduleService
+	webhookService      *webhook.WebhookService
 }
 
 func setupRoutes(h *Handler) {
@@ -76,7 +84,7 @@ func setupRoutes(h *Handler) {
 	h.setupRunnerRoutes(h.engine.Group("/_"))
 }
 
-func NewHandler(cfg *config.Config, bindAddr string, db *bun.DB, redis *redis.Client, worldService *world.WorldService, longpoll *longpoll.LongPollService, kvs kvs.KeyValueStore, launcher *launcher.LauncherService) (*Handler, error) {
+func NewHandler(cfg *config.Config, bindAddr string, db *bun.DB, redis *redis.Client, worldService *world.WorldService, longpoll *longpoll.LongPollService, kvs kvs.KeyValueStore, launcher *launcher.LauncherService, streaming *streaming.StreamingService, webhookService *webhook.WebhookService) (*Handler, error) {
 	engine := echo.New()
 	engine.Use(echootel.NewMiddlewareWithConfig(echootel.Config{
 		ServerName: "web",
@@ -121,11 +129,15 @@ func NewHandler(cfg *config.Config, bindAddr string, db *bun.DB, redis *redis.Cl
 		redis:               redis,
 		bind:                bindAddr,
 		KVS:                 kvs,
-		StreamingService:    streaming.NewStreamingService(redis),
+		StreamingService:    streaming,
 		worldService:        worldService,
 		runnerActionService: longpoll,
-		authService:         auth.New(kvs),
+		authService:         auth.New(kvs, db),
 		launcherService:     launcher,
+		auditService:        audit.New(db),
+		serverService:       servers.New(db, cfg.GameDomain),
+		scheduleService:     schedule.New(db),
+		webhookService:      webhookService,
 	}
 
 	h.MCVersionsService = mcversions.New(h.KVS)
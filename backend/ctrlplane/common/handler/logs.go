@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	"github.com/labstack/echo/v5"
+)
+
+var logLevelSeverity = map[string]int{
+	"TRACE": 0,
+	"DEBUG": 1,
+	"INFO":  2,
+	"WARN":  3,
+	"ERROR": 4,
+	"FATAL": 5,
+}
+
+// parseLogLevel returns severity of the level. ok is false if the level is unknown.
+func parseLogLevel(level string) (int, bool) {
+	if level == "" {
+		return logLevelSeverity["INFO"], true
+	}
+	severity, ok := logLevelSeverity[strings.ToUpper(level)]
+	return severity, ok
+}
+
+func filterServerLogs(lines []web.ServerLogLine, minSeverity int) []web.ServerLogLine {
+	result := make([]web.ServerLogLine, 0, len(lines))
+	for _, line := range lines {
+		severity, ok := logLevelSeverity[line.Level]
+		if !ok {
+			// Treat lines in unknown format as INFO.
+			severity = logLevelSeverity["INFO"]
+		}
+		if severity >= minSeverity {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func (h *Handler) handleApiLogs(c *echo.Context) error {
+	jsonMode := c.Request().Header.Get("Accept") == "application/json"
+	serverID := c.Get("server-id").(string)
+
+	minSeverity, ok := parseLogLevel(c.QueryParam("level"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	// Subscribe before reading history not to miss lines arriving in between.
+	subscription, err := h.StreamingService.SubscribeEvent(c.Request().Context(), serverID)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to connect to stream", slog.Any("error", err))
+		return c.String(http.StatusInternalServerError, "")
+	}
+	defer subscription.Close()
+
+	history, err := h.StreamingService.GetServerLogs(c.Request().Context(), serverID)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to retrieve server logs", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+	history = filterServerLogs(history, minSeverity)
+
+	if jsonMode {
+		// If it is a JSON mode, we only send the buffered lines and exit.
+		return c.JSON(http.StatusOK, web.SuccessfulResponse[web.ServerLogBatch]{
+			Success: true,
+			Data: web.ServerLogBatch{
+				Lines: history,
+			},
+		})
+	}
+
+	c.Response().Header().Set("Content-Type", "text/event-stream")
+	c.Response().Header().Set("X-Accel-Buffering", "no")
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	writeLines := func(lines []web.ServerLogLine) {
+		if len(lines) == 0 {
+			return
+		}
+
+		message, _ := json.Marshal(web.ServerLogBatch{
+			Lines: lines,
+		})
+
+		writer := bufio.NewWriter(c.Response())
+
+		writer.WriteString("event: " + streaming.LogsMessage.String() + "\n")
+		writer.WriteString("data: ")
+		writer.Write(message)
+		writer.WriteString("\n\n")
+		writer.Flush()
+
+		if flusher, ok := c.Response().(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	writeLines(history)
+
+	eventChannel := subscription.Channel()
+
+	for {
+		select {
+		case msg, ok := <-eventChannel:
+			if !ok {
+				return nil
+			}
+			if msg.Type != streaming.LogsMessage {
+				continue
+			}
+
+			// Body is decoded as a generic value, so convert it back.
+			data, _ := json.Marshal(msg.Body)
+			var batch web.ServerLogBatch
+			if err := json.Unmarshal(data, &batch); err != nil {
+				slog.ErrorContext(c.Request().Context(), "Invalid server log message", slog.Any("error", err))
+				continue
+			}
+
+			writeLines(filterServerLogs(batch.Lines, minSeverity))
+
+		case <-c.Request().Context().Done():
+			return nil
+		}
+	}
+}
+
+func setupApiLogsRoutes(h *Handler, group *echo.Group) {
+	group.GET("", h.handleApiLogs, scope(auth.ScopeServerConsole))
+}
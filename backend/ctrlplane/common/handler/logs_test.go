@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/kofuk/premises/backend/common/entity/web"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logs", func() {
+	DescribeTable("parseLogLevel", func(level string, severity int, ok bool) {
+		s, o := parseLogLevel(level)
+		Expect(o).To(Equal(ok))
+		if ok {
+			Expect(s).To(Equal(severity))
+		}
+	},
+		Entry("default", "", logLevelSeverity["INFO"], true),
+		Entry("lower case", "warn", logLevelSeverity["WARN"], true),
+		Entry("unknown", "verbose", 0, false),
+	)
+
+	It("should filter lines by level", func() {
+		lines := []web.ServerLogLine{
+			{Level: "DEBUG", Message: "a"},
+			{Level: "INFO", Message: "b"},
+			{Level: "WARN", Message: "c"},
+			{Level: "", Message: "d"},
+			{Level: "ERROR", Message: "e"},
+		}
+
+		Expect(filterServerLogs(lines, logLevelSeverity["INFO"])).To(Equal([]web.ServerLogLine{
+			{Level: "INFO", Message: "b"},
+			{Level: "WARN", Message: "c"},
+			{Level: "", Message: "d"},
+			{Level: "ERROR", Message: "e"},
+		}))
+		Expect(filterServerLogs(lines, logLevelSeverity["WARN"])).To(Equal([]web.ServerLogLine{
+			{Level: "WARN", Message: "c"},
+			{Level: "ERROR", Message: "e"},
+		}))
+	})
+})
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/labstack/echo/v5"
+)
+
+func (h *Handler) handleApiListModSets(c *echo.Context) error {
+	modSets, err := h.worldService.ListModSets(c.Request().Context())
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list mod sets", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.ModSet]{
+		Success: true,
+		Data:    modSets,
+	})
+}
+
+func (h *Handler) handleApiCreateModSetUploadLink(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CreateModSetUploadLinkReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if !world.IsValidModSetName(req.ModSet) || !world.IsValidModSetFileName(req.Name) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	key := world.ModSetUploadKey(req.ModSet, req.Name)
+	url, err := h.worldService.GetPresignedPutURLWithLifetime(c.Request().Context(), key, time.Minute)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateUploadLink, audit.ModSetFileTarget(req.ModSet, req.Name), audit.ResultOf(err))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.ModSetUploadLink]{
+		Success: true,
+		Data: web.ModSetUploadLink{
+			URL: url,
+			ID:  key,
+		},
+	})
+}
+
+func (h *Handler) handleApiCompleteModSetUpload(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CompleteModSetUploadLinkReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	file, err := h.worldService.CompleteModSetUpload(c.Request().Context(), req.ID)
+	modSet, name, _ := strings.Cut(strings.TrimPrefix(req.ID, world.UploadPrefix+world.ModSetPrefix), "/")
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUploadModSetFile, audit.ModSetFileTarget(modSet, name), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrModSetNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		if errors.Is(err, world.ErrInvalidModSetFile) {
+			slog.InfoContext(c.Request().Context(), "Rejected uploaded mod set file", slog.Any("error", err))
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrInvalidModSet,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Unable to complete mod set upload", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.ModSetFile]{
+		Success: true,
+		Data:    *file,
+	})
+}
+
+func (h *Handler) handleApiDeleteModSetFile(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.DeleteModSetFileReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.DeleteModSetFile(c.Request().Context(), req.ModSet, req.Name)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteModSetFile, audit.ModSetFileTarget(req.ModSet, req.Name), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrModSetNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to delete mod set file", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleApiDeleteModSet(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.DeleteModSetReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.DeleteModSet(c.Request().Context(), req.Name)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteModSet, audit.ModSetTarget(req.Name), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrModSetNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to delete mod set", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
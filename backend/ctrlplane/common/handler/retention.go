@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/labstack/echo/v5"
+)
+
+func toRetentionPolicyEntity(policy *model.WorldRetentionPolicy, isDefault bool) web.WorldRetentionPolicy {
+	return web.WorldRetentionPolicy{
+		WorldName:  policy.WorldName,
+		KeepLast:   policy.KeepLast,
+		KeepDaily:  policy.KeepDaily,
+		KeepWeekly: policy.KeepWeekly,
+		IsDefault:  isDefault,
+	}
+}
+
+func (h *Handler) handleApiGetRetentionPolicy(c *echo.Context) error {
+	worldName := c.QueryParam("worldName")
+	if worldName == "" {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	policy, isDefault, err := h.worldService.GetRetentionPolicy(c.Request().Context(), worldName)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to get retention policy", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldRetentionPolicy]{
+		Success: true,
+		Data:    toRetentionPolicyEntity(policy, isDefault),
+	})
+}
+
+func (h *Handler) handleApiUpdateRetentionPolicy(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.UpdateWorldRetentionPolicyReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	policy := &model.WorldRetentionPolicy{
+		WorldName:  req.WorldName,
+		KeepLast:   req.KeepLast,
+		KeepDaily:  req.KeepDaily,
+		KeepWeekly: req.KeepWeekly,
+	}
+	if req.WorldName == "" || len(req.WorldName) > 255 || !world.IsValidRetentionPolicy(policy) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.SetRetentionPolicy(c.Request().Context(), policy)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUpdateRetention, audit.WorldTarget(req.WorldName), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to update retention policy", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WorldRetentionPolicy]{
+		Success: true,
+		Data:    toRetentionPolicyEntity(policy, false),
+	})
+}
+
+func (h *Handler) handleApiPinWorld(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.PinWorldReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.SetPinned(c.Request().Context(), req.ID, req.Pinned)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionPinWorld, audit.WorldTarget(req.ID), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, world.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to pin world", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleApiPrunePreview(c *echo.Context) error {
+	pruned, err := h.worldService.PreviewPrune(c.Request().Context())
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to preview prune", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.PrunedWorld]{
+		Success: true,
+		Data:    pruned,
+	})
+}
@@ -28,7 +28,7 @@ func (h *Handler) handleLogin(c *echo.Context) error {
 	}
 
 	user := model.User{}
-	if err := h.db.NewSelect().Model(&user).Column("id", "password", "initialized").Where("name = ? AND deleted_at IS NULL", cred.UserName).Scan(c.Request().Context()); err != nil {
+	if err := h.db.NewSelect().Model(&user).Column("id", "password", "initialized", "scopes").Where("name = ? AND deleted_at IS NULL", cred.UserName).Scan(c.Request().Context()); err != nil {
 		return c.JSON(http.StatusOK, web.ErrorResponse{
 			Success:   false,
 			ErrorCode: entity.ErrCredential,
@@ -62,7 +62,16 @@ func (h *Handler) handleLogin(c *echo.Context) error {
 		})
 	}
 
-	token, err := h.authService.CreateToken(c.Request().Context(), user.ID, []auth.Scope{auth.ScopeAdmin})
+	scopes, err := auth.ParseScopes(user.Scopes)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Invalid scopes assigned to user", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	token, err := h.authService.CreateToken(c.Request().Context(), user.ID, scopes)
 	if err != nil {
 		return c.JSON(http.StatusOK, web.ErrorResponse{
 			Success:   false,
@@ -199,6 +208,7 @@ func (h *Handler) handleSessionData(c *echo.Context) error {
 		if err == nil {
 			sessionData.LoggedIn = true
 			sessionData.AccessToken = token.Token
+			sessionData.Scopes = auth.ScopesToStrings(token.Scopes)
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -14,9 +15,12 @@ import (
 	"github.com/kofuk/premises/backend/common/entity"
 	"github.com/kofuk/premises/backend/common/entity/runner"
 	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/manifest"
 	potel "github.com/kofuk/premises/backend/common/otel"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
 	"github.com/kofuk/premises/backend/ctrlplane/common/longpoll"
 	"github.com/kofuk/premises/backend/ctrlplane/common/monitor"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
 	"github.com/labstack/echo/v5"
 	"go.opentelemetry.io/otel/codes"
 )
@@ -94,7 +98,7 @@ func (h *Handler) handlePostStatus(c *echo.Context) error {
 		ctx := context.Background()
 
 		if event.Type == runner.EventStatus && event.Status.EventCode == entity.EventShutdown {
-			go h.launcherService.Clean(ctx, c.Request().Header.Get("Authorization"))
+			go h.launcherService.Clean(ctx, runnerId, c.Request().Header.Get("Authorization"))
 
 			span.End()
 
@@ -223,9 +227,17 @@ func (h *Handler) handleCreateWorldDownloadURL(c *echo.Context) error {
 		})
 	}
 
+	// Worlds uploaded before checksums were introduced are not in the catalog or don't have the checksum.
+	var checksum string
+	if gen, err := h.worldService.GetGeneration(c.Request().Context(), req.WorldID); err == nil {
+		checksum = gen.Checksum
+	} else if !errors.Is(err, world.ErrNotFound) {
+		slog.ErrorContext(c.Request().Context(), "Unable to get world generation", slog.Any("error", err))
+	}
+
 	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.CreateWorldDownloadURLResponse]{
 		Success: true,
-		Data:    web.CreateWorldDownloadURLResponse{URL: url},
+		Data:    web.CreateWorldDownloadURLResponse{URL: url, Checksum: checksum},
 	})
 }
 
@@ -239,7 +251,7 @@ func (h *Handler) handleCreateWorldUploadURL(c *echo.Context) error {
 		})
 	}
 
-	if strings.ContainsRune(req.WorldName, '/') {
+	if strings.ContainsRune(req.WorldName, '/') || strings.HasPrefix(req.WorldName, "@") {
 		slog.ErrorContext(c.Request().Context(), "Invalid world name", slog.Any("worldName", req.WorldName))
 		return c.JSON(http.StatusOK, web.ErrorResponse{
 			Success:   false,
@@ -247,7 +259,11 @@ func (h *Handler) handleCreateWorldUploadURL(c *echo.Context) error {
 		})
 	}
 
-	key := fmt.Sprintf("%s/%s.tar.zst", req.WorldName, time.Now().Format(time.DateTime))
+	ext := ".tar.zst"
+	if req.Format == runner.BackupFormatIncremental {
+		ext = manifest.Extension
+	}
+	key := fmt.Sprintf("%s/%s%s", req.WorldName, time.Now().Format(time.DateTime), ext)
 
 	url, err := h.worldService.GetPresignedPutURL(c.Request().Context(), key)
 	if err != nil {
@@ -267,6 +283,204 @@ func (h *Handler) handleCreateWorldUploadURL(c *echo.Context) error {
 	})
 }
 
+func (h *Handler) handleCreateCrashReportUploadURL(c *echo.Context) error {
+	serverID := c.Get("runner-id").(string)
+
+	key := world.CrashReportKey(serverID, time.Now())
+	url, err := h.worldService.GetPresignedPutURL(c.Request().Context(), key)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to get presigned URL", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.CreateCrashReportUploadURLResponse]{
+		Success: true,
+		Data:    web.CreateCrashReportUploadURLResponse{URL: url, ReportID: key},
+	})
+}
+
+func (h *Handler) handleCompleteWorldUpload(c *echo.Context) error {
+	var req web.CompleteWorldUploadRequest
+	if err := c.Bind(&req); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to bind request", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	serverID := c.Get("runner-id").(string)
+
+	var mcVersion string
+	if worldInfo, err := monitor.GetWorldInfo(c.Request().Context(), h.cfg, &h.KVS, serverID); err == nil {
+		mcVersion = worldInfo.Version
+	}
+
+	if req.Checksum != "" && !manifest.IsValidHash(req.Checksum) {
+		slog.ErrorContext(c.Request().Context(), "Invalid checksum", slog.String("checksum", req.Checksum))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	info := world.GenerationInfo{
+		Size:       req.Size,
+		MCVersion:  mcVersion,
+		CreatedBy:  audit.ServerTarget(serverID),
+		AutoBackup: req.AutoBackup,
+		Checksum:   req.Checksum,
+		Level:      req.Level,
+	}
+	if err := h.worldService.RecordGeneration(c.Request().Context(), req.WorldID, info); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to record world generation", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleGetWorldDatapacks(c *echo.Context) error {
+	var req web.GetWorldDatapacksRequest
+	if err := c.Bind(&req); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to bind request", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	datapacks, err := h.worldService.GetDatapackDownloads(c.Request().Context(), req.WorldName)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to get datapacks of world", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.GetWorldDatapacksResponse]{
+		Success: true,
+		Data:    web.GetWorldDatapacksResponse{Datapacks: datapacks},
+	})
+}
+
+func (h *Handler) handleGetModSet(c *echo.Context) error {
+	var req web.GetModSetRequest
+	if err := c.Bind(&req); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to bind request", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	files, err := h.worldService.GetModSetDownloads(c.Request().Context(), req.Name)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to get files of mod set", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.GetModSetResponse]{
+		Success: true,
+		Data:    web.GetModSetResponse{Files: files},
+	})
+}
+
+// blobURLLifetime is long enough for runners to transfer all blobs of a world.
+const blobURLLifetime = time.Hour
+
+func bindBlobURLsRequest(c *echo.Context) ([]string, bool) {
+	var req web.CreateBlobURLsRequest
+	if err := c.Bind(&req); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to bind request", slog.Any("error", err))
+		return nil, false
+	}
+	for _, hash := range req.Hashes {
+		if !manifest.IsValidHash(hash) {
+			slog.ErrorContext(c.Request().Context(), "Invalid blob hash", slog.String("hash", hash))
+			return nil, false
+		}
+	}
+	return req.Hashes, true
+}
+
+func (h *Handler) handleCreateBlobUploadURLs(c *echo.Context) error {
+	hashes, ok := bindBlobURLsRequest(c)
+	if !ok {
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	missing, err := h.worldService.MissingBlobs(c.Request().Context(), hashes)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Unable to list blobs", slog.Any("error", err))
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	urls := make(map[string]string)
+	for _, hash := range missing {
+		url, err := h.worldService.GetPresignedPutURLWithLifetime(c.Request().Context(), manifest.BlobKey(hash), blobURLLifetime)
+		if err != nil {
+			slog.ErrorContext(c.Request().Context(), "Unable to get presigned URL", slog.Any("error", err))
+			return c.JSON(http.StatusOK, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrInternal,
+			})
+		}
+		urls[hash] = url
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.CreateBlobURLsResponse]{
+		Success: true,
+		Data:    web.CreateBlobURLsResponse{URLs: urls},
+	})
+}
+
+func (h *Handler) handleCreateBlobDownloadURLs(c *echo.Context) error {
+	hashes, ok := bindBlobURLsRequest(c)
+	if !ok {
+		return c.JSON(http.StatusOK, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	urls := make(map[string]string)
+	for _, hash := range hashes {
+		url, err := h.worldService.GetPresignedGetURLWithLifetime(c.Request().Context(), manifest.BlobKey(hash), blobURLLifetime)
+		if err != nil {
+			slog.ErrorContext(c.Request().Context(), "Unable to get presigned URL", slog.Any("error", err))
+			return c.JSON(http.StatusOK, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrInternal,
+			})
+		}
+		urls[hash] = url
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.CreateBlobURLsResponse]{
+		Success: true,
+		Data:    web.CreateBlobURLsResponse{URLs: urls},
+	})
+}
+
 func (h *Handler) authKeyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c *echo.Context) error {
 		authKey := c.Request().Header.Get("Authorization")
@@ -290,10 +504,21 @@ func (h *Handler) setupRunnerRoutes(group *echo.Group) {
 	group.GET("/install", h.handleGetInstallScript)
 	group.GET("/startup", h.handleGetStartupScript)
 
+	// URLs of the storage are signed, so they don't need the auth key.
+	if storageHandler := h.worldService.StorageHandler(); storageHandler != nil {
+		group.Any("/storage/*", echo.WrapHandler(http.StripPrefix("/_/storage", storageHandler)))
+	}
+
 	privates := group.Group("", h.authKeyMiddleware)
 	privates.GET("/poll", h.handleRunnerPoll)
 	privates.POST("/status", h.handlePostStatus)
 	privates.GET("/world/latest-id/:worldName", h.handleGetLatestWorldID)
 	privates.POST("/world/download-url", h.handleCreateWorldDownloadURL)
 	privates.POST("/world/upload-url", h.handleCreateWorldUploadURL)
+	privates.POST("/world/upload-complete", h.handleCompleteWorldUpload)
+	privates.POST("/world/blobs/upload-urls", h.handleCreateBlobUploadURLs)
+	privates.POST("/world/blobs/download-urls", h.handleCreateBlobDownloadURLs)
+	privates.POST("/world/datapacks", h.handleGetWorldDatapacks)
+	privates.POST("/modset", h.handleGetModSet)
+	privates.POST("/crashreport/upload-url", h.handleCreateCrashReportUploadURL)
 }
@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/schedule"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
+	"github.com/labstack/echo/v5"
+)
+
+func isValidSchedule(launchAt, stopAt, timezone string) bool {
+	if launchAt == "" && stopAt == "" {
+		return false
+	}
+	if len(launchAt) > 64 || len(stopAt) > 64 || len(timezone) > 64 {
+		return false
+	}
+	for _, spec := range []string{launchAt, stopAt} {
+		if spec == "" {
+			continue
+		}
+		if _, err := schedule.Parse(spec, timezone); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func toScheduleEntity(s *model.Schedule) web.Schedule {
+	result := web.Schedule{
+		ID:        s.ID,
+		ServerID:  s.ServerID,
+		Name:      s.Name,
+		LaunchAt:  s.LaunchAt,
+		StopAt:    s.StopAt,
+		Timezone:  s.Timezone,
+		Enabled:   s.Enabled,
+		CreatedAt: s.CreatedAt,
+	}
+	json.Unmarshal(s.Config, &result.Config)
+	if s.Enabled {
+		now := time.Now()
+		result.NextLaunchAt = schedule.Next(s.LaunchAt, s.Timezone, now)
+		result.NextStopAt = schedule.Next(s.StopAt, s.Timezone, now)
+	}
+	return result
+}
+
+// validateScheduleConfig checks that the server can be launched with the config when the schedule launches the server.
+func (h *Handler) validateScheduleConfig(s *model.Schedule, config *web.PendingConfig) bool {
+	if s.LaunchAt == "" {
+		return true
+	}
+	return h.validateAndNormalizeConfig(config)
+}
+
+func (h *Handler) handleApiSchedulesList(c *echo.Context) error {
+	schedules, err := h.scheduleService.List(c.Request().Context())
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list schedules", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	result := make([]web.Schedule, 0, len(schedules))
+	for _, s := range schedules {
+		result = append(result, toScheduleEntity(&s))
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.Schedule]{
+		Success: true,
+		Data:    result,
+	})
+}
+
+func (h *Handler) handleApiSchedulesCreate(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CreateScheduleReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if req.ServerID == "" {
+		req.ServerID = servers.DefaultServerID
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if len(req.Name) == 0 || len(req.Name) > 64 || !isValidSchedule(req.LaunchAt, req.StopAt, req.Timezone) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if _, err := h.serverService.Get(c.Request().Context(), req.ServerID); err != nil {
+		if errors.Is(err, servers.ErrNotFound) {
+			return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrBadRequest,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to get server", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	var config web.PendingConfig
+	if req.Config != nil {
+		config = *req.Config
+	} else if err := h.KVS.Get(c.Request().Context(), pendingConfigKey(req.ServerID), &config); err != nil && req.LaunchAt != "" {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInvalidConfig,
+		})
+	}
+
+	s := &model.Schedule{
+		ServerID:        req.ServerID,
+		Name:            req.Name,
+		LaunchAt:        req.LaunchAt,
+		StopAt:          req.StopAt,
+		Timezone:        req.Timezone,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		CreatedByUserID: &userID,
+	}
+	if !h.validateScheduleConfig(s, &config) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInvalidConfig,
+		})
+	}
+	s.Config, _ = json.Marshal(config)
+
+	err := h.scheduleService.Create(c.Request().Context(), s)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateSchedule, audit.ScheduleTarget(s.ID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to create schedule", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, web.SuccessfulResponse[web.Schedule]{
+		Success: true,
+		Data:    toScheduleEntity(s),
+	})
+}
+
+func (h *Handler) handleApiSchedulesUpdate(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	var req web.UpdateScheduleReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	s, err := h.scheduleService.Get(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, schedule.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to get schedule", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	if req.Name != nil {
+		s.Name = *req.Name
+	}
+	if req.LaunchAt != nil {
+		s.LaunchAt = *req.LaunchAt
+	}
+	if req.StopAt != nil {
+		s.StopAt = *req.StopAt
+	}
+	if req.Timezone != nil {
+		s.Timezone = *req.Timezone
+	}
+	if req.Enabled != nil {
+		s.Enabled = *req.Enabled
+	}
+	if len(s.Name) == 0 || len(s.Name) > 64 || !isValidSchedule(s.LaunchAt, s.StopAt, s.Timezone) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	var config web.PendingConfig
+	if req.Config != nil {
+		config = *req.Config
+	} else {
+		json.Unmarshal(s.Config, &config)
+	}
+	if !h.validateScheduleConfig(s, &config) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInvalidConfig,
+		})
+	}
+	s.Config, _ = json.Marshal(config)
+
+	err = h.scheduleService.Update(c.Request().Context(), s)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUpdateSchedule, audit.ScheduleTarget(s.ID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to update schedule", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.Schedule]{
+		Success: true,
+		Data:    toScheduleEntity(s),
+	})
+}
+
+func (h *Handler) handleApiSchedulesDelete(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err = h.scheduleService.Delete(c.Request().Context(), uint(id))
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteSchedule, audit.ScheduleTarget(uint(id)), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, schedule.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to delete schedule", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func setupApiSchedulesRoutes(h *Handler, group *echo.Group) {
+	group.GET("", h.handleApiSchedulesList, scope(auth.ScopeServerRead))
+	group.POST("", h.handleApiSchedulesCreate, scope(auth.ScopeServerControl))
+	group.PUT("/:id", h.handleApiSchedulesUpdate, scope(auth.ScopeServerControl))
+	group.DELETE("/:id", h.handleApiSchedulesDelete, scope(auth.ScopeServerControl))
+}
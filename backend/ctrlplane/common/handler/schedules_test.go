@@ -0,0 +1,20 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedules", func() {
+	DescribeTable("isValidSchedule", func(launchAt, stopAt, timezone string, valid bool) {
+		Expect(isValidSchedule(launchAt, stopAt, timezone)).To(Equal(valid))
+	},
+		Entry("launch and stop", "0 19 * * 1-5", "0 23 * * 1-5", "Asia/Tokyo", true),
+		Entry("launch only", "0 19 * * *", "", "UTC", true),
+		Entry("stop only", "", "0 3 * * *", "UTC", true),
+		Entry("neither", "", "", "UTC", false),
+		Entry("invalid launch time", "19:00", "0 23 * * *", "UTC", false),
+		Entry("invalid stop time", "0 19 * * *", "23:00", "UTC", false),
+		Entry("invalid time zone", "0 19 * * *", "", "JST-9", false),
+	)
+})
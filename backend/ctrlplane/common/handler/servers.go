@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
+	"github.com/labstack/echo/v5"
+)
+
+var hostnameRegexp = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+func isValidHostname(hostname string) bool {
+	return len(hostname) <= 253 && hostnameRegexp.MatchString(hostname)
+}
+
+func (h *Handler) toServerEntity(c *echo.Context, server *model.Server) web.Server {
+	return web.Server{
+		ID:       server.ID,
+		Name:     server.Name,
+		Hostname: h.serverService.Hostname(server),
+		Running:  h.launcherService.IsRunning(c.Request().Context(), server.ID),
+	}
+}
+
+func (h *Handler) handleApiServersList(c *echo.Context) error {
+	serverList, err := h.serverService.List(c.Request().Context())
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list servers", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	result := make([]web.Server, 0, len(serverList))
+	for _, server := range serverList {
+		result = append(result, h.toServerEntity(c, &server))
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.Server]{
+		Success: true,
+		Data:    result,
+	})
+}
+
+func (h *Handler) handleApiServersCreate(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CreateServerReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	req.Hostname = strings.ToLower(req.Hostname)
+	if !servers.IsValidID(req.ID) || len(req.Name) == 0 || len(req.Name) > 64 || !isValidHostname(req.Hostname) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+	if req.Hostname == h.cfg.GameDomain {
+		// The hostname is reserved for the default server.
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	server := &model.Server{
+		ID:       req.ID,
+		Name:     req.Name,
+		Hostname: req.Hostname,
+	}
+
+	err := h.serverService.Create(c.Request().Context(), server)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateServer, audit.ServerTarget(req.ID), audit.ResultOf(err))
+	if err != nil {
+		// Most likely the ID or the hostname is already used.
+		slog.ErrorContext(c.Request().Context(), "Failed to create server", slog.Any("error", err))
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, web.SuccessfulResponse[web.Server]{
+		Success: true,
+		Data:    h.toServerEntity(c, server),
+	})
+}
+
+func (h *Handler) handleApiServersDelete(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+	serverID := c.Get("server-id").(string)
+
+	if serverID == servers.DefaultServerID {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+	if h.launcherService.IsRunning(c.Request().Context(), serverID) {
+		return c.JSON(http.StatusConflict, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrServerRunning,
+		})
+	}
+
+	err := h.serverService.Delete(c.Request().Context(), serverID)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteServer, audit.ServerTarget(serverID), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, servers.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to delete server", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	if err := h.KVS.Del(c.Request().Context(), pendingConfigKey(serverID), fmt.Sprintf("players:%s", serverID)); err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to clean up server data", slog.Any("error", err))
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+// serverMiddleware resolves the server specified in the path.
+func (h *Handler) serverMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		serverID := c.Param("serverId")
+		if !servers.IsValidID(serverID) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+
+		if _, err := h.serverService.Get(c.Request().Context(), serverID); err != nil {
+			if errors.Is(err, servers.ErrNotFound) {
+				return c.JSON(http.StatusNotFound, web.ErrorResponse{
+					Success:   false,
+					ErrorCode: entity.ErrNotFound,
+				})
+			}
+			slog.ErrorContext(c.Request().Context(), "Failed to get server", slog.Any("error", err))
+			return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrInternal,
+			})
+		}
+
+		c.Set("server-id", serverID)
+
+		return next(c)
+	}
+}
+
+func (h *Handler) defaultServerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		c.Set("server-id", servers.DefaultServerID)
+
+		return next(c)
+	}
+}
+
+func setupApiServersRoutes(h *Handler, group *echo.Group) {
+	group.GET("", h.handleApiServersList, scope(auth.ScopeServerRead))
+	group.POST("", h.handleApiServersCreate, scope(auth.ScopeAdmin))
+
+	server := group.Group("/:serverId", h.serverMiddleware)
+	server.DELETE("", h.handleApiServersDelete, scope(auth.ScopeAdmin))
+	setupApiServerRoutes(h, server)
+}
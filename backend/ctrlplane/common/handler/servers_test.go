@@ -0,0 +1,21 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Servers", func() {
+	DescribeTable("isValidHostname", func(hostname string, valid bool) {
+		Expect(isValidHostname(hostname)).To(Equal(valid))
+	},
+		Entry("empty", "", false),
+		Entry("single label", "localhost", true),
+		Entry("subdomain", "survival.mc.example.com", true),
+		Entry("with hyphen", "mc-2.example.com", true),
+		Entry("leading hyphen", "-mc.example.com", false),
+		Entry("trailing dot", "mc.example.com.", false),
+		Entry("with port", "mc.example.com:25565", false),
+		Entry("upper case", "MC.example.com", false),
+	)
+})
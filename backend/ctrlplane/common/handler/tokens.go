@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/labstack/echo/v5"
+)
+
+func toPersonalAccessTokenEntity(pat *model.PersonalAccessToken) web.PersonalAccessToken {
+	result := web.PersonalAccessToken{
+		ID:        pat.ID,
+		Name:      pat.Name,
+		Scopes:    pat.Scopes,
+		CreatedAt: pat.CreatedAt,
+	}
+	if !pat.ExpiresAt.IsZero() {
+		result.ExpiresAt = &pat.ExpiresAt.Time
+	}
+	if !pat.LastUsedAt.IsZero() {
+		result.LastUsedAt = &pat.LastUsedAt.Time
+	}
+	return result
+}
+
+func (h *Handler) handleApiTokensList(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	tokens, err := h.authService.ListPersonalAccessTokens(c.Request().Context(), userID)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list personal access tokens", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	result := make([]web.PersonalAccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, toPersonalAccessTokenEntity(&token))
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.PersonalAccessToken]{
+		Success: true,
+		Data:    result,
+	})
+}
+
+func (h *Handler) handleApiTokensCreate(c *echo.Context) error {
+	token := c.Get("access_token").(*auth.Token)
+
+	var req web.CreatePersonalAccessTokenReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if len(req.Name) == 0 || len(req.Name) > 64 {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil || len(scopes) == 0 {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+	for _, s := range scopes {
+		// Tokens can't have scopes which the issuer doesn't have.
+		if !token.HasScope(s) {
+			return c.JSON(http.StatusForbidden, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrCredential,
+			})
+		}
+	}
+
+	pat, secret, err := h.authService.CreatePersonalAccessToken(c.Request().Context(), token.UserID, req.Name, scopes, req.ExpiresAt)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to create personal access token", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, web.SuccessfulResponse[web.CreatedPersonalAccessToken]{
+		Success: true,
+		Data: web.CreatedPersonalAccessToken{
+			PersonalAccessToken: toPersonalAccessTokenEntity(pat),
+			Token:               secret,
+		},
+	})
+}
+
+func (h *Handler) handleApiTokensRevoke(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if err := h.authService.RevokePersonalAccessToken(c.Request().Context(), userID, uint(id)); err != nil {
+		if errors.Is(err, auth.ErrTokenNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to revoke personal access token", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+// rejectPersonalAccessToken rejects requests authenticated with personal access tokens.
+// Otherwise, a token could issue tokens with longer expiry or revoke other tokens.
+func rejectPersonalAccessToken(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		if c.Get("access_token").(*auth.Token).PersonalAccessTokenID != 0 {
+			return c.JSON(http.StatusForbidden, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrCredential,
+			})
+		}
+
+		return next(c)
+	}
+}
+
+func setupApiTokensRoutes(h *Handler, group *echo.Group) {
+	group.Use(rejectPersonalAccessToken)
+	group.GET("", h.handleApiTokensList, scope(auth.NoScope))
+	group.POST("", h.handleApiTokensCreate, scope(auth.NoScope))
+	group.DELETE("/:id", h.handleApiTokensRevoke, scope(auth.NoScope))
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/labstack/echo/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tokens", func() {
+	DescribeTable("rejectPersonalAccessToken", func(token *auth.Token, expectedStatus int) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/v1/tokens", nil), rec)
+		c.Set("access_token", token)
+
+		handler := rejectPersonalAccessToken(func(c *echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		Expect(handler(c)).To(Succeed())
+		Expect(rec.Code).To(Equal(expectedStatus))
+	},
+		Entry("session", &auth.Token{UserID: 1}, http.StatusOK),
+		Entry("personal access token", &auth.Token{UserID: 1, PersonalAccessTokenID: 2}, http.StatusForbidden),
+	)
+})
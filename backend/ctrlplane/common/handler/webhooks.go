@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/webhook"
+	"github.com/labstack/echo/v5"
+)
+
+func isValidWebhookURL(webhookURL string) bool {
+	if len(webhookURL) > 2048 {
+		return false
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isValidWebhook(w *model.Webhook) bool {
+	return len(w.Name) != 0 && len(w.Name) <= 64 && isValidWebhookURL(w.URL) && webhook.IsValidFormat(w.Format)
+}
+
+func toWebhookEntity(w *model.Webhook) web.Webhook {
+	return web.Webhook{
+		ID:         w.ID,
+		Name:       w.Name,
+		URL:        w.URL,
+		Format:     w.Format,
+		EventCodes: w.EventCodes,
+		InfoCodes:  w.InfoCodes,
+		Enabled:    w.Enabled,
+		CreatedAt:  w.CreatedAt,
+	}
+}
+
+func (h *Handler) handleApiWebhooksList(c *echo.Context) error {
+	webhooks, err := h.webhookService.List(c.Request().Context())
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list webhooks", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	result := make([]web.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		result = append(result, toWebhookEntity(&w))
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[[]web.Webhook]{
+		Success: true,
+		Data:    result,
+	})
+}
+
+func (h *Handler) handleApiWebhooksCreate(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CreateWebhookReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	if req.Format == "" {
+		req.Format = webhook.FormatJSON
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to generate webhook secret", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	w := &model.Webhook{
+		Name:            req.Name,
+		URL:             req.URL,
+		Secret:          secret,
+		Format:          req.Format,
+		EventCodes:      req.EventCodes,
+		InfoCodes:       req.InfoCodes,
+		Enabled:         req.Enabled == nil || *req.Enabled,
+		CreatedByUserID: &userID,
+	}
+	if !isValidWebhook(w) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err = h.webhookService.Create(c.Request().Context(), w)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionCreateWebhook, audit.WebhookTarget(w.ID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to create webhook", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, web.SuccessfulResponse[web.CreatedWebhook]{
+		Success: true,
+		Data: web.CreatedWebhook{
+			Webhook: toWebhookEntity(w),
+			Secret:  secret,
+		},
+	})
+}
+
+func (h *Handler) getWebhookFromParam(c *echo.Context) (*model.Webhook, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	w, err := h.webhookService.Get(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			return nil, c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to get webhook", slog.Any("error", err))
+		return nil, c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return w, nil
+}
+
+func (h *Handler) handleApiWebhooksUpdate(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.UpdateWebhookReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	w, err := h.getWebhookFromParam(c)
+	if w == nil {
+		return err
+	}
+
+	if req.Name != nil {
+		w.Name = *req.Name
+	}
+	if req.URL != nil {
+		w.URL = *req.URL
+	}
+	if req.Format != nil {
+		w.Format = *req.Format
+	}
+	if req.EventCodes != nil {
+		w.EventCodes = req.EventCodes
+	}
+	if req.InfoCodes != nil {
+		w.InfoCodes = req.InfoCodes
+	}
+	if req.Enabled != nil {
+		w.Enabled = *req.Enabled
+	}
+	if !isValidWebhook(w) {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err = h.webhookService.Update(c.Request().Context(), w)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionUpdateWebhook, audit.WebhookTarget(w.ID), audit.ResultOf(err))
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to update webhook", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.Webhook]{
+		Success: true,
+		Data:    toWebhookEntity(w),
+	})
+}
+
+func (h *Handler) handleApiWebhooksDelete(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err = h.webhookService.Delete(c.Request().Context(), uint(id))
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDeleteWebhook, audit.WebhookTarget(uint(id)), audit.ResultOf(err))
+	if err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, web.ErrorResponse{
+				Success:   false,
+				ErrorCode: entity.ErrNotFound,
+			})
+		}
+		slog.ErrorContext(c.Request().Context(), "Failed to delete webhook", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	return c.JSON(http.StatusNoContent, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleApiWebhooksDeliveries(c *echo.Context) error {
+	page, perPage, ok := parsePagination(c.QueryParam("page"), c.QueryParam("perPage"))
+	if !ok {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	w, err := h.getWebhookFromParam(c)
+	if w == nil {
+		return err
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request().Context(), w.ID, perPage, (page-1)*perPage)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "Failed to list webhook deliveries", slog.Any("error", err))
+		return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrInternal,
+		})
+	}
+
+	result := make([]web.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		delivery := web.WebhookDelivery{
+			ID:         d.ID,
+			CreatedAt:  d.CreatedAt,
+			Payload:    d.Payload,
+			Attempts:   d.Attempts,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			Succeeded:  d.Succeeded,
+		}
+		if !d.FinishedAt.IsZero() {
+			delivery.FinishedAt = &d.FinishedAt.Time
+		}
+		result = append(result, delivery)
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[web.WebhookDeliveryPage]{
+		Success: true,
+		Data: web.WebhookDeliveryPage{
+			Deliveries: result,
+			Total:      total,
+			Page:       page,
+			PerPage:    perPage,
+		},
+	})
+}
+
+func setupApiWebhooksRoutes(h *Handler, group *echo.Group) {
+	group.GET("", h.handleApiWebhooksList, scope(auth.ScopeAdmin))
+	group.POST("", h.handleApiWebhooksCreate, scope(auth.ScopeAdmin))
+	group.PUT("/:id", h.handleApiWebhooksUpdate, scope(auth.ScopeAdmin))
+	group.DELETE("/:id", h.handleApiWebhooksDelete, scope(auth.ScopeAdmin))
+	group.GET("/:id/deliveries", h.handleApiWebhooksDeliveries, scope(auth.ScopeAdmin))
+}
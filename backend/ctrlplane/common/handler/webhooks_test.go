@@ -0,0 +1,19 @@
+package handler
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks", func() {
+	DescribeTable("isValidWebhookURL", func(webhookURL string, valid bool) {
+		Expect(isValidWebhookURL(webhookURL)).To(Equal(valid))
+	},
+		Entry("https", "https://hooks.slack.com/services/T000/B000/XXXX", true),
+		Entry("http with port", "http://192.0.2.1:8080/hook", true),
+		Entry("empty", "", false),
+		Entry("no scheme", "example.com/hook", false),
+		Entry("unsupported scheme", "ftp://example.com/hook", false),
+		Entry("no host", "https:///hook", false),
+	)
+})
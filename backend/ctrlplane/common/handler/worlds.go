@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/labstack/echo/v5"
+)
+
+// worldCopyError responds to errors of renaming, duplicating or forking worlds.
+func worldCopyError(c *echo.Context, err error) error {
+	switch {
+	case errors.Is(err, world.ErrNotFound):
+		return c.JSON(http.StatusNotFound, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrNotFound,
+		})
+	case errors.Is(err, world.ErrInvalidWorldName):
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	case errors.Is(err, world.ErrWorldExists):
+		return c.JSON(http.StatusConflict, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrWorldExists,
+		})
+	}
+
+	slog.ErrorContext(c.Request().Context(), "Failed to copy world", slog.Any("error", err))
+	return c.JSON(http.StatusInternalServerError, web.ErrorResponse{
+		Success:   false,
+		ErrorCode: entity.ErrInternal,
+	})
+}
+
+func (h *Handler) handleApiRenameWorld(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CopyWorldReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.RenameWorld(c.Request().Context(), req.WorldName, req.NewWorldName)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionRenameWorld, audit.WorldTarget(req.WorldName), audit.ResultOf(err))
+	if err != nil {
+		return worldCopyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleApiDuplicateWorld(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.CopyWorldReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.DuplicateWorld(c.Request().Context(), req.WorldName, req.NewWorldName)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionDuplicateWorld, audit.WorldTarget(req.WorldName), audit.ResultOf(err))
+	if err != nil {
+		return worldCopyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
+
+func (h *Handler) handleApiForkWorld(c *echo.Context) error {
+	userID := c.Get("access_token").(*auth.Token).UserID
+
+	var req web.ForkWorldReq
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, web.ErrorResponse{
+			Success:   false,
+			ErrorCode: entity.ErrBadRequest,
+		})
+	}
+
+	err := h.worldService.ForkWorld(c.Request().Context(), req.ID, req.NewWorldName)
+	h.auditService.Record(c.Request().Context(), userID, audit.ActionForkWorld, audit.WorldTarget(req.ID), audit.ResultOf(err))
+	if err != nil {
+		return worldCopyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, web.SuccessfulResponse[any]{
+		Success: true,
+	})
+}
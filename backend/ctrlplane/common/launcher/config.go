@@ -11,12 +11,17 @@ import (
 type LaunchServerConfig struct {
 	PreferDetected     bool
 	Version            string
+	Flavor             string
+	ModSet             string
 	DownloadUrl        string
 	ManifestOverride   string
 	CustomCommand      []string
 	ServerPropOverride map[string]string
 	JavaVersion        int
 	InactiveTimeout    int
+	MaxCrashLoops      int
+	JVMProfile         string
+	JVMArgs            []string
 	// TODO: Move this to world config
 	Motd      string
 	Operators []string
@@ -30,6 +35,9 @@ type LaunchWorldConfig struct {
 	Seed           string
 	LevelType      string
 	Difficulty     string
+	BackupFormat   string
+	// Minutes between backups during the session. Zero disables them.
+	AutoBackupInterval int
 }
 
 type ObservabilityConfig struct {
@@ -60,12 +68,17 @@ func (c *LaunchConfig) ToRunnerConfig(cfg *config.Config) (*runner.Config, error
 	// server config
 	result.GameConfig.Server.PreferDetected = c.Server.PreferDetected
 	result.GameConfig.Server.Version = c.Server.Version
+	result.GameConfig.Server.Flavor = c.Server.Flavor
+	result.GameConfig.Server.ModSet = c.Server.ModSet
 	result.GameConfig.Server.DownloadUrl = c.Server.DownloadUrl
 	result.GameConfig.Server.ManifestOverride = c.Server.ManifestOverride
 	result.GameConfig.Server.CustomCommand = c.Server.CustomCommand
 	result.GameConfig.Server.ServerPropOverride = c.Server.ServerPropOverride
 	result.GameConfig.Server.JavaVersion = c.Server.JavaVersion
 	result.GameConfig.Server.InactiveTimeout = c.Server.InactiveTimeout
+	result.GameConfig.Server.MaxCrashLoops = c.Server.MaxCrashLoops
+	result.GameConfig.Server.JVMProfile = c.Server.JVMProfile
+	result.GameConfig.Server.JVMArgs = c.Server.JVMArgs
 	result.GameConfig.Motd = c.Server.Motd
 
 	// world config
@@ -75,6 +88,8 @@ func (c *LaunchConfig) ToRunnerConfig(cfg *config.Config) (*runner.Config, error
 	result.GameConfig.World.Seed = c.World.Seed
 	result.GameConfig.World.LevelType = c.World.LevelType
 	result.GameConfig.World.Difficulty = c.World.Difficulty
+	result.GameConfig.World.BackupFormat = c.World.BackupFormat
+	result.GameConfig.World.AutoBackupInterval = c.World.AutoBackupInterval
 
 	// misc config
 	result.GameConfig.Operators = c.Server.Operators
@@ -20,6 +20,8 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+var ErrAlreadyRunning = errors.New("the server is already running")
+
 type LauncherService struct {
 	config    *config.Config
 	kvs       kvs.KeyValueStore
@@ -36,9 +38,13 @@ func NewLauncherService(config *config.Config, kvs kvs.KeyValueStore, server ser
 	}
 }
 
-func (s *LauncherService) lockInstance(ctx context.Context) error {
+func runningKey(serverID string) string {
+	return fmt.Sprintf("running:%s", serverID)
+}
+
+func (s *LauncherService) lockInstance(ctx context.Context, serverID string) error {
 	var running bool
-	if err := s.kvs.GetSet(ctx, "running", true, -1, &running); err != nil {
+	if err := s.kvs.GetSet(ctx, runningKey(serverID), true, -1, &running); err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil
 		}
@@ -47,63 +53,80 @@ func (s *LauncherService) lockInstance(ctx context.Context) error {
 	}
 
 	if running {
-		return errors.New("another server is already running")
+		return ErrAlreadyRunning
 	}
 
 	return nil
 }
 
-func (s *LauncherService) releaseInstance(ctx context.Context) error {
-	if err := s.kvs.Del(ctx, "running"); err != nil {
+func (s *LauncherService) releaseInstance(ctx context.Context, serverID string) error {
+	if err := s.kvs.Del(ctx, runningKey(serverID)); err != nil {
 		return fmt.Errorf("failed to unlock instance: %w", err)
 	}
 
 	return nil
 }
 
-func (s *LauncherService) launchServer(ctx context.Context, config *LaunchConfig) {
+// IsRunning reports whether the server is launched (or being launched).
+func (s *LauncherService) IsRunning(ctx context.Context, serverID string) bool {
+	var running bool
+	if err := s.kvs.Get(ctx, runningKey(serverID), &running); err != nil {
+		return false
+	}
+	return running
+}
+
+func (s *LauncherService) launchServer(ctx context.Context, serverID string, config *LaunchConfig) {
 	runnerConfig, err := config.ToRunnerConfig(s.config)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to convert config to runner config", slog.Any("error", err))
 		s.streaming.PublishEvent(
 			ctx,
+			serverID,
 			streaming.NewInfoMessage(entity.InfoErrRunnerPrepare, true),
 		)
-		s.releaseInstance(context.TODO())
+		s.releaseInstance(context.TODO(), serverID)
 		return
 	}
 
-	if err := s.kvs.Set(ctx, fmt.Sprintf("runner:%s", runnerConfig.AuthKey), "default", -1); err != nil {
+	if err := s.kvs.Set(ctx, fmt.Sprintf("runner:%s", runnerConfig.AuthKey), serverID, -1); err != nil {
 		slog.ErrorContext(ctx, "Failed to save runner id", slog.Any("error", err))
 
 		s.streaming.PublishEvent(
 			ctx,
+			serverID,
 			streaming.NewInfoMessage(entity.InfoErrRunnerPrepare, true),
 		)
 
-		s.releaseInstance(context.TODO())
+		s.releaseInstance(context.TODO(), serverID)
 		return
 	}
 
+	if err := s.streaming.ClearServerLogs(ctx, serverID); err != nil {
+		slog.ErrorContext(ctx, "Failed to clear server logs", slog.Any("error", err))
+	}
+
 	s.streaming.PublishEvent(
 		ctx,
+		serverID,
 		streaming.NewStandardMessage(entity.EventCreateRunner, web.PageLoading),
 	)
 
 	if s.server.IsAvailable() {
-		serverCookie, err := s.server.Start(ctx, runnerConfig, config.MachineType)
+		serverCookie, err := s.server.Start(ctx, serverID, runnerConfig, config.MachineType)
 		if err != nil {
 			slog.ErrorContext(ctx, "Failed to start server", slog.Any("error", err))
 			goto failure
 		}
 
-		if err := s.kvs.Set(ctx, "runner-id:default", serverCookie, -1); err != nil {
+		if err := s.kvs.Set(ctx, fmt.Sprintf("runner-id:%s", serverID), serverCookie, -1); err != nil {
 			slog.ErrorContext(ctx, "Failed to set runner ID", slog.Any("error", err))
 			return
 		}
 
 		s.streaming.PublishEvent(
 			ctx,
+			serverID,
 			streaming.NewStandardMessageWithProgress(entity.EventCreateRunner, 50, web.PageLoading),
 		)
 
@@ -118,6 +141,7 @@ failure:
 
 	s.streaming.PublishEvent(
 		ctx,
+		serverID,
 		streaming.NewStandardMessageWithTextData(entity.EventManualSetup, authCode, web.PageManualSetup),
 	)
 
@@ -128,36 +152,38 @@ failure:
 	}
 }
 
-func (s *LauncherService) Launch(ctx context.Context, config *LaunchConfig) error {
+func (s *LauncherService) Launch(ctx context.Context, serverID string, config *LaunchConfig) error {
 	if err := config.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	if err := s.lockInstance(ctx); err != nil {
+	if err := s.lockInstance(ctx, serverID); err != nil {
 		return fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
-	go s.launchServer(trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx)), config)
+	go s.launchServer(trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx)), serverID, config)
 
 	return nil
 }
 
-func (h *LauncherService) Clean(ctx context.Context, authKey string) {
-	defer h.releaseInstance(context.TODO())
+func (h *LauncherService) Clean(ctx context.Context, serverID string, authKey string) {
+	defer h.releaseInstance(context.TODO(), serverID)
 
 	h.streaming.PublishEvent(
 		ctx,
+		serverID,
 		streaming.NewStandardMessage(entity.EventStopRunner, web.PageLoading),
 	)
 
 	var serverCookie server.ServerCookie
-	if err := h.kvs.Get(ctx, "runner-id:default", &serverCookie); err != nil || !h.server.IsAvailable() {
+	if err := h.kvs.Get(ctx, fmt.Sprintf("runner-id:%s", serverID), &serverCookie); err != nil || !h.server.IsAvailable() {
 		if err == redis.Nil {
 			goto out
 		}
 
 		h.streaming.PublishEvent(
 			ctx,
+			serverID,
 			streaming.NewInfoMessage(entity.InfoErrRunnerStop, true),
 		)
 		return
@@ -165,12 +191,14 @@ func (h *LauncherService) Clean(ctx context.Context, authKey string) {
 
 	h.streaming.PublishEvent(
 		ctx,
+		serverID,
 		streaming.NewStandardMessageWithProgress(entity.EventStopRunner, 10, web.PageLoading),
 	)
 
 	if !h.server.Stop(ctx, serverCookie) {
 		h.streaming.PublishEvent(
 			ctx,
+			serverID,
 			streaming.NewInfoMessage(entity.InfoErrRunnerStop, true),
 		)
 		return
@@ -178,25 +206,36 @@ func (h *LauncherService) Clean(ctx context.Context, authKey string) {
 
 	h.streaming.PublishEvent(
 		ctx,
+		serverID,
 		streaming.NewStandardMessageWithProgress(entity.EventStopRunner, 60, web.PageLoading),
 	)
 
 	if !h.server.Delete(ctx, serverCookie) {
 		h.streaming.PublishEvent(
 			ctx,
+			serverID,
 			streaming.NewInfoMessage(entity.InfoErrRunnerStop, true),
 		)
 		return
 	}
 
 out:
-	if err := h.kvs.Del(ctx, "runner-id:default", "runner-info:default", "world-info:default", fmt.Sprintf("runner:%s", authKey)); err != nil {
+	if err := h.kvs.Del(
+		ctx,
+		fmt.Sprintf("runner-id:%s", serverID),
+		fmt.Sprintf("runner-info:%s", serverID),
+		fmt.Sprintf("world-info:%s", serverID),
+		fmt.Sprintf("players:%s", serverID),
+		fmt.Sprintf("datapacks:%s", serverID),
+		fmt.Sprintf("runner:%s", authKey),
+	); err != nil {
 		slog.ErrorContext(ctx, "Failed to unset runner information", slog.Any("error", err))
 		return
 	}
 
 	h.streaming.PublishEvent(
 		ctx,
+		serverID,
 		streaming.NewStandardMessage(entity.EventStopped, web.PageLaunch),
 	)
 
@@ -15,6 +15,7 @@ import (
 	"github.com/kofuk/premises/backend/common/retry"
 	"github.com/kofuk/premises/backend/ctrlplane/common/config"
 	"github.com/kofuk/premises/backend/ctrlplane/common/conoha"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
 	"github.com/kofuk/premises/backend/ctrlplane/common/startup"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
@@ -82,7 +83,7 @@ func getMemorySize(machineType string) (string, error) {
 	}
 }
 
-func (s *ConohaServer) Start(ctx context.Context, gameConfig *runner.Config, machineType string) (ServerCookie, error) {
+func (s *ConohaServer) Start(ctx context.Context, serverID string, gameConfig *runner.Config, machineType string) (ServerCookie, error) {
 	flavorName, err := getMemorySize(machineType)
 	if err != nil {
 		return "", fmt.Errorf("invalid machine type: %w", err)
@@ -105,7 +106,7 @@ func (s *ConohaServer) Start(ctx context.Context, gameConfig *runner.Config, mac
 	if err != nil {
 		return "", fmt.Errorf("failed to get volumes: %w", err)
 	}
-	volumeID, err := findVolume(volumes.Volumes, s.cfg.ConohaNameTag)
+	volumeID, err := findVolume(volumes.Volumes, servers.ResourceNameTag(s.cfg.ConohaNameTag, serverID))
 	if err != nil {
 		return "", fmt.Errorf("failed to get volume ID: %w", err)
 	}
@@ -116,7 +117,7 @@ func (s *ConohaServer) Start(ctx context.Context, gameConfig *runner.Config, mac
 	server, err := s.conoha.CreateServer(ctx, conoha.CreateServerInput{
 		FlavorID:     flavorId,
 		RootVolumeID: volumeID,
-		NameTag:      s.cfg.ConohaNameTag,
+		NameTag:      servers.ResourceNameTag(s.cfg.ConohaNameTag, serverID),
 		UserData:     string(startupScript),
 	})
 	if err != nil {
@@ -156,18 +157,18 @@ func findServer(servers []conoha.ServerDetail, nameTag string) (string, error) {
 	return "", errors.New("no matching server")
 }
 
-func (s *ConohaServer) Find(ctx context.Context) (ServerCookie, error) {
-	servers, err := s.conoha.ListServerDetails(ctx)
+func (s *ConohaServer) Find(ctx context.Context, serverID string) (ServerCookie, error) {
+	details, err := s.conoha.ListServerDetails(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	serverID, err := findServer(servers.Servers, s.cfg.ConohaNameTag)
+	vmID, err := findServer(details.Servers, servers.ResourceNameTag(s.cfg.ConohaNameTag, serverID))
 	if err != nil {
 		return "", err
 	}
 
-	return ServerCookie(serverID), nil
+	return ServerCookie(vmID), nil
 }
 
 func (s *ConohaServer) IsRunning(ctx context.Context, cookie ServerCookie) bool {
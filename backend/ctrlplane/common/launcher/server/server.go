@@ -10,8 +10,8 @@ type ServerCookie string
 
 type GameServer interface {
 	IsAvailable() bool
-	Start(ctx context.Context, gameConfig *runner.Config, machineType string) (ServerCookie, error)
-	Find(ctx context.Context) (ServerCookie, error)
+	Start(ctx context.Context, serverID string, gameConfig *runner.Config, machineType string) (ServerCookie, error)
+	Find(ctx context.Context, serverID string) (ServerCookie, error)
 	IsRunning(ctx context.Context, cookie ServerCookie) bool
 	Stop(ctx context.Context, cookie ServerCookie) bool
 	Delete(ctx context.Context, cookie ServerCookie) bool
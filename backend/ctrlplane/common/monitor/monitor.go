@@ -15,6 +15,8 @@ import (
 	"github.com/kofuk/premises/backend/ctrlplane/common/conoha"
 	"github.com/kofuk/premises/backend/ctrlplane/common/kvs"
 	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
@@ -33,13 +35,13 @@ func GetPageCodeByEventCode(event entity.EventCode) web.PageCode {
 	return web.PageLoading
 }
 
-func AttachRunner(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore, ipv4Addr string) error {
+func AttachRunner(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore, runnerId string, ipv4Addr string) error {
 	if cfg.ConohaUser == "" || cfg.ConohaPassword == "" {
 		return nil
 	}
 
 	var id string
-	if err := cache.Get(ctx, "runner-id:default", &id); err == nil {
+	if err := cache.Get(ctx, fmt.Sprintf("runner-id:%s", runnerId), &id); err == nil {
 		return nil
 	}
 
@@ -85,7 +87,7 @@ out:
 		return errors.New("no matching server")
 	}
 
-	if err := cache.Set(ctx, "runner-id:default", matchingServer.ID, -1); err != nil {
+	if err := cache.Set(ctx, fmt.Sprintf("runner-id:%s", runnerId), matchingServer.ID, -1); err != nil {
 		return err
 	}
 
@@ -114,7 +116,7 @@ func HandleEvent(ctx context.Context, runnerId string, strmService *streaming.St
 		}
 
 		if len(event.Hello.Addr.IPv4) != 0 {
-			if err := AttachRunner(ctx, cfg, kvs, event.Hello.Addr.IPv4[0]); err != nil {
+			if err := AttachRunner(ctx, cfg, kvs, runnerId, event.Hello.Addr.IPv4[0]); err != nil {
 				slog.ErrorContext(ctx, "Error updating runner ID", slog.Any("error", err))
 			}
 		}
@@ -124,8 +126,31 @@ func HandleEvent(ctx context.Context, runnerId string, strmService *streaming.St
 			return errors.New("invalid event message: has no Status")
 		}
 
+		if event.Status.EventCode == entity.EventCrashed && event.Status.CrashReport != "" {
+			if !world.IsCrashReportOf(event.Status.CrashReport, runnerId) {
+				return errors.New("invalid event message: crash report of another server")
+			}
+
+			report := web.CrashReport{
+				ID:        event.Status.CrashReport,
+				Timestamp: int(time.Now().UnixMilli()),
+			}
+			if err := kvs.Set(ctx, fmt.Sprintf("crash-report:%s", runnerId), report, 30*24*time.Hour); err != nil {
+				return err
+			}
+
+			// The UI shows the link to the crash report with this.
+			strmService.PublishEvent(
+				ctx,
+				runnerId,
+				streaming.NewStandardMessageWithTextData(event.Status.EventCode, event.Status.CrashReport, GetPageCodeByEventCode(event.Status.EventCode)),
+			)
+			break
+		}
+
 		strmService.PublishEvent(
 			ctx,
+			runnerId,
 			streaming.NewStandardMessageWithProgress(event.Status.EventCode, event.Status.Progress, GetPageCodeByEventCode(event.Status.EventCode)),
 		)
 
@@ -136,6 +161,7 @@ func HandleEvent(ctx context.Context, runnerId string, strmService *streaming.St
 
 		strmService.PublishEvent(
 			ctx,
+			runnerId,
 			streaming.NewInfoMessage(event.Info.InfoCode, event.Info.IsError),
 		)
 
@@ -147,13 +173,87 @@ func HandleEvent(ctx context.Context, runnerId string, strmService *streaming.St
 		if err := kvs.Set(ctx, fmt.Sprintf("world-info:%s", runnerId), event.Started, 30*24*time.Hour); err != nil {
 			return err
 		}
+
+	case runner.EventPlayers:
+		if event.Players == nil {
+			return errors.New("invalid event message: has no Players")
+		}
+
+		players := web.PlayerList{
+			MaxPlayers: event.Players.MaxPlayers,
+			Players:    event.Players.Players,
+		}
+		if players.Players == nil {
+			players.Players = []string{}
+		}
+
+		if err := kvs.Set(ctx, fmt.Sprintf("players:%s", runnerId), players, 30*24*time.Hour); err != nil {
+			return err
+		}
+
+		strmService.PublishEvent(
+			ctx,
+			runnerId,
+			streaming.NewPlayersMessage(players),
+		)
+
+	case runner.EventLogs:
+		if event.Logs == nil {
+			return errors.New("invalid event message: has no Logs")
+		}
+
+		lines := make([]web.ServerLogLine, 0, len(event.Logs.Lines))
+		for _, line := range event.Logs.Lines {
+			lines = append(lines, web.ServerLogLine{
+				Time:    line.Time,
+				Level:   line.Level,
+				Message: line.Message,
+			})
+		}
+
+		if err := strmService.AppendServerLogs(ctx, runnerId, lines); err != nil {
+			return err
+		}
+
+		strmService.PublishEvent(
+			ctx,
+			runnerId,
+			streaming.NewLogsMessage(lines),
+		)
+
+	case runner.EventDatapacks:
+		if event.Datapacks == nil {
+			return errors.New("invalid event message: has no Datapacks")
+		}
+
+		datapacks := make([]web.InstalledDatapack, 0, len(event.Datapacks.Datapacks))
+		for _, datapack := range event.Datapacks.Datapacks {
+			datapacks = append(datapacks, web.InstalledDatapack{
+				Name:    datapack.Name,
+				Enabled: datapack.Enabled,
+			})
+		}
+
+		if err := kvs.Set(ctx, fmt.Sprintf("datapacks:%s", runnerId), datapacks, 30*24*time.Hour); err != nil {
+			return err
+		}
+
+	case runner.EventConsole:
+		if event.Console == nil {
+			return errors.New("invalid event message: has no Console")
+		}
+
+		// The result will be picked up by the handler waiting for it.
+		if err := kvs.Set(ctx, fmt.Sprintf("console-result:%s", event.Console.ID), event.Console, time.Minute); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func GetSystemInfo(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore) (*web.SystemInfo, error) {
+func GetSystemInfo(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore, serverID string) (*web.SystemInfo, error) {
 	var serverHello runner.HelloExtra
-	if err := cache.Get(ctx, "runner-info:default", &serverHello); err != nil {
+	if err := cache.Get(ctx, fmt.Sprintf("runner-info:%s", serverID), &serverHello); err != nil {
 		return nil, err
 	}
 
@@ -162,16 +262,38 @@ func GetSystemInfo(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueS
 		ipAddr = &serverHello.Addr.IPv4[0]
 	}
 
+	// The command line is known after the server has started.
+	var startedData runner.StartedExtra
+	if err := cache.Get(ctx, fmt.Sprintf("world-info:%s", serverID), &startedData); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
 	return &web.SystemInfo{
 		PremisesVersion: serverHello.Version,
 		HostOS:          serverHello.Host,
 		IPAddress:       ipAddr,
+		CommandLine:     startedData.CommandLine,
 	}, nil
 }
 
-func GetWorldInfo(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore) (*web.WorldInfo, error) {
+// GetCrashReport returns the crash report which the server uploaded last time. URL is not filled.
+func GetCrashReport(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore, serverID string) (*web.CrashReport, error) {
+	var report web.CrashReport
+	if err := cache.Get(ctx, fmt.Sprintf("crash-report:%s", serverID), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func GetWorldInfo(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore, serverID string) (*web.WorldInfo, error) {
 	var startedData runner.StartedExtra
-	if err := cache.Get(ctx, "world-info:default", &startedData); err != nil {
+	if err := cache.Get(ctx, fmt.Sprintf("world-info:%s", serverID), &startedData); err != nil {
+		return nil, err
+	}
+
+	// Datapacks are reported after the server started, or never if the runner is older.
+	datapacks := []web.InstalledDatapack{}
+	if err := cache.Get(ctx, fmt.Sprintf("datapacks:%s", serverID), &datapacks); err != nil && !errors.Is(err, redis.Nil) {
 		return nil, err
 	}
 
@@ -179,5 +301,15 @@ func GetWorldInfo(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueSt
 		Version:   startedData.ServerVersion,
 		WorldName: startedData.World.Name,
 		Seed:      startedData.World.Seed,
+		Datapacks: datapacks,
 	}, nil
 }
+
+func GetPlayers(ctx context.Context, cfg *config.Config, cache *kvs.KeyValueStore, serverID string) (*web.PlayerList, error) {
+	var players web.PlayerList
+	if err := cache.Get(ctx, fmt.Sprintf("players:%s", serverID), &players); err != nil {
+		return nil, err
+	}
+
+	return &players, nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/kofuk/premises/backend/ctrlplane/common/config"
 	"github.com/kofuk/premises/backend/ctrlplane/common/kvs"
 	"github.com/kofuk/premises/backend/ctrlplane/common/longpoll"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -33,6 +34,7 @@ type Connection struct {
 type ProxyHandler struct {
 	kvs        kvs.KeyValueStore
 	action     *longpoll.LongPollService
+	servers    *servers.ServerService
 	bindAddr   string
 	endpoint   string
 	iconURL    string
@@ -43,7 +45,7 @@ type ProxyHandler struct {
 	wg         sync.WaitGroup
 }
 
-func NewProxyHandler(cfg *config.Config, kvs kvs.KeyValueStore, action *longpoll.LongPollService) (*ProxyHandler, error) {
+func NewProxyHandler(cfg *config.Config, kvs kvs.KeyValueStore, action *longpoll.LongPollService, servers *servers.ServerService) (*ProxyHandler, error) {
 	bindAddr := cfg.ProxyBind
 	if bindAddr == "" {
 		bindAddr = "0.0.0.0:25565"
@@ -59,6 +61,7 @@ func NewProxyHandler(cfg *config.Config, kvs kvs.KeyValueStore, action *longpoll
 		endpoint:   cfg.ProxyBackendAddr,
 		kvs:        kvs,
 		action:     action,
+		servers:    servers,
 		iconURL:    cfg.IconURL,
 		gameDomain: cfg.GameDomain,
 		cert:       cert,
@@ -178,6 +181,24 @@ func (p *ProxyHandler) handleDummyServer(ctx context.Context, h *protocol.Handle
 	return nil
 }
 
+// resolveServer returns ID of the server players are connecting to with the hostname, and whether it is running.
+func (p *ProxyHandler) resolveServer(ctx context.Context, hostname string) (string, bool) {
+	server, err := p.servers.FindByHostname(ctx, hostname)
+	if err != nil {
+		if !errors.Is(err, servers.ErrNotFound) {
+			slog.ErrorContext(ctx, "Error finding server", slog.Any("error", err))
+		}
+		return "", false
+	}
+
+	var running bool
+	if err := p.kvs.Get(ctx, fmt.Sprintf("running:%s", server.ID), &running); err != nil {
+		running = false
+	}
+
+	return server.ID, running
+}
+
 func (p *ProxyHandler) handleConn(ctx context.Context, conn io.ReadWriteCloser) error {
 	defer func() {
 		if err := recover(); err != nil {
@@ -200,12 +221,8 @@ func (p *ProxyHandler) handleConn(ctx context.Context, conn io.ReadWriteCloser)
 		return fmt.Errorf("handshake error: %w", err)
 	}
 
-	var running bool
-	if err := p.kvs.Get(ctx, "running", &running); err != nil {
-		running = false
-	}
-
-	if hs.ServerAddr != p.gameDomain || !running {
+	serverID, running := p.resolveServer(ctx, hs.ServerAddr)
+	if !running {
 		if hs.NextState != 1 {
 			return fmt.Errorf("unknown server: %s", hs.ServerAddr)
 		}
@@ -227,7 +244,7 @@ func (p *ProxyHandler) handleConn(ctx context.Context, conn io.ReadWriteCloser)
 	}
 	defer deleteFromPool()
 
-	p.action.Push(ctx, "default", runner.Action{
+	p.action.Push(ctx, serverID, runner.Action{
 		Type: runner.ActionConnReq,
 		ConnReq: &runner.ConnReqInfo{
 			ConnectionID: connID.String(),
@@ -0,0 +1,119 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/robfig/cron/v3"
+	"github.com/uptrace/bun"
+)
+
+var ErrNotFound = errors.New("schedule not found")
+
+// Parse parses a cron spec (e.g. "0 19 * * 1-5") evaluated in the time zone.
+func Parse(spec, timezone string) (cron.Schedule, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid time zone: %w", err)
+	}
+	return cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", timezone, spec))
+}
+
+// IsDue reports whether the spec fires within (from, to].
+// Empty spec never fires.
+func IsDue(spec, timezone string, from, to time.Time) (bool, error) {
+	if spec == "" {
+		return false, nil
+	}
+	sched, err := Parse(spec, timezone)
+	if err != nil {
+		return false, err
+	}
+	return !sched.Next(from).After(to), nil
+}
+
+// Next returns the next time the spec fires after t, or nil if the spec is empty or invalid.
+func Next(spec, timezone string, t time.Time) *time.Time {
+	if spec == "" {
+		return nil
+	}
+	sched, err := Parse(spec, timezone)
+	if err != nil {
+		return nil
+	}
+	next := sched.Next(t)
+	return &next
+}
+
+type ScheduleService struct {
+	db *bun.DB
+}
+
+func New(db *bun.DB) *ScheduleService {
+	return &ScheduleService{
+		db: db,
+	}
+}
+
+func (s *ScheduleService) List(ctx context.Context) ([]model.Schedule, error) {
+	schedules := make([]model.Schedule, 0)
+	if err := s.db.NewSelect().Model(&schedules).Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *ScheduleService) ListEnabled(ctx context.Context) ([]model.Schedule, error) {
+	schedules := make([]model.Schedule, 0)
+	if err := s.db.NewSelect().Model(&schedules).Where("enabled").Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *ScheduleService) Get(ctx context.Context, id uint) (*model.Schedule, error) {
+	schedule := new(model.Schedule)
+	if err := s.db.NewSelect().Model(schedule).Where("id = ?", id).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *ScheduleService) Create(ctx context.Context, schedule *model.Schedule) error {
+	if _, err := s.db.NewInsert().Model(schedule).Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ScheduleService) Update(ctx context.Context, schedule *model.Schedule) error {
+	result, err := s.db.NewUpdate().Model(schedule).Column("name", "launch_at", "stop_at", "timezone", "config", "enabled").WherePK().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *ScheduleService) Delete(ctx context.Context, id uint) error {
+	result, err := s.db.NewDelete().Model((*model.Schedule)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
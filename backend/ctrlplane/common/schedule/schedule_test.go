@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedule", func() {
+	DescribeTable("Parse", func(spec, timezone string, valid bool) {
+		_, err := Parse(spec, timezone)
+		if valid {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("weekday evenings", "0 19 * * 1-5", "Asia/Tokyo", true),
+		Entry("empty time zone", "30 4 * * *", "", true),
+		Entry("unknown time zone", "0 19 * * *", "Mars/Olympus", false),
+		Entry("invalid spec", "every day", "UTC", false),
+		Entry("seconds are not supported", "0 0 19 * * *", "UTC", false),
+	)
+
+	DescribeTable("IsDue", func(spec, timezone string, from, to time.Time, due bool) {
+		actual, err := IsDue(spec, timezone, from, to)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(due))
+	},
+		// 19:00 JST is 10:00 UTC.
+		Entry("fires in range", "0 19 * * 1-5", "Asia/Tokyo",
+			time.Date(2026, 10, 16, 9, 59, 0, 0, time.UTC), time.Date(2026, 10, 16, 10, 0, 1, 0, time.UTC), true),
+		Entry("fires at the end of range", "0 19 * * 1-5", "Asia/Tokyo",
+			time.Date(2026, 10, 16, 9, 59, 0, 0, time.UTC), time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), true),
+		Entry("not yet", "0 19 * * 1-5", "Asia/Tokyo",
+			time.Date(2026, 10, 16, 9, 58, 0, 0, time.UTC), time.Date(2026, 10, 16, 9, 59, 0, 0, time.UTC), false),
+		Entry("weekend", "0 19 * * 1-5", "Asia/Tokyo",
+			time.Date(2026, 10, 17, 9, 59, 0, 0, time.UTC), time.Date(2026, 10, 17, 10, 0, 1, 0, time.UTC), false),
+		Entry("already fired at the start of range", "0 19 * * 1-5", "Asia/Tokyo",
+			time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), time.Date(2026, 10, 16, 10, 1, 0, 0, time.UTC), false),
+		Entry("empty spec", "", "UTC",
+			time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), false),
+	)
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schedule Suite")
+}
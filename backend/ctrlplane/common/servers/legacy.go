@@ -0,0 +1,41 @@
+package servers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// legacyKeys are Redis keys which were used before multiple servers were supported.
+// They hold the state of the default server.
+var legacyKeys = []string{"running", "pending-config", "current-state"}
+
+// MigrateLegacyKeys moves the state of the server saved by older versions to the keys of the default server,
+// so that a server which is running while Premises is updated can still be managed.
+// Keys which the default server already has are not overwritten.
+func MigrateLegacyKeys(ctx context.Context, client *redis.Client) error {
+	for _, key := range legacyKeys {
+		n, err := client.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+
+		newKey := key + ":" + DefaultServerID
+		renamed, err := client.RenameNX(ctx, key, newKey).Result()
+		if err != nil {
+			return err
+		}
+		if !renamed {
+			// The legacy key is stale since the new one has been written.
+			if err := client.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+		slog.InfoContext(ctx, "Migrated legacy key", slog.String("key", key), slog.String("new_key", newKey), slog.Bool("renamed", renamed))
+	}
+	return nil
+}
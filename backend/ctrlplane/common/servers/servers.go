@@ -0,0 +1,109 @@
+package servers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/uptrace/bun"
+)
+
+// DefaultServerID is the ID of the server which exists from the beginning and can't be deleted.
+const DefaultServerID = "default"
+
+var ErrNotFound = errors.New("server not found")
+
+var serverIDRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// IsValidID reports whether id can be used as a server ID.
+// Server IDs are used as a part of Redis keys and resource names, so only a limited set of characters is allowed.
+func IsValidID(id string) bool {
+	return serverIDRegexp.MatchString(id)
+}
+
+// ResourceNameTag returns the name tag of cloud resources (VMs and volumes) used by the server.
+// The default server keeps using the configured name tag so that resources created before are found.
+func ResourceNameTag(nameTag, serverID string) string {
+	if serverID == DefaultServerID {
+		return nameTag
+	}
+	return nameTag + "-" + serverID
+}
+
+type ServerService struct {
+	db         *bun.DB
+	gameDomain string
+}
+
+func New(db *bun.DB, gameDomain string) *ServerService {
+	return &ServerService{
+		db:         db,
+		gameDomain: gameDomain,
+	}
+}
+
+// Hostname returns the hostname players use to connect to the server.
+func (s *ServerService) Hostname(server *model.Server) string {
+	if server.Hostname == "" {
+		return s.gameDomain
+	}
+	return server.Hostname
+}
+
+func (s *ServerService) List(ctx context.Context) ([]model.Server, error) {
+	servers := make([]model.Server, 0)
+	if err := s.db.NewSelect().Model(&servers).Order("created_at", "id").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+func (s *ServerService) Get(ctx context.Context, id string) (*model.Server, error) {
+	server := new(model.Server)
+	if err := s.db.NewSelect().Model(server).Where("id = ?", id).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return server, nil
+}
+
+// FindByHostname returns the server which players connect to with the hostname.
+func (s *ServerService) FindByHostname(ctx context.Context, hostname string) (*model.Server, error) {
+	server := new(model.Server)
+	query := s.db.NewSelect().Model(server)
+	if hostname == s.gameDomain {
+		query = query.Where("hostname = '' OR hostname = ?", hostname)
+	} else {
+		query = query.Where("hostname = ?", hostname)
+	}
+
+	if err := query.Limit(1).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return server, nil
+}
+
+func (s *ServerService) Create(ctx context.Context, server *model.Server) error {
+	if _, err := s.db.NewInsert().Model(server).Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ServerService) Delete(ctx context.Context, id string) error {
+	result, err := s.db.NewDelete().Model((*model.Server)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
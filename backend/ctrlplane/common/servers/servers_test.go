@@ -0,0 +1,36 @@
+package servers
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Servers", func() {
+	DescribeTable("IsValidID", func(id string, isValid bool) {
+		actual := IsValidID(id)
+		Expect(actual).To(Equal(isValid))
+	},
+		Entry("default", "default", true),
+		Entry("with hyphen", "survival-2", true),
+		Entry("empty", "", false),
+		Entry("upper case", "Survival", false),
+		Entry("leading hyphen", "-survival", false),
+		Entry("colon", "a:b", false),
+		Entry("too long", "abcdefghijklmnopqrstuvwxyz0123456", false),
+	)
+
+	DescribeTable("ResourceNameTag", func(serverID string, expected string) {
+		actual := ResourceNameTag("mc-premises", serverID)
+		Expect(actual).To(Equal(expected))
+	},
+		Entry("default server", "default", "mc-premises"),
+		Entry("other server", "survival", "mc-premises-survival"),
+	)
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Servers Suite")
+}
@@ -3,6 +3,7 @@ package streaming
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 
 	"github.com/kofuk/premises/backend/common/entity"
@@ -10,8 +11,12 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// Hook is called for each message published to a server.
+type Hook func(ctx context.Context, serverID string, message Message)
+
 type StreamingService struct {
 	redis *redis.Client
+	hooks []Hook
 }
 
 func NewStreamingService(redis *redis.Client) *StreamingService {
@@ -25,6 +30,8 @@ type MessageType int
 const (
 	EventMessage MessageType = iota
 	NotifyMessage
+	PlayersMessage
+	LogsMessage
 )
 
 func (m MessageType) String() string {
@@ -33,6 +40,10 @@ func (m MessageType) String() string {
 		return "event"
 	case NotifyMessage:
 		return "notify"
+	case PlayersMessage:
+		return "players"
+	case LogsMessage:
+		return "logs"
 	default:
 		return "<unknown>"
 	}
@@ -87,14 +98,42 @@ func NewInfoMessage(infoCode entity.InfoCode, isError bool) Message {
 	}
 }
 
-func (s *StreamingService) publishEvent(ctx context.Context, message Message) error {
+func NewPlayersMessage(players web.PlayerList) Message {
+	return Message{
+		Type: PlayersMessage,
+		Body: players,
+	}
+}
+
+func NewLogsMessage(lines []web.ServerLogLine) Message {
+	return Message{
+		Type: LogsMessage,
+		Body: web.ServerLogBatch{
+			Lines: lines,
+		},
+	}
+}
+
+func currentStateKey(serverID string) string {
+	return fmt.Sprintf("current-state:%s", serverID)
+}
+
+func eventsChannel(serverID string) string {
+	return fmt.Sprintf("events:%s", serverID)
+}
+
+func serverLogsKey(serverID string) string {
+	return fmt.Sprintf("server-logs:%s", serverID)
+}
+
+func (s *StreamingService) publishEvent(ctx context.Context, serverID string, message Message) error {
 	switch message.Type {
 	case EventMessage:
 		body, err := json.Marshal(message.Body)
 		if err != nil {
 			return err
 		}
-		if _, err := s.redis.Set(ctx, "current-state", body, 0).Result(); err != nil {
+		if _, err := s.redis.Set(ctx, currentStateKey(serverID), body, 0).Result(); err != nil {
 			return err
 		}
 	}
@@ -103,16 +142,26 @@ func (s *StreamingService) publishEvent(ctx context.Context, message Message) er
 	if err != nil {
 		return err
 	}
-	if _, err := s.redis.Publish(ctx, "events", data).Result(); err != nil {
+	if _, err := s.redis.Publish(ctx, eventsChannel(serverID), data).Result(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *StreamingService) PublishEvent(ctx context.Context, message Message) {
-	if err := s.publishEvent(ctx, message); err != nil {
-		slog.ErrorContext(ctx, "Failed to publish event", slog.Any("error", err))
+// AddHook registers a hook called after messages are published.
+// It must be called before the service is used.
+func (s *StreamingService) AddHook(hook Hook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *StreamingService) PublishEvent(ctx context.Context, serverID string, message Message) {
+	if err := s.publishEvent(ctx, serverID, message); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish event", slog.Any("error", err), slog.String("server_id", serverID))
+	}
+
+	for _, hook := range s.hooks {
+		hook(ctx, serverID, message)
 	}
 }
 
@@ -146,8 +195,8 @@ func (s *Subscription) Channel() chan Message {
 	return outChannel
 }
 
-func (s *StreamingService) SubscribeEvent(ctx context.Context) (*Subscription, error) {
-	currentState, err := s.redis.Get(ctx, "current-state").Result()
+func (s *StreamingService) SubscribeEvent(ctx context.Context, serverID string) (*Subscription, error) {
+	currentState, err := s.redis.Get(ctx, currentStateKey(serverID)).Result()
 	if err != nil && err != redis.Nil {
 		return nil, err
 	}
@@ -159,7 +208,7 @@ func (s *StreamingService) SubscribeEvent(ctx context.Context) (*Subscription, e
 		currentState = string(defState)
 	}
 
-	subscription := s.redis.Subscribe(ctx, "events")
+	subscription := s.redis.Subscribe(ctx, eventsChannel(serverID))
 
 	return &Subscription{
 		subscription: subscription,
@@ -173,3 +222,53 @@ func (s *StreamingService) ClearSysstat(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Number of server log lines kept in Redis for clients which connect later.
+const maxServerLogLines = 1000
+
+func (s *StreamingService) AppendServerLogs(ctx context.Context, serverID string, lines []web.ServerLogLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	values := make([]any, 0, len(lines))
+	for _, line := range lines {
+		data, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		values = append(values, data)
+	}
+
+	pipe := s.redis.TxPipeline()
+	pipe.RPush(ctx, serverLogsKey(serverID), values...)
+	pipe.LTrim(ctx, serverLogsKey(serverID), -maxServerLogLines, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *StreamingService) GetServerLogs(ctx context.Context, serverID string) ([]web.ServerLogLine, error) {
+	values, err := s.redis.LRange(ctx, serverLogsKey(serverID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]web.ServerLogLine, 0, len(values))
+	for _, value := range values {
+		var line web.ServerLogLine
+		if err := json.Unmarshal([]byte(value), &line); err != nil {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (s *StreamingService) ClearServerLogs(ctx context.Context, serverID string) error {
+	if _, err := s.redis.Del(ctx, serverLogsKey(serverID)).Result(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/retry"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
+	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+)
+
+const (
+	SignatureHeader = "X-Premises-Signature"
+	DeliveryHeader  = "X-Premises-Delivery"
+)
+
+// Deliveries are retried with exponential backoff until this duration elapses.
+const deliveryTimeout = 10 * time.Minute
+
+var ErrNotFound = errors.New("webhook not found")
+
+func IsValidFormat(format string) bool {
+	return slices.Contains([]string{FormatJSON, FormatSlack, FormatDiscord}, format)
+}
+
+// GenerateSecret generates a secret used to sign payloads.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign returns the value of the signature header for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches reports whether the webhook is subscribed to the message.
+func Matches(webhook *model.Webhook, message streaming.Message) bool {
+	switch body := message.Body.(type) {
+	case web.StandardMessage:
+		return message.Type == streaming.EventMessage && slices.Contains(webhook.EventCodes, int(body.EventCode))
+	case web.InfoMessage:
+		return message.Type == streaming.NotifyMessage && slices.Contains(webhook.InfoCodes, int(body.InfoCode))
+	}
+	return false
+}
+
+var eventDescriptions = map[entity.EventCode]string{
+	entity.EventRunning:  "is up",
+	entity.EventStopping: "is stopping",
+	entity.EventCrashed:  "has crashed",
+	entity.EventStopped:  "has stopped",
+}
+
+var infoDescriptions = map[entity.InfoCode]string{
+	entity.InfoSnapshotDone:           "took a snapshot",
+	entity.InfoSnapshotError:          "failed to take a snapshot",
+	entity.InfoReconfigureDone:        "was reconfigured",
+	entity.InfoReconfigureError:       "failed to be reconfigured",
+	entity.InfoScheduledLaunch:        "was launched by schedule",
+	entity.InfoScheduledStop:          "was stopped by schedule",
+	entity.InfoScheduledLaunchSkipped: "skipped a scheduled launch",
+	entity.InfoAutoBackupDone:         "backed up the world",
+	entity.InfoAutoBackupError:        "failed to back up the world",
+	entity.InfoStopScheduled:          "is going to stop after a countdown",
+	entity.InfoStopCancelled:          "cancelled the stop",
+	entity.InfoErrRunnerPrepare:       "failed to start",
+	entity.InfoErrRunnerStop:          "failed to stop",
+	entity.InfoErrScheduledLaunch:     "failed to be launched by schedule",
+	entity.InfoErrScheduledStop:       "failed to be stopped by schedule",
+}
+
+// Summarize returns a human readable description of the payload, which chat services show.
+func Summarize(payload *web.WebhookPayload) string {
+	var description string
+	switch payload.Type {
+	case streaming.EventMessage.String():
+		description = eventDescriptions[payload.EventCode]
+		if description == "" {
+			description = "reported event " + strconv.Itoa(int(payload.EventCode))
+		}
+		if payload.EventCode == entity.EventRunning && payload.Hostname != "" {
+			description += " at " + payload.Hostname
+		}
+	case streaming.NotifyMessage.String():
+		description = infoDescriptions[payload.InfoCode]
+		if description == "" {
+			description = "reported info " + strconv.Itoa(int(payload.InfoCode))
+		}
+	}
+	return fmt.Sprintf("Server %s %s", payload.ServerName, description)
+}
+
+// Encode returns the request body sent to the webhook.
+func Encode(format string, payload *web.WebhookPayload) ([]byte, error) {
+	switch format {
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": payload.Text})
+	case FormatDiscord:
+		return json.Marshal(map[string]string{"content": payload.Text})
+	default:
+		return json.Marshal(payload)
+	}
+}
+
+type WebhookService struct {
+	db      *bun.DB
+	servers *servers.ServerService
+	client  *http.Client
+}
+
+func New(db *bun.DB, servers *servers.ServerService) *WebhookService {
+	return &WebhookService{
+		db:      db,
+		servers: servers,
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   10 * time.Second,
+		},
+	}
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]model.Webhook, error) {
+	webhooks := make([]model.Webhook, 0)
+	if err := s.db.NewSelect().Model(&webhooks).Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id uint) (*model.Webhook, error) {
+	webhook := new(model.Webhook)
+	if err := s.db.NewSelect().Model(webhook).Where("id = ?", id).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) Create(ctx context.Context, webhook *model.Webhook) error {
+	if _, err := s.db.NewInsert().Model(webhook).Exec(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *WebhookService) Update(ctx context.Context, webhook *model.Webhook) error {
+	result, err := s.db.NewUpdate().Model(webhook).Column("name", "url", "format", "event_codes", "info_codes", "enabled").WherePK().Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id uint) error {
+	result, err := s.db.NewDelete().Model((*model.Webhook)(nil)).Where("id = ?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns deliveries of the webhook in reverse chronological order along with the total number of them.
+func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID uint, limit, offset int) ([]model.WebhookDelivery, int, error) {
+	deliveries := make([]model.WebhookDelivery, 0)
+	total, err := s.db.NewSelect().Model(&deliveries).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Offset(offset).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (s *WebhookService) buildPayload(ctx context.Context, serverID string, message streaming.Message) *web.WebhookPayload {
+	payload := &web.WebhookPayload{
+		ServerID:   serverID,
+		ServerName: serverID,
+		Type:       message.Type.String(),
+		Timestamp:  time.Now(),
+	}
+	if server, err := s.servers.Get(ctx, serverID); err == nil {
+		payload.ServerName = server.Name
+		payload.Hostname = s.servers.Hostname(server)
+	}
+
+	switch body := message.Body.(type) {
+	case web.StandardMessage:
+		payload.EventCode = body.EventCode
+	case web.InfoMessage:
+		payload.InfoCode = body.InfoCode
+		payload.IsError = body.IsError
+	}
+	payload.Text = Summarize(payload)
+
+	return payload
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *model.Webhook, deliveryID uint, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Premises-Webhook")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(deliveryID), 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, webhook *model.Webhook, payload *web.WebhookPayload) {
+	body, err := Encode(webhook.Format, payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode webhook payload", slog.Any("error", err))
+		return
+	}
+
+	delivery := &model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Payload:   body,
+	}
+	if _, err := s.db.NewInsert().Model(delivery).Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", slog.Any("error", err))
+		return
+	}
+
+	_, err = retry.Retry(ctx, func(ctx context.Context) (retry.Void, error) {
+		delivery.Attempts++
+		delivery.StatusCode, err = s.send(ctx, webhook, delivery.ID, body)
+		return retry.V, err
+	}, deliveryTimeout)
+	delivery.Succeeded = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+		slog.ErrorContext(ctx, "Failed to deliver webhook", slog.Any("error", err), slog.Any("webhook_id", webhook.ID))
+	}
+	delivery.FinishedAt = bun.NullTime{Time: time.Now()}
+
+	if _, err := s.db.NewUpdate().Model(delivery).Column("attempts", "status_code", "error", "succeeded", "finished_at").WherePK().Exec(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", slog.Any("error", err))
+	}
+}
+
+// HandleMessage delivers the message to subscribing webhooks in background.
+// It is meant to be registered to StreamingService as a hook.
+func (s *WebhookService) HandleMessage(ctx context.Context, serverID string, message streaming.Message) {
+	if message.Type != streaming.EventMessage && message.Type != streaming.NotifyMessage {
+		return
+	}
+
+	// Deliveries outlive the request which triggered the message.
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		webhooks := make([]model.Webhook, 0)
+		if err := s.db.NewSelect().Model(&webhooks).Where("enabled").Scan(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to list webhooks", slog.Any("error", err))
+			return
+		}
+
+		var payload *web.WebhookPayload
+		for _, webhook := range webhooks {
+			if !Matches(&webhook, message) {
+				continue
+			}
+			if payload == nil {
+				payload = s.buildPayload(ctx, serverID, message)
+			}
+			go s.deliver(ctx, &webhook, payload)
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook", func() {
+	It("should sign body with HMAC-SHA256", func() {
+		// echo -n 'hello' | openssl dgst -sha256 -hmac secret
+		Expect(Sign("secret", []byte("hello"))).To(Equal("sha256=88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b"))
+	})
+
+	DescribeTable("Matches", func(message streaming.Message, matches bool) {
+		webhook := &model.Webhook{
+			EventCodes: []int{int(entity.EventRunning)},
+			InfoCodes:  []int{int(entity.InfoScheduledLaunch)},
+		}
+		Expect(Matches(webhook, message)).To(Equal(matches))
+	},
+		Entry("subscribed event", streaming.NewStandardMessage(entity.EventRunning, web.PageRunning), true),
+		Entry("unsubscribed event", streaming.NewStandardMessage(entity.EventStopped, web.PageLaunch), false),
+		Entry("subscribed info", streaming.NewInfoMessage(entity.InfoScheduledLaunch, false), true),
+		Entry("unsubscribed info", streaming.NewInfoMessage(entity.InfoSnapshotDone, false), false),
+	)
+
+	DescribeTable("Summarize", func(payload web.WebhookPayload, expected string) {
+		Expect(Summarize(&payload)).To(Equal(expected))
+	},
+		Entry("running", web.WebhookPayload{ServerName: "Survival", Hostname: "mc.example.com", Type: streaming.EventMessage.String(), EventCode: entity.EventRunning}, "Server Survival is up at mc.example.com"),
+		Entry("stopped", web.WebhookPayload{ServerName: "Survival", Hostname: "mc.example.com", Type: streaming.EventMessage.String(), EventCode: entity.EventStopped}, "Server Survival has stopped"),
+		Entry("info", web.WebhookPayload{ServerName: "Survival", Type: streaming.NotifyMessage.String(), InfoCode: entity.InfoScheduledStop}, "Server Survival was stopped by schedule"),
+	)
+
+	DescribeTable("Encode", func(format string, key string) {
+		body, err := Encode(format, &web.WebhookPayload{Text: "Server Survival has stopped"})
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded map[string]any
+		Expect(json.Unmarshal(body, &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKeyWithValue(key, "Server Survival has stopped"))
+	},
+		Entry("json", FormatJSON, "text"),
+		Entry("slack", FormatSlack, "text"),
+		Entry("discord", FormatDiscord, "content"),
+	)
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook Suite")
+}
@@ -0,0 +1,157 @@
+package world
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/manifest"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	"github.com/uptrace/bun"
+)
+
+// FormatManifest is the format of incremental generations in the catalog.
+const FormatManifest = "manifest.json"
+
+var errNotWorld = errors.New("not a world")
+
+// Blobs newer than this are never collected because runners may be uploading a generation which refers to them.
+// Blobs which MissingBlobs reported to exist are leased for the same duration.
+const blobGracePeriod = 24 * time.Hour
+
+func (ws *WorldService) listBlobs(ctx context.Context) (map[string]storage.ObjectMetaData, error) {
+	objs, err := ws.storage.ListObjects(ctx, manifest.BlobPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]storage.ObjectMetaData)
+	for _, obj := range objs {
+		if hash, ok := manifest.HashFromBlobKey(obj.Key); ok {
+			result[hash] = obj
+		}
+	}
+	return result, nil
+}
+
+// leaseBlobs keeps the blobs from being collected for blobGracePeriod.
+func (ws *WorldService) leaseBlobs(ctx context.Context, hashes []string) error {
+	expiresAt := time.Now().Add(blobGracePeriod)
+	seen := make(map[string]bool)
+	var leases []model.BlobLease
+	for _, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		leases = append(leases, model.BlobLease{Hash: hash, ExpiresAt: expiresAt})
+	}
+	if len(leases) == 0 {
+		return nil
+	}
+
+	_, err := ws.db.NewInsert().Model(&leases).
+		On("CONFLICT (hash) DO UPDATE").
+		Set("expires_at = EXCLUDED.expires_at").
+		Exec(ctx)
+	return err
+}
+
+// MissingBlobs returns hashes of blobs which don't exist in the bucket.
+// The other blobs are leased so that they are not collected before the generation which refers to them is uploaded.
+func (ws *WorldService) MissingBlobs(ctx context.Context, hashes []string) ([]string, error) {
+	// Lease before listing, so that CollectGarbage either sees the leases or deletes blobs before they are listed.
+	if err := ws.leaseBlobs(ctx, hashes); err != nil {
+		return nil, err
+	}
+
+	blobs, err := ws.listBlobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, hash := range hashes {
+		if _, ok := blobs[hash]; !ok {
+			result = append(result, hash)
+		}
+	}
+	return result, nil
+}
+
+func (ws *WorldService) readManifest(ctx context.Context, key string) (*manifest.Manifest, error) {
+	obj, err := ws.storage.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Body.Close()
+
+	return manifest.Read(obj.Body)
+}
+
+// CollectGarbage deletes blobs which no manifest refers to.
+func (ws *WorldService) CollectGarbage(ctx context.Context) error {
+	objs, err := ws.storage.ListObjects(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	referenced := make(map[string]bool)
+	for _, obj := range objs {
+		if !strings.HasSuffix(obj.Key, manifest.Extension) {
+			continue
+		}
+		m, err := ws.readManifest(ctx, obj.Key)
+		if err != nil {
+			// Deleting blobs without knowing all references may break generations.
+			return fmt.Errorf("%s: %w", obj.Key, err)
+		}
+		for _, hash := range m.Blobs() {
+			referenced[hash] = true
+		}
+	}
+
+	threshold := time.Now().Add(-blobGracePeriod)
+	var garbage []string
+	for _, obj := range objs {
+		hash, ok := manifest.HashFromBlobKey(obj.Key)
+		if !ok || referenced[hash] || obj.Timestamp.After(threshold) {
+			continue
+		}
+		garbage = append(garbage, obj.Key)
+	}
+	if len(garbage) == 0 {
+		return nil
+	}
+
+	return ws.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Blobs must not be leased until leased ones are excluded from garbage and the rest are deleted.
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE blob_leases IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+		if _, err := tx.NewDelete().Model((*model.BlobLease)(nil)).Where("expires_at < ?", time.Now()).Exec(ctx); err != nil {
+			return err
+		}
+		var hashes []string
+		if err := tx.NewSelect().Model((*model.BlobLease)(nil)).Column("hash").Scan(ctx, &hashes); err != nil {
+			return err
+		}
+		leased := make(map[string]bool)
+		for _, hash := range hashes {
+			leased[hash] = true
+		}
+
+		garbage = slices.DeleteFunc(garbage, func(key string) bool {
+			hash, _ := manifest.HashFromBlobKey(key)
+			return leased[hash]
+		})
+		if len(garbage) == 0 {
+			return nil
+		}
+		return ws.storage.DeleteObjects(ctx, garbage)
+	})
+}
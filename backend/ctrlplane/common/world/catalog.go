@@ -0,0 +1,259 @@
+package world
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	"github.com/uptrace/bun"
+)
+
+const (
+	SortByName    = "name"
+	SortByUpdated = "updated"
+	SortBySize    = "size"
+)
+
+var sortColumns = map[string]string{
+	SortByName:    "world_name",
+	SortByUpdated: "updated_at",
+	SortBySize:    "size",
+}
+
+func IsValidSort(sort string) bool {
+	_, ok := sortColumns[sort]
+	return ok
+}
+
+type ListOptions struct {
+	// Limit is the maximum number of worlds to return. Zero means no limit.
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+}
+
+func newGeneration(obj storage.ObjectMetaData) (*model.WorldGeneration, error) {
+	worldName, gen, err := extractWorldInfoFromKey(obj.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &model.WorldGeneration{
+		Key:       obj.Key,
+		WorldName: worldName,
+		Gen:       gen,
+		Size:      obj.Size,
+		Format:    archiveFormat(obj.Key),
+		CreatedAt: obj.Timestamp,
+	}, nil
+}
+
+func toGenerationEntity(gen *model.WorldGeneration) web.WorldGeneration {
+	result := web.WorldGeneration{
+		Gen:              gen.Gen,
+		ID:               gen.Key,
+		Timestamp:        int(gen.CreatedAt.UnixMilli()),
+		Size:             gen.Size,
+		Format:           gen.Format,
+		MinecraftVersion: gen.MCVersion,
+		CreatedBy:        gen.CreatedBy,
+		Pinned:           gen.Pinned,
+		AutoBackup:       gen.AutoBackup,
+		Corrupt:          gen.Corrupt,
+	}
+	if len(gen.Level) > 0 {
+		var level web.LevelInfo
+		if err := json.Unmarshal(gen.Level, &level); err == nil {
+			result.Level = &level
+		}
+	}
+	return result
+}
+
+// GenerationInfo describes an uploaded generation.
+type GenerationInfo struct {
+	Size       int64
+	MCVersion  string
+	CreatedBy  string
+	AutoBackup bool
+	// Checksum is the hex-encoded SHA-256 of the object.
+	Checksum string
+	// Level is nil if level.dat couldn't be read.
+	Level *web.LevelInfo
+}
+
+// RecordGeneration adds the uploaded object to the catalog.
+func (ws *WorldService) RecordGeneration(ctx context.Context, key string, info GenerationInfo) error {
+	gen, err := newGeneration(storage.ObjectMetaData{
+		Key:       key,
+		Timestamp: time.Now(),
+		Size:      info.Size,
+	})
+	if err != nil {
+		return err
+	}
+	gen.MCVersion = info.MCVersion
+	gen.CreatedBy = info.CreatedBy
+	gen.AutoBackup = info.AutoBackup
+	gen.Checksum = info.Checksum
+	if info.Level != nil {
+		if gen.Level, err = json.Marshal(info.Level); err != nil {
+			return err
+		}
+	}
+
+	_, err = ws.db.NewInsert().Model(gen).
+		On("CONFLICT (key) DO UPDATE").
+		Set("size = EXCLUDED.size").
+		Set("mc_version = EXCLUDED.mc_version").
+		Set("created_by = EXCLUDED.created_by").
+		Set("created_at = EXCLUDED.created_at").
+		Set("auto_backup = EXCLUDED.auto_backup").
+		Set("checksum = EXCLUDED.checksum").
+		Set("level = EXCLUDED.level").
+		Set("corrupt = false").
+		Set("verified_at = NULL").
+		Exec(ctx)
+	return err
+}
+
+func (ws *WorldService) GetGeneration(ctx context.Context, key string) (*model.WorldGeneration, error) {
+	gen := new(model.WorldGeneration)
+	if err := ws.db.NewSelect().Model(gen).Where("key = ?", key).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return gen, nil
+}
+
+func (ws *WorldService) forgetGenerations(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := ws.db.NewDelete().Model((*model.WorldGeneration)(nil)).Where("key IN (?)", bun.In(keys)).Exec(ctx)
+	return err
+}
+
+// ListWorlds returns worlds in the catalog along with the total number of them.
+// Generations of each world are sorted from newest to oldest.
+func (ws *WorldService) ListWorlds(ctx context.Context, opts ListOptions) ([]web.World, int, error) {
+	column, ok := sortColumns[opts.Sort]
+	if !ok {
+		column = sortColumns[SortByName]
+	}
+	order := "ASC"
+	if opts.Desc {
+		order = "DESC"
+	}
+
+	var summaries []struct {
+		WorldName string    `bun:"world_name"`
+		UpdatedAt time.Time `bun:"updated_at"`
+		Size      int64     `bun:"size"`
+	}
+	q := ws.db.NewSelect().Model((*model.WorldGeneration)(nil)).
+		Column("world_name").
+		ColumnExpr("MAX(created_at) AS updated_at").
+		ColumnExpr("SUM(size) AS size").
+		Group("world_name").
+		OrderExpr("? "+order, bun.Ident(column)).
+		OrderExpr("world_name")
+	if opts.Limit > 0 {
+		q = q.Limit(opts.Limit).Offset(opts.Offset)
+	}
+	if err := q.Scan(ctx, &summaries); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := ws.db.NewSelect().Model((*model.WorldGeneration)(nil)).ColumnExpr("COUNT(DISTINCT world_name)").Scan(ctx, &total); err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]web.World, 0, len(summaries))
+	if len(summaries) == 0 {
+		return result, total, nil
+	}
+
+	names := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		names = append(names, s.WorldName)
+	}
+
+	var gens []model.WorldGeneration
+	if err := ws.db.NewSelect().Model(&gens).Where("world_name IN (?)", bun.In(names)).Order("created_at DESC").Scan(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	generations := make(map[string][]web.WorldGeneration)
+	for _, gen := range gens {
+		generations[gen.WorldName] = append(generations[gen.WorldName], toGenerationEntity(&gen))
+	}
+	for _, name := range names {
+		result = append(result, web.World{
+			WorldName:   name,
+			Generations: generations[name],
+		})
+	}
+
+	return result, total, nil
+}
+
+// Reconcile synchronizes the catalog with objects in the bucket.
+func (ws *WorldService) Reconcile(ctx context.Context) error {
+	// Read the catalog before listing objects. Generations are recorded after they are uploaded,
+	// so ones recorded while listing are not forgotten even if the listing doesn't include them.
+	var gens []model.WorldGeneration
+	if err := ws.db.NewSelect().Model(&gens).Column("id", "key", "size").Scan(ctx); err != nil {
+		return err
+	}
+
+	objs, err := ws.storage.ListObjects(ctx, "")
+	if err != nil {
+		return err
+	}
+	known := make(map[string]*model.WorldGeneration)
+	for i := range gens {
+		known[gens[i].Key] = &gens[i]
+	}
+
+	var missing []*model.WorldGeneration
+	for _, obj := range objs {
+		if gen, ok := known[obj.Key]; ok {
+			delete(known, obj.Key)
+			if gen.Size != obj.Size {
+				gen.Size = obj.Size
+				if _, err := ws.db.NewUpdate().Model(gen).Column("size").WherePK().Exec(ctx); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		gen, err := newGeneration(obj)
+		if err != nil {
+			// Not a world archive.
+			continue
+		}
+		missing = append(missing, gen)
+	}
+
+	if len(missing) > 0 {
+		if _, err := ws.db.NewInsert().Model(&missing).On("CONFLICT (key) DO NOTHING").Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	var removed []string
+	for key := range known {
+		removed = append(removed, key)
+	}
+	return ws.forgetGenerations(ctx, removed)
+}
@@ -0,0 +1,46 @@
+package world
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+)
+
+// CrashReportPrefix is the prefix of crash reports which runners upload when the server crashes.
+const CrashReportPrefix = "@crashreports/"
+
+// Crash reports older than this are deleted on prune. The UI doesn't show them either.
+const crashReportRetention = 30 * 24 * time.Hour
+
+// CrashReportKey returns the key of a new crash report of the server.
+// The key has a random suffix so that crashes within the same second don't overwrite each other.
+func CrashReportKey(serverID string, t time.Time) string {
+	return CrashReportPrefix + serverID + "/" + t.UTC().Format("20060102-150405") + "-" + strings.ToLower(rand.Text()[:8]) + ".txt"
+}
+
+// IsCrashReportOf reports whether key is a crash report of the server.
+func IsCrashReportOf(key, serverID string) bool {
+	name, ok := strings.CutPrefix(key, CrashReportPrefix+serverID+"/")
+	return ok && name != "" && !strings.ContainsRune(name, '/')
+}
+
+func (ws *WorldService) pruneCrashReports(ctx context.Context) error {
+	objs, err := ws.storage.ListObjects(ctx, CrashReportPrefix)
+	if err != nil {
+		return err
+	}
+
+	threshold := time.Now().Add(-crashReportRetention)
+	var expired []string
+	for _, obj := range objs {
+		if obj.Timestamp.Before(threshold) {
+			expired = append(expired, obj.Key)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	return ws.storage.DeleteObjects(ctx, expired)
+}
@@ -0,0 +1,50 @@
+package world
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CrashReport", func() {
+	It("should build keys of crash reports of the server", func() {
+		t := time.Date(2026, 10, 17, 12, 34, 56, 0, time.UTC)
+		key := CrashReportKey("default", t)
+		Expect(key).To(MatchRegexp(`^@crashreports/default/20261017-123456-[a-z2-7]{8}\.txt$`))
+		Expect(CrashReportKey("default", t)).NotTo(Equal(key))
+
+		Expect(IsCrashReportOf(key, "default")).To(BeTrue())
+		Expect(IsCrashReportOf(key, "other")).To(BeFalse())
+		Expect(IsCrashReportOf("@crashreports/default/", "default")).To(BeFalse())
+		Expect(IsCrashReportOf("@crashreports/default/nested/a.txt", "default")).To(BeFalse())
+		Expect(IsCrashReportOf("default/world.tar.zst", "default")).To(BeFalse())
+	})
+
+	It("should not treat crash reports as worlds", func() {
+		_, _, err := extractWorldInfoFromKey("@crashreports/default/20261017-123456.txt")
+		Expect(err).To(MatchError(errNotWorld))
+	})
+
+	It("should prune expired crash reports", func() {
+		root := GinkgoT().TempDir()
+		store, err := storage.NewLocalStorage(root, "http://localhost", []byte("secret"))
+		Expect(err).ShouldNot(HaveOccurred())
+		ws := New(nil, store)
+
+		Expect(os.MkdirAll(filepath.Join(root, "@crashreports", "default"), 0o755)).To(Succeed())
+		expired := filepath.Join(root, "@crashreports", "default", "expired.txt")
+		recent := filepath.Join(root, "@crashreports", "default", "recent.txt")
+		Expect(os.WriteFile(expired, []byte("crash"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(recent, []byte("crash"), 0o644)).To(Succeed())
+		old := time.Now().Add(-crashReportRetention - time.Hour)
+		Expect(os.Chtimes(expired, old, old)).To(Succeed())
+
+		Expect(ws.pruneCrashReports(GinkgoT().Context())).To(Succeed())
+		Expect(expired).NotTo(BeAnExistingFile())
+		Expect(recent).To(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,289 @@
+package world
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	"github.com/uptrace/bun"
+)
+
+// DatapackPrefix is the prefix of datapacks which can be attached to worlds.
+const DatapackPrefix = "@datapacks/"
+
+const (
+	// MaxDatapackSize is the maximum size of datapacks which users upload.
+	MaxDatapackSize int64 = 64 << 20
+
+	maxPackMetaSize = 1 << 20
+)
+
+var (
+	ErrInvalidDatapack  = errors.New("invalid datapack")
+	ErrDatapackNotFound = errors.New("datapack not found")
+)
+
+func invalidDatapack(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidDatapack, fmt.Sprintf(format, args...))
+}
+
+// IsValidDatapackName reports whether name can be used as a datapack name.
+// Datapacks are installed as <name>.zip and enabled with `datapack enable "file/<name>.zip"`.
+func IsValidDatapackName(name string) bool {
+	return IsValidWorldName(name) && !strings.HasPrefix(name, ".") && !strings.ContainsRune(name, '"')
+}
+
+func datapackKey(name string) string {
+	return DatapackPrefix + name + ".zip"
+}
+
+// DatapackUploadKey returns the key which users upload the datapack to. It is moved under DatapackPrefix after validation.
+func DatapackUploadKey(name string) string {
+	return UploadPrefix + datapackKey(name)
+}
+
+func datapackName(key string) (string, bool) {
+	name, ok := strings.CutPrefix(key, DatapackPrefix)
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, ".zip")
+	return name, ok && IsValidDatapackName(name)
+}
+
+// validateDatapack checks that data is a ZIP archive which has pack.mcmeta at its root.
+func validateDatapack(data []byte) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return invalidDatapack("not a ZIP archive: %v", err)
+	}
+
+	found := false
+	for _, file := range zipReader.File {
+		name, err := cleanEntryName(file.Name)
+		if err != nil {
+			return invalidDatapack("unsafe path: %s", file.Name)
+		}
+		if name != "pack.mcmeta" || file.FileInfo().IsDir() {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return invalidDatapack("unable to read pack.mcmeta: %v", err)
+		}
+		var meta struct {
+			Pack json.RawMessage `json:"pack"`
+		}
+		err = json.NewDecoder(io.LimitReader(r, maxPackMetaSize)).Decode(&meta)
+		r.Close()
+		if err != nil || len(meta.Pack) == 0 || meta.Pack[0] != '{' {
+			return invalidDatapack("pack.mcmeta doesn't describe a pack")
+		}
+		found = true
+	}
+	if !found {
+		return invalidDatapack("pack.mcmeta is not at the root of the archive")
+	}
+	return nil
+}
+
+func (ws *WorldService) ListDatapacks(ctx context.Context) ([]web.Datapack, error) {
+	objs, err := ws.storage.ListObjects(ctx, DatapackPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]web.Datapack, 0, len(objs))
+	for _, obj := range objs {
+		name, ok := datapackName(obj.Key)
+		if !ok {
+			continue
+		}
+		result = append(result, web.Datapack{
+			Name:      name,
+			Size:      obj.Size,
+			Timestamp: int(obj.Timestamp.UnixMilli()),
+		})
+	}
+	slices.SortFunc(result, func(a, b web.Datapack) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+// CompleteDatapackUpload validates the datapack which a user uploaded to key, and makes it available to worlds.
+// A datapack with the same name is replaced. Invalid datapacks are deleted.
+func (ws *WorldService) CompleteDatapackUpload(ctx context.Context, key string) (*web.Datapack, error) {
+	dst, ok := strings.CutPrefix(key, UploadPrefix)
+	if !ok {
+		return nil, ErrDatapackNotFound
+	}
+	name, ok := datapackName(dst)
+	if !ok {
+		return nil, ErrDatapackNotFound
+	}
+
+	obj, err := ws.storage.GetObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrDatapackNotFound
+		}
+		return nil, err
+	}
+	defer obj.Body.Close()
+
+	if obj.Size > MaxDatapackSize {
+		err = invalidDatapack("datapack is larger than %d bytes", MaxDatapackSize)
+	} else {
+		data, readErr := io.ReadAll(io.LimitReader(obj.Body, MaxDatapackSize+1))
+		if readErr != nil {
+			return nil, readErr
+		}
+		if int64(len(data)) > MaxDatapackSize {
+			err = invalidDatapack("datapack is larger than %d bytes", MaxDatapackSize)
+		} else {
+			err = validateDatapack(data)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidDatapack) {
+			if err := ws.storage.DeleteObjects(ctx, []string{key}); err != nil {
+				slog.ErrorContext(ctx, "Unable to delete invalid datapack", slog.String("key", key), slog.Any("error", err))
+			}
+		}
+		return nil, err
+	}
+
+	if err := ws.storage.CopyObject(ctx, key, dst); err != nil {
+		return nil, err
+	}
+	if err := ws.storage.DeleteObjects(ctx, []string{key}); err != nil {
+		// It'll be deleted on prune.
+		slog.ErrorContext(ctx, "Unable to delete uploaded datapack", slog.String("key", key), slog.Any("error", err))
+	}
+
+	return &web.Datapack{
+		Name:      name,
+		Size:      obj.Size,
+		Timestamp: int(time.Now().UnixMilli()),
+	}, nil
+}
+
+func (ws *WorldService) datapackExists(ctx context.Context, name string) (bool, error) {
+	objs, err := ws.storage.ListObjects(ctx, datapackKey(name))
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(objs, func(obj storage.ObjectMetaData) bool {
+		return obj.Key == datapackKey(name)
+	}), nil
+}
+
+// DeleteDatapack deletes the datapack and detaches it from all worlds.
+// Worlds which were launched with it keep their installed copy.
+func (ws *WorldService) DeleteDatapack(ctx context.Context, name string) error {
+	if !IsValidDatapackName(name) {
+		return ErrDatapackNotFound
+	}
+	exists, err := ws.datapackExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrDatapackNotFound
+	}
+
+	if _, err := ws.db.NewDelete().Model((*model.WorldDatapack)(nil)).Where("name = ?", name).Exec(ctx); err != nil {
+		return err
+	}
+	return ws.storage.DeleteObjects(ctx, []string{datapackKey(name)})
+}
+
+// GetWorldDatapacks returns names of datapacks attached to the world.
+func (ws *WorldService) GetWorldDatapacks(ctx context.Context, worldName string) ([]string, error) {
+	names := []string{}
+	if err := ws.db.NewSelect().Model((*model.WorldDatapack)(nil)).Column("name").Where("world_name = ?", worldName).Order("name").Scan(ctx, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// SetWorldDatapacks replaces datapacks attached to the world.
+// The world doesn't need to exist yet, so that new worlds can be generated with datapacks.
+func (ws *WorldService) SetWorldDatapacks(ctx context.Context, worldName string, names []string) error {
+	if !IsValidWorldName(worldName) {
+		return ErrInvalidWorldName
+	}
+
+	available, err := ws.ListDatapacks(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([]model.WorldDatapack, 0, len(names))
+	for _, name := range names {
+		if !slices.ContainsFunc(available, func(d web.Datapack) bool { return d.Name == name }) {
+			return fmt.Errorf("%w: %s", ErrDatapackNotFound, name)
+		}
+		if slices.ContainsFunc(rows, func(row model.WorldDatapack) bool { return row.Name == name }) {
+			continue
+		}
+		rows = append(rows, model.WorldDatapack{WorldName: worldName, Name: name})
+	}
+
+	return ws.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().Model((*model.WorldDatapack)(nil)).Where("world_name = ?", worldName).Exec(ctx); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		_, err := tx.NewInsert().Model(&rows).Exec(ctx)
+		return err
+	})
+}
+
+// GetDatapackDownloads returns URLs to download datapacks attached to the world.
+func (ws *WorldService) GetDatapackDownloads(ctx context.Context, worldName string) ([]web.DatapackDownload, error) {
+	names, err := ws.GetWorldDatapacks(ctx, worldName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]web.DatapackDownload, 0, len(names))
+	for _, name := range names {
+		url, err := ws.GetPresignedGetURL(ctx, datapackKey(name))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, web.DatapackDownload{Name: name, URL: url})
+	}
+	return result, nil
+}
+
+func copyDatapacks(ctx context.Context, tx bun.Tx, src, dst string) error {
+	var rows []model.WorldDatapack
+	if err := tx.NewSelect().Model(&rows).Where("world_name = ?", src).Scan(ctx); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].WorldName = dst
+		rows[i].CreatedAt = time.Time{}
+	}
+	_, err := tx.NewInsert().Model(&rows).Exec(ctx)
+	return err
+}
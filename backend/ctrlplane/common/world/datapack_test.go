@@ -0,0 +1,51 @@
+package world
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Datapack", func() {
+	packMeta := []byte(`{"pack": {"pack_format": 48, "description": "Sample"}}`)
+
+	It("should accept datapacks with pack.mcmeta at the root", func() {
+		archive := createZip(
+			testEntry{name: "pack.mcmeta", content: packMeta},
+			testEntry{name: "data/sample/function/hello.mcfunction", content: []byte("say hello")},
+		)
+		Expect(validateDatapack(archive)).To(Succeed())
+	})
+
+	DescribeTable("should reject invalid datapacks", func(archive []byte) {
+		Expect(validateDatapack(archive)).To(MatchError(ErrInvalidDatapack))
+	},
+		Entry("not a zip", []byte("datapack")),
+		Entry("no pack.mcmeta", createZip(testEntry{name: "data/sample/function/hello.mcfunction", content: []byte("say hello")})),
+		Entry("nested pack.mcmeta", createZip(testEntry{name: "sample/pack.mcmeta", content: packMeta})),
+		Entry("broken pack.mcmeta", createZip(testEntry{name: "pack.mcmeta", content: []byte(`{"pack": 1}`)})),
+		Entry("path traversal", createZip(
+			testEntry{name: "pack.mcmeta", content: packMeta},
+			testEntry{name: "../evil", content: []byte("evil")},
+		)),
+	)
+
+	DescribeTable("should validate datapack names", func(name string, valid bool) {
+		Expect(IsValidDatapackName(name)).To(Equal(valid))
+	},
+		Entry("simple", "sample", true),
+		Entry("with spaces", "sample pack", true),
+		Entry("hidden", ".sample", false),
+		Entry("with quote", `sample"`, false),
+		Entry("with slash", "sample/pack", false),
+		Entry("empty", "", false),
+	)
+
+	It("should extract names from keys", func() {
+		name, ok := datapackName("@datapacks/sample.zip")
+		Expect(ok).To(BeTrue())
+		Expect(name).To(Equal("sample"))
+
+		_, ok = datapackName("@datapacks/sample.tar.gz")
+		Expect(ok).To(BeFalse())
+	})
+})
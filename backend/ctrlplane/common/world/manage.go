@@ -0,0 +1,257 @@
+package world
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrWorldExists      = errors.New("world already exists")
+	ErrInvalidWorldName = errors.New("invalid world name")
+)
+
+func IsValidWorldName(name string) bool {
+	return name != "" && name != "." && name != ".." && len(name) <= 255 && !strings.ContainsAny(name, "@/\\")
+}
+
+// ensureNewWorld fails if name can't be used for a new world.
+func (ws *WorldService) ensureNewWorld(ctx context.Context, name string) error {
+	if !IsValidWorldName(name) {
+		return ErrInvalidWorldName
+	}
+	objs, err := ws.storage.ListObjects(ctx, name+"/")
+	if err != nil {
+		return err
+	}
+	if len(objs) > 0 {
+		return ErrWorldExists
+	}
+	return nil
+}
+
+func (ws *WorldService) listWorldObjects(ctx context.Context, worldName string) ([]storage.ObjectMetaData, error) {
+	if !IsValidWorldName(worldName) {
+		return nil, ErrNotFound
+	}
+	objs, err := ws.storage.ListObjects(ctx, worldName+"/")
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, ErrNotFound
+	}
+	return objs, nil
+}
+
+func (ws *WorldService) deleteCopies(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if err := ws.storage.DeleteObjects(ctx, keys); err != nil {
+		slog.ErrorContext(ctx, "Unable to delete copied generations", slog.Any("error", err))
+	}
+}
+
+// copyGenerations copies objects to the world dst, and returns catalog entries for the copies.
+// Metadata of the generations is inherited from the catalog. Incremental generations share blobs with the originals.
+func (ws *WorldService) copyGenerations(ctx context.Context, objs []storage.ObjectMetaData, dst string) ([]model.WorldGeneration, error) {
+	keys := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		keys = append(keys, obj.Key)
+	}
+	var known []model.WorldGeneration
+	if err := ws.db.NewSelect().Model(&known).Where("key IN (?)", bun.In(keys)).Scan(ctx); err != nil {
+		return nil, err
+	}
+	catalog := make(map[string]model.WorldGeneration)
+	for _, gen := range known {
+		catalog[gen.Key] = gen
+	}
+
+	var copied []string
+	gens := make([]model.WorldGeneration, 0, len(objs))
+	for _, obj := range objs {
+		_, name, ok := strings.Cut(obj.Key, "/")
+		if !ok {
+			continue
+		}
+		newKey := dst + "/" + name
+
+		var gen model.WorldGeneration
+		if src, ok := catalog[obj.Key]; ok {
+			gen = src
+			gen.ID = 0
+			gen.Key = newKey
+			gen.WorldName = dst
+		} else {
+			newGen, err := newGeneration(storage.ObjectMetaData{Key: newKey, Timestamp: obj.Timestamp, Size: obj.Size})
+			if err != nil {
+				continue
+			}
+			gen = *newGen
+		}
+
+		if err := ws.storage.CopyObject(ctx, obj.Key, newKey); err != nil {
+			ws.deleteCopies(ctx, copied)
+			return nil, fmt.Errorf("failed to copy %s: %w", obj.Key, err)
+		}
+		copied = append(copied, newKey)
+		gens = append(gens, gen)
+	}
+
+	return gens, nil
+}
+
+func copyRetentionPolicy(ctx context.Context, tx bun.Tx, src, dst string) error {
+	policy := new(model.WorldRetentionPolicy)
+	if err := tx.NewSelect().Model(policy).Where("world_name = ?", src).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	policy.WorldName = dst
+	_, err := tx.NewInsert().Model(policy).Exec(ctx)
+	return err
+}
+
+func keysOf(gens []model.WorldGeneration) []string {
+	keys := make([]string, 0, len(gens))
+	for _, gen := range gens {
+		keys = append(keys, gen.Key)
+	}
+	return keys
+}
+
+// RenameWorld moves all generations of the world to a new name, along with its retention policy and datapacks.
+func (ws *WorldService) RenameWorld(ctx context.Context, worldName, newName string) error {
+	objs, err := ws.listWorldObjects(ctx, worldName)
+	if err != nil {
+		return err
+	}
+	if err := ws.ensureNewWorld(ctx, newName); err != nil {
+		return err
+	}
+
+	gens, err := ws.copyGenerations(ctx, objs, newName)
+	if err != nil {
+		return err
+	}
+
+	err = ws.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().Model((*model.WorldGeneration)(nil)).Where("world_name = ?", worldName).Exec(ctx); err != nil {
+			return err
+		}
+		if len(gens) > 0 {
+			if _, err := tx.NewInsert().Model(&gens).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.NewUpdate().Model((*model.WorldRetentionPolicy)(nil)).Set("world_name = ?", newName).Where("world_name = ?", worldName).Exec(ctx); err != nil {
+			return err
+		}
+		_, err := tx.NewUpdate().Model((*model.WorldDatapack)(nil)).Set("world_name = ?", newName).Where("world_name = ?", worldName).Exec(ctx)
+		return err
+	})
+	if err != nil {
+		ws.deleteCopies(ctx, keysOf(gens))
+		return err
+	}
+
+	keys := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		keys = append(keys, obj.Key)
+	}
+	if err := ws.storage.DeleteObjects(ctx, keys); err != nil {
+		return fmt.Errorf("world was copied, but failed to delete the original: %w", err)
+	}
+	return nil
+}
+
+// DuplicateWorld copies all generations of the world to a new name, along with its retention policy and datapacks.
+func (ws *WorldService) DuplicateWorld(ctx context.Context, worldName, newName string) error {
+	objs, err := ws.listWorldObjects(ctx, worldName)
+	if err != nil {
+		return err
+	}
+	if err := ws.ensureNewWorld(ctx, newName); err != nil {
+		return err
+	}
+
+	gens, err := ws.copyGenerations(ctx, objs, newName)
+	if err != nil {
+		return err
+	}
+
+	err = ws.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(gens) > 0 {
+			if _, err := tx.NewInsert().Model(&gens).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		if err := copyRetentionPolicy(ctx, tx, worldName, newName); err != nil {
+			return err
+		}
+		return copyDatapacks(ctx, tx, worldName, newName)
+	})
+	if err != nil {
+		ws.deleteCopies(ctx, keysOf(gens))
+		return err
+	}
+	return nil
+}
+
+// ForkWorld creates a new world which starts from the generation. Datapacks attached to the world are attached to the fork.
+func (ws *WorldService) ForkWorld(ctx context.Context, key, newName string) error {
+	worldName, _, err := extractWorldInfoFromKey(key)
+	if err != nil {
+		return ErrNotFound
+	}
+	objs, err := ws.listWorldObjects(ctx, worldName)
+	if err != nil {
+		return err
+	}
+	var target []storage.ObjectMetaData
+	for _, obj := range objs {
+		if obj.Key == key {
+			target = append(target, obj)
+		}
+	}
+	if len(target) == 0 {
+		return ErrNotFound
+	}
+	if err := ws.ensureNewWorld(ctx, newName); err != nil {
+		return err
+	}
+
+	gens, err := ws.copyGenerations(ctx, target, newName)
+	if err != nil {
+		return err
+	}
+	for i := range gens {
+		// Pins are for the original world.
+		gens[i].Pinned = false
+	}
+
+	err = ws.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(gens) > 0 {
+			if _, err := tx.NewInsert().Model(&gens).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return copyDatapacks(ctx, tx, worldName, newName)
+	})
+	if err != nil {
+		ws.deleteCopies(ctx, keysOf(gens))
+		return err
+	}
+	return nil
+}
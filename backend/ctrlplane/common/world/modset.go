@@ -0,0 +1,210 @@
+package world
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+)
+
+// ModSetPrefix is the prefix of files in mod sets, which are installed into mods or plugins of the server.
+const ModSetPrefix = "@modsets/"
+
+// MaxModSetFileSize is the maximum size of mods and plugins which users upload.
+const MaxModSetFileSize int64 = 256 << 20
+
+var (
+	ErrInvalidModSetFile = errors.New("invalid mod set file")
+	ErrModSetNotFound    = errors.New("mod set not found")
+)
+
+// IsValidModSetName reports whether name can be used as a mod set name.
+func IsValidModSetName(name string) bool {
+	return IsValidWorldName(name) && !strings.HasPrefix(name, ".")
+}
+
+// IsValidModSetFileName reports whether name can be used as a file name in mod sets.
+// Servers load mods and plugins only from JAR files.
+func IsValidModSetFileName(name string) bool {
+	return IsValidWorldName(name) && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".jar")
+}
+
+func modSetFileKey(modSet, name string) string {
+	return ModSetPrefix + modSet + "/" + name
+}
+
+// ModSetUploadKey returns the key which users upload the file to. It is moved under ModSetPrefix after validation.
+func ModSetUploadKey(modSet, name string) string {
+	return UploadPrefix + modSetFileKey(modSet, name)
+}
+
+func parseModSetFileKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, ModSetPrefix)
+	if !ok {
+		return "", "", false
+	}
+	modSet, name, ok := strings.Cut(rest, "/")
+	return modSet, name, ok && IsValidModSetName(modSet) && IsValidModSetFileName(name)
+}
+
+var zipSignature = []byte{0x50, 0x4b, 0x03, 0x04}
+
+func toModSetFileEntity(file *model.ModSetFile) web.ModSetFile {
+	return web.ModSetFile{
+		Name:      file.Name,
+		Size:      file.Size,
+		Sha256:    file.Sha256,
+		Timestamp: int(file.CreatedAt.UnixMilli()),
+	}
+}
+
+// ListModSets returns all mod sets with their files.
+func (ws *WorldService) ListModSets(ctx context.Context) ([]web.ModSet, error) {
+	var files []model.ModSetFile
+	if err := ws.db.NewSelect().Model(&files).Order("mod_set", "name").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	result := []web.ModSet{}
+	for _, file := range files {
+		if len(result) == 0 || result[len(result)-1].Name != file.ModSet {
+			result = append(result, web.ModSet{Name: file.ModSet, Files: []web.ModSetFile{}})
+		}
+		last := &result[len(result)-1]
+		last.Files = append(last.Files, toModSetFileEntity(&file))
+	}
+	return result, nil
+}
+
+// CompleteModSetUpload validates the file which a user uploaded to key, and adds it to the mod set.
+// A file with the same name is replaced. Invalid files are deleted.
+func (ws *WorldService) CompleteModSetUpload(ctx context.Context, key string) (*web.ModSetFile, error) {
+	dst, ok := strings.CutPrefix(key, UploadPrefix)
+	if !ok {
+		return nil, ErrModSetNotFound
+	}
+	modSet, name, ok := parseModSetFileKey(dst)
+	if !ok {
+		return nil, ErrModSetNotFound
+	}
+
+	obj, err := ws.storage.GetObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrModSetNotFound
+		}
+		return nil, err
+	}
+	defer obj.Body.Close()
+
+	header := make([]byte, len(zipSignature))
+	n, _ := io.ReadFull(obj.Body, header)
+	hash := sha256.New()
+	hash.Write(header[:n])
+	size, readErr := io.Copy(hash, io.LimitReader(obj.Body, MaxModSetFileSize+1-int64(n)))
+	if readErr != nil {
+		return nil, readErr
+	}
+	size += int64(n)
+	if size > MaxModSetFileSize {
+		err = fmt.Errorf("%w: file is larger than %d bytes", ErrInvalidModSetFile, MaxModSetFileSize)
+	} else if !bytes.Equal(header, zipSignature) {
+		err = fmt.Errorf("%w: not a JAR file", ErrInvalidModSetFile)
+	}
+	if err != nil {
+		if err := ws.storage.DeleteObjects(ctx, []string{key}); err != nil {
+			slog.ErrorContext(ctx, "Unable to delete invalid mod set file", slog.String("key", key), slog.Any("error", err))
+		}
+		return nil, err
+	}
+
+	if err := ws.storage.CopyObject(ctx, key, dst); err != nil {
+		return nil, err
+	}
+	if err := ws.storage.DeleteObjects(ctx, []string{key}); err != nil {
+		// It'll be deleted on prune.
+		slog.ErrorContext(ctx, "Unable to delete uploaded mod set file", slog.String("key", key), slog.Any("error", err))
+	}
+
+	file := &model.ModSetFile{
+		ModSet:    modSet,
+		Name:      name,
+		Size:      size,
+		Sha256:    hex.EncodeToString(hash.Sum(nil)),
+		CreatedAt: time.Now(),
+	}
+	_, err = ws.db.NewInsert().Model(file).
+		On("CONFLICT (mod_set, name) DO UPDATE").
+		Set("size = EXCLUDED.size").
+		Set("sha256 = EXCLUDED.sha256").
+		Set("created_at = EXCLUDED.created_at").
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toModSetFileEntity(file)
+	return &result, nil
+}
+
+// DeleteModSetFile deletes the file from the mod set. The mod set disappears when its last file is deleted.
+func (ws *WorldService) DeleteModSetFile(ctx context.Context, modSet, name string) error {
+	res, err := ws.db.NewDelete().Model((*model.ModSetFile)(nil)).Where("mod_set = ? AND name = ?", modSet, name).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrModSetNotFound
+	}
+	return ws.storage.DeleteObjects(ctx, []string{modSetFileKey(modSet, name)})
+}
+
+// DeleteModSet deletes the mod set with all its files.
+func (ws *WorldService) DeleteModSet(ctx context.Context, modSet string) error {
+	var names []string
+	if err := ws.db.NewSelect().Model((*model.ModSetFile)(nil)).Column("name").Where("mod_set = ?", modSet).Scan(ctx, &names); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return ErrModSetNotFound
+	}
+
+	if _, err := ws.db.NewDelete().Model((*model.ModSetFile)(nil)).Where("mod_set = ?", modSet).Exec(ctx); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, modSetFileKey(modSet, name))
+	}
+	return ws.storage.DeleteObjects(ctx, keys)
+}
+
+// GetModSetDownloads returns files in the mod set with URLs to download them.
+// Unknown mod sets are empty, so that the runner removes files installed from them.
+func (ws *WorldService) GetModSetDownloads(ctx context.Context, modSet string) ([]web.ModSetFileDownload, error) {
+	var files []model.ModSetFile
+	if err := ws.db.NewSelect().Model(&files).Where("mod_set = ?", modSet).Order("name").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	result := make([]web.ModSetFileDownload, 0, len(files))
+	for _, file := range files {
+		url, err := ws.GetPresignedGetURL(ctx, modSetFileKey(modSet, file.Name))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, web.ModSetFileDownload{Name: file.Name, URL: url, Sha256: file.Sha256})
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package world
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ModSet", func() {
+	DescribeTable("should validate file names", func(name string, valid bool) {
+		Expect(IsValidModSetFileName(name)).To(Equal(valid))
+	},
+		Entry("jar", "sodium-fabric-0.6.0.jar", true),
+		Entry("not a jar", "readme.txt", false),
+		Entry("hidden", ".sample.jar", false),
+		Entry("with slash", "mods/sample.jar", false),
+		Entry("empty", "", false),
+	)
+
+	It("should parse keys of files", func() {
+		modSet, name, ok := parseModSetFileKey("@modsets/sample/sodium.jar")
+		Expect(ok).To(BeTrue())
+		Expect(modSet).To(Equal("sample"))
+		Expect(name).To(Equal("sodium.jar"))
+
+		_, _, ok = parseModSetFileKey("@modsets/sample/nested/sodium.jar")
+		Expect(ok).To(BeFalse())
+
+		_, _, ok = parseModSetFileKey("@datapacks/sample.zip")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should not treat mod sets as worlds", func() {
+		_, _, err := extractWorldInfoFromKey("@modsets/sample/sodium.jar")
+		Expect(err).To(MatchError(errNotWorld))
+	})
+})
@@ -0,0 +1,188 @@
+package world
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+)
+
+const maxRetentionCount = 1000
+
+var ErrNotFound = errors.New("world generation not found")
+
+// DefaultRetentionPolicy is applied to worlds without their own policy.
+var DefaultRetentionPolicy = model.WorldRetentionPolicy{
+	KeepLast: 3,
+}
+
+func IsValidRetentionPolicy(policy *model.WorldRetentionPolicy) bool {
+	// Keep at least one generation so that the latest world is never lost.
+	if policy.KeepLast < 1 || maxRetentionCount < policy.KeepLast {
+		return false
+	}
+	if policy.KeepDaily < 0 || maxRetentionCount < policy.KeepDaily {
+		return false
+	}
+	if policy.KeepWeekly < 0 || maxRetentionCount < policy.KeepWeekly {
+		return false
+	}
+	return true
+}
+
+// keepNewestInBuckets marks the newest generation in each of the most recent count buckets.
+// Buckets without generations are not counted, and corrupt generations don't fill buckets.
+func keepNewestInBuckets(gens []model.WorldGeneration, keep []bool, count int, bucket func(time.Time) string) {
+	seen := make(map[string]bool)
+	for i, gen := range gens {
+		if gen.Corrupt {
+			continue
+		}
+		key := bucket(gen.CreatedAt.UTC())
+		if seen[key] {
+			continue
+		}
+		if len(seen) == count {
+			break
+		}
+		seen[key] = true
+		keep[i] = true
+	}
+}
+
+// selectExpired returns generations which the policy doesn't retain.
+// gens must be sorted from newest to oldest. Days and weeks are counted in UTC.
+// Corrupt generations are not counted as retained ones, so that they don't push out the ones which can be restored.
+func selectExpired(gens []model.WorldGeneration, policy *model.WorldRetentionPolicy) []model.WorldGeneration {
+	keep := make([]bool, len(gens))
+	kept := 0
+	for i, gen := range gens {
+		if !gen.Corrupt && kept < policy.KeepLast {
+			keep[i] = true
+			kept++
+		}
+		if gen.Pinned {
+			keep[i] = true
+		}
+	}
+
+	keepNewestInBuckets(gens, keep, policy.KeepDaily, func(t time.Time) string {
+		return t.Format(time.DateOnly)
+	})
+	keepNewestInBuckets(gens, keep, policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+
+	var expired []model.WorldGeneration
+	for i, gen := range gens {
+		if !keep[i] {
+			expired = append(expired, gen)
+		}
+	}
+	return expired
+}
+
+// GetRetentionPolicy returns the retention policy of the world, or the default one if the world doesn't have it.
+func (ws *WorldService) GetRetentionPolicy(ctx context.Context, worldName string) (*model.WorldRetentionPolicy, bool, error) {
+	policy := new(model.WorldRetentionPolicy)
+	if err := ws.db.NewSelect().Model(policy).Where("world_name = ?", worldName).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			policy := DefaultRetentionPolicy
+			policy.WorldName = worldName
+			return &policy, true, nil
+		}
+		return nil, false, err
+	}
+	return policy, false, nil
+}
+
+func (ws *WorldService) SetRetentionPolicy(ctx context.Context, policy *model.WorldRetentionPolicy) error {
+	policy.UpdatedAt = time.Now()
+	_, err := ws.db.NewInsert().Model(policy).
+		On("CONFLICT (world_name) DO UPDATE").
+		Set("keep_last = EXCLUDED.keep_last").
+		Set("keep_daily = EXCLUDED.keep_daily").
+		Set("keep_weekly = EXCLUDED.keep_weekly").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	return err
+}
+
+// SetPinned pins or unpins the generation. Pinned generations are never pruned.
+func (ws *WorldService) SetPinned(ctx context.Context, key string, pinned bool) error {
+	result, err := ws.db.NewUpdate().Model((*model.WorldGeneration)(nil)).Set("pinned = ?", pinned).Where("key = ?", key).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PlanPrune returns generations that Prune would delete, grouped by world name.
+func (ws *WorldService) PlanPrune(ctx context.Context) (map[string][]model.WorldGeneration, error) {
+	var gens []model.WorldGeneration
+	if err := ws.db.NewSelect().Model(&gens).Order("world_name", "created_at DESC").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	var policies []model.WorldRetentionPolicy
+	if err := ws.db.NewSelect().Model(&policies).Scan(ctx); err != nil {
+		return nil, err
+	}
+	policyByWorld := make(map[string]*model.WorldRetentionPolicy)
+	for i := range policies {
+		policyByWorld[policies[i].WorldName] = &policies[i]
+	}
+
+	grouped := make(map[string][]model.WorldGeneration)
+	for _, gen := range gens {
+		grouped[gen.WorldName] = append(grouped[gen.WorldName], gen)
+	}
+
+	result := make(map[string][]model.WorldGeneration)
+	for worldName, gens := range grouped {
+		policy, ok := policyByWorld[worldName]
+		if !ok {
+			policy = &DefaultRetentionPolicy
+		}
+		if expired := selectExpired(gens, policy); len(expired) > 0 {
+			result[worldName] = expired
+		}
+	}
+
+	return result, nil
+}
+
+// PreviewPrune returns generations that Prune would delete without deleting them.
+func (ws *WorldService) PreviewPrune(ctx context.Context) ([]web.PrunedWorld, error) {
+	plan, err := ws.PlanPrune(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]web.PrunedWorld, 0, len(plan))
+	for worldName, gens := range plan {
+		pruned := web.PrunedWorld{
+			WorldName:   worldName,
+			Generations: make([]web.WorldGeneration, 0, len(gens)),
+		}
+		for _, gen := range gens {
+			pruned.Generations = append(pruned.Generations, toGenerationEntity(&gen))
+		}
+		result = append(result, pruned)
+	}
+	slices.SortFunc(result, func(a, b web.PrunedWorld) int {
+		return strings.Compare(a.WorldName, b.WorldName)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Uploaded files are written here first, and renamed to the final path when they are complete.
+const localTmpDir = ".tmp"
+
+var errInvalidKey = errors.New("invalid key")
+
+// LocalStorage stores objects in a local directory. Since runners can't access the directory,
+// LocalStorage serves objects over HTTP with signed URLs, which expire like presigned URLs of S3.
+type LocalStorage struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+var (
+	_ Storage      = (*LocalStorage)(nil)
+	_ http.Handler = (*LocalStorage)(nil)
+)
+
+// NewLocalStorage creates a storage in root. baseURL is the URL where the storage is served as an http.Handler.
+func NewLocalStorage(root, baseURL string, secret []byte) (*LocalStorage, error) {
+	if err := os.MkdirAll(filepath.Join(root, localTmpDir), 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStorage{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." || key == localTmpDir || strings.HasPrefix(key, localTmpDir+"/") {
+		return "", errInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) ListObjects(ctx context.Context, prefix string) ([]ObjectMetaData, error) {
+	var result []ObjectMetaData
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if key == localTmpDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		result = append(result, ObjectMetaData{
+			Key:       key,
+			Timestamp: info.ModTime(),
+			Size:      info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *LocalStorage) DeleteObjects(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		path, err := s.path(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *LocalStorage) GetObject(ctx context.Context, key string) (*Object, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Object{
+		Size: info.Size(),
+		Body: file,
+	}, nil
+}
+
+func (s *LocalStorage) CopyObject(ctx context.Context, src, dst string) error {
+	srcPath, err := s.path(src)
+	if err != nil {
+		return err
+	}
+	dstPath, err := s.path(dst)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(srcPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	defer file.Close()
+
+	return s.put(dstPath, file)
+}
+
+func (s *LocalStorage) sign(method, key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) presign(method, key string, expires time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(expires).Unix()
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", s.sign(method, key, expiresAt))
+
+	return s.baseURL + "/" + strings.Join(segments, "/") + "?" + query.Encode(), nil
+}
+
+func (s *LocalStorage) GetPresignedGetURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, expires)
+}
+
+func (s *LocalStorage) GetPresignedPutURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, expires)
+}
+
+func (s *LocalStorage) verify(method, key string, query url.Values) bool {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(query.Get("signature")), []byte(s.sign(method, key, expires)))
+}
+
+func (s *LocalStorage) serveGet(w http.ResponseWriter, r *http.Request, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		slog.ErrorContext(r.Context(), "Unable to open object", slog.Any("error", err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Unable to stat object", slog.Any("error", err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
+func (s *LocalStorage) put(path string, body io.Reader) error {
+	tmpFile, err := os.CreateTemp(filepath.Join(s.root, localTmpDir), "upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, body); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// ServeHTTP serves objects to signed URLs. The path of the request must be the key of the object,
+// so the handler should be mounted with http.StripPrefix.
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	path, err := s.path(key)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	if method != http.MethodGet && method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.verify(method, key, r.URL.Query()) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	if method == http.MethodGet {
+		s.serveGet(w, r, path)
+		return
+	}
+
+	if err := s.put(path, r.Body); err != nil {
+		slog.ErrorContext(r.Context(), "Unable to store object", slog.Any("error", err))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
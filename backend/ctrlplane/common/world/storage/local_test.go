@@ -0,0 +1,118 @@
+package storage_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func request(method, url, body string) (int, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	Expect(err).NotTo(HaveOccurred())
+	resp, err := http.DefaultClient.Do(req)
+	Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(respBody)
+}
+
+var _ = Describe("LocalStorage", func() {
+	var (
+		sut    *storage.LocalStorage
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+
+		var err error
+		sut, err = storage.NewLocalStorage(GinkgoT().TempDir(), server.URL+"/_/storage", []byte("secret"))
+		Expect(err).NotTo(HaveOccurred())
+		mux.Handle("/_/storage/", http.StripPrefix("/_/storage", sut))
+	})
+
+	It("should store and serve objects with signed URLs", func() {
+		putURL, err := sut.GetPresignedPutURL(GinkgoT().Context(), "foo/bar.tar.zst", time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		status, _ := request(http.MethodPut, putURL, "world")
+		Expect(status).To(Equal(http.StatusOK))
+
+		objs, err := sut.ListObjects(GinkgoT().Context(), "foo/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].Key).To(Equal("foo/bar.tar.zst"))
+		Expect(objs[0].Size).To(Equal(int64(5)))
+
+		getURL, err := sut.GetPresignedGetURL(GinkgoT().Context(), "foo/bar.tar.zst", time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		status, body := request(http.MethodGet, getURL, "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("world"))
+
+		Expect(sut.DeleteObjects(GinkgoT().Context(), []string{"foo/bar.tar.zst"})).To(Succeed())
+		objs, err = sut.ListObjects(GinkgoT().Context(), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+	})
+
+	It("should copy objects", func() {
+		putURL, err := sut.GetPresignedPutURL(GinkgoT().Context(), "foo/bar.tar.zst", time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		status, _ := request(http.MethodPut, putURL, "world")
+		Expect(status).To(Equal(http.StatusOK))
+
+		Expect(sut.CopyObject(GinkgoT().Context(), "foo/bar.tar.zst", "baz/bar.tar.zst")).To(Succeed())
+
+		obj, err := sut.GetObject(GinkgoT().Context(), "baz/bar.tar.zst")
+		Expect(err).NotTo(HaveOccurred())
+		defer obj.Body.Close()
+		body, err := io.ReadAll(obj.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("world"))
+
+		Expect(sut.CopyObject(GinkgoT().Context(), "foo/missing.tar.zst", "baz/missing.tar.zst")).To(MatchError(storage.ErrNotFound))
+	})
+
+	It("should reject URLs signed for another operation", func() {
+		getURL, err := sut.GetPresignedGetURL(GinkgoT().Context(), "foo/bar.tar.zst", time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		status, _ := request(http.MethodPut, getURL, "world")
+		Expect(status).To(Equal(http.StatusForbidden))
+
+		status, _ = request(http.MethodGet, strings.Replace(getURL, "bar", "baz", 1), "")
+		Expect(status).To(Equal(http.StatusForbidden))
+	})
+
+	It("should reject expired URLs", func() {
+		putURL, err := sut.GetPresignedPutURL(GinkgoT().Context(), "foo/bar.tar.zst", -time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		status, _ := request(http.MethodPut, putURL, "world")
+		Expect(status).To(Equal(http.StatusForbidden))
+	})
+
+	DescribeTable("should reject invalid keys",
+		func(key string) {
+			_, err := sut.GetPresignedPutURL(GinkgoT().Context(), key, time.Minute)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("parent directory", "../foo"),
+		Entry("absolute path", "/foo"),
+		Entry("temporary directory", ".tmp/foo"),
+	)
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Storage Suite")
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/s3wrap"
+)
+
+type S3Storage struct {
+	client *s3wrap.Client
+	bucket string
+}
+
+var _ Storage = (*S3Storage)(nil)
+
+func NewS3Storage(ctx context.Context, bucket string, forcePathStyle bool) (*S3Storage, error) {
+	client, err := s3wrap.New(ctx, forcePathStyle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Storage{
+		client: client,
+		bucket: bucket,
+	}, nil
+}
+
+func (s *S3Storage) ListObjects(ctx context.Context, prefix string) ([]ObjectMetaData, error) {
+	var opts []s3wrap.ListObjectsOption
+	if prefix != "" {
+		opts = append(opts, s3wrap.WithPrefix(prefix))
+	}
+
+	objs, err := s.client.ListObjects(ctx, s.bucket, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ObjectMetaData, 0, len(objs))
+	for _, obj := range objs {
+		result = append(result, ObjectMetaData{
+			Key:       obj.Key,
+			Timestamp: obj.Timestamp,
+			Size:      obj.Size,
+		})
+	}
+	return result, nil
+}
+
+func (s *S3Storage) DeleteObjects(ctx context.Context, keys []string) error {
+	return s.client.DeleteObjects(ctx, s.bucket, keys)
+}
+
+func (s *S3Storage) GetObject(ctx context.Context, key string) (*Object, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key)
+	if err != nil {
+		if errors.Is(err, s3wrap.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &Object{
+		Size: obj.Size,
+		Body: obj.Body,
+	}, nil
+}
+
+func (s *S3Storage) CopyObject(ctx context.Context, src, dst string) error {
+	return s.client.CopyObject(ctx, s.bucket, src, dst)
+}
+
+func (s *S3Storage) GetPresignedGetURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.client.GetPresignedGetURL(ctx, s.bucket, key, expires)
+}
+
+func (s *S3Storage) GetPresignedPutURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return s.client.GetPresignedPutURL(ctx, s.bucket, key, expires)
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type ObjectMetaData struct {
+	Key       string
+	Timestamp time.Time
+	Size      int64
+}
+
+type Object struct {
+	Size int64
+	Body io.ReadCloser
+}
+
+// Storage stores world archives and blobs of incremental backups.
+// Runners never access the storage directly, but through URLs which GetPresignedGetURL and GetPresignedPutURL return.
+type Storage interface {
+	// ListObjects returns objects whose keys start with prefix. Empty prefix lists all objects.
+	ListObjects(ctx context.Context, prefix string) ([]ObjectMetaData, error)
+	DeleteObjects(ctx context.Context, keys []string) error
+	// GetObject returns ErrNotFound if the object doesn't exist.
+	GetObject(ctx context.Context, key string) (*Object, error)
+	CopyObject(ctx context.Context, src, dst string) error
+	GetPresignedGetURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	GetPresignedPutURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// Options selects and configures a storage for New.
+type Options struct {
+	// Backend is either "s3" or "local".
+	Backend          string
+	S3Bucket         string
+	S3ForcePathStyle bool
+	LocalPath        string
+	// LocalBaseURL is the URL where the local storage is served.
+	LocalBaseURL string
+	LocalSecret  []byte
+}
+
+func New(ctx context.Context, opts Options) (Storage, error) {
+	switch opts.Backend {
+	case "s3":
+		return NewS3Storage(ctx, opts.S3Bucket, opts.S3ForcePathStyle)
+	case "local":
+		if opts.LocalPath == "" {
+			return nil, errors.New("path of the local storage is not specified")
+		}
+		return NewLocalStorage(opts.LocalPath, opts.LocalBaseURL, opts.LocalSecret)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", opts.Backend)
+	}
+}
@@ -0,0 +1,368 @@
+package world
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/mc/leveldat"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+)
+
+// UploadPrefix is the prefix of archives which users upload. They are moved to the world after validation.
+const UploadPrefix = "@uploads/"
+
+const (
+	// MaxUploadSize is the maximum size of archives which users upload.
+	MaxUploadSize int64 = 4 << 30
+	// MaxExtractedSize is the maximum total size of files in an uploaded archive.
+	MaxExtractedSize int64 = 16 << 30
+
+	// Uploads which are not completed within this period are deleted on prune.
+	uploadGracePeriod = 24 * time.Hour
+)
+
+var ErrInvalidArchive = errors.New("invalid world archive")
+
+type archiveInfo struct {
+	level    *leveldat.LevelDat
+	checksum string
+}
+
+type archiveEntry struct {
+	name string
+	dir  bool
+	body io.Reader
+}
+
+func invalidArchive(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidArchive, fmt.Sprintf(format, args...))
+}
+
+// isUploadedArchive returns true if the key looks like an archive which users upload.
+func isUploadedArchive(key string) bool {
+	switch archiveFormat(key) {
+	case "zip", "tar.gz", "tar.zst":
+		return true
+	}
+	return false
+}
+
+// cleanEntryName returns the name of the entry relative to the root of the archive.
+// It fails if the entry would be extracted outside the directory.
+func cleanEntryName(name string) (string, error) {
+	cleaned := strings.ReplaceAll(name, `\`, "/")
+	for strings.HasPrefix(cleaned, "./") {
+		cleaned = strings.TrimPrefix(cleaned, "./")
+	}
+	cleaned = strings.TrimSuffix(cleaned, "/")
+	if cleaned == "" || cleaned == "." {
+		return "", nil
+	}
+	if !fs.ValidPath(cleaned) {
+		return "", invalidArchive("unsafe path: %s", name)
+	}
+	return cleaned, nil
+}
+
+func parseLevelDat(r io.Reader) (*leveldat.LevelDat, error) {
+	levelDat, err := leveldat.Read(r)
+	if err != nil {
+		return nil, invalidArchive("level.dat: %v", err)
+	}
+	return levelDat, nil
+}
+
+func walkTar(r io.Reader, fn func(archiveEntry) error) error {
+	tr := tar.NewReader(r)
+	for {
+		th, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return invalidArchive("%v", err)
+		}
+
+		switch th.Typeflag {
+		case tar.TypeDir:
+			if err := fn(archiveEntry{name: th.Name, dir: true}); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := fn(archiveEntry{name: th.Name, body: tr}); err != nil {
+				return err
+			}
+		default:
+			// Runners can't extract other types of entries either.
+			return invalidArchive("unsupported entry: %s", th.Name)
+		}
+	}
+}
+
+func walkZip(r io.Reader, tempDir string, fn func(archiveEntry) error) error {
+	// ZIP requires seek operations to read.
+	tmpFile, err := os.CreateTemp(tempDir, "premises-upload-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	size, err := io.Copy(tmpFile, r)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(tmpFile, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return invalidArchive("%v", err)
+	}
+
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") {
+			if err := fn(archiveEntry{name: f.Name, dir: true}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return invalidArchive("%s: %v", f.Name, err)
+		}
+		err = fn(archiveEntry{name: f.Name, body: rc})
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inspectArchive reads the archive and returns information of the world in it.
+// ZIP archives are saved in tempDir while they are read.
+func inspectArchive(r io.Reader, format, tempDir string) (*archiveInfo, error) {
+	checksum := sha256.New()
+	counter := countingReader{r: io.TeeReader(r, checksum)}
+
+	var level *leveldat.LevelDat
+	var totalSize int64
+	handleEntry := func(entry archiveEntry) error {
+		name, err := cleanEntryName(entry.name)
+		if err != nil {
+			return err
+		}
+		if entry.dir {
+			return nil
+		}
+
+		// Count bytes actually extracted, as sizes in headers can be forged.
+		limit := MaxExtractedSize - totalSize + 1
+		body := &io.LimitedReader{R: entry.body, N: limit}
+
+		// Runners treat the directory which contains the first level.dat as the world.
+		if level == nil && (name == "level.dat" || strings.HasSuffix(name, "/level.dat")) {
+			level, err = parseLevelDat(body)
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := io.Copy(io.Discard, body); err != nil {
+			return invalidArchive("%s: %v", entry.name, err)
+		}
+
+		totalSize += limit - body.N
+		if totalSize > MaxExtractedSize {
+			return invalidArchive("extracted content is larger than %d bytes", MaxExtractedSize)
+		}
+		return nil
+	}
+
+	var err error
+	switch format {
+	case "zip":
+		err = walkZip(&counter, tempDir, handleEntry)
+	case "tar.gz":
+		var gzipReader *gzip.Reader
+		gzipReader, err = gzip.NewReader(&counter)
+		if err != nil {
+			return nil, invalidArchive("%v", err)
+		}
+		defer gzipReader.Close()
+		err = walkTar(gzipReader, handleEntry)
+	case "tar.zst":
+		var zstdReader *zstd.Decoder
+		zstdReader, err = zstd.NewReader(&counter)
+		if err != nil {
+			return nil, invalidArchive("%v", err)
+		}
+		defer zstdReader.Close()
+		err = walkTar(zstdReader, handleEntry)
+	default:
+		return nil, invalidArchive("unsupported format: %s", format)
+	}
+	if counter.err != nil {
+		// Don't blame the archive for errors of the storage.
+		return nil, counter.err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the rest of the archive to compute the checksum.
+	if _, err := io.Copy(io.Discard, &counter); err != nil {
+		return nil, err
+	}
+	if level == nil {
+		return nil, invalidArchive("level.dat is not found")
+	}
+
+	return &archiveInfo{
+		level:    level,
+		checksum: hex.EncodeToString(checksum.Sum(nil)),
+	}, nil
+}
+
+// countingReader fails if more than MaxUploadSize bytes are read. It also remembers errors of the underlying reader.
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.n > MaxUploadSize {
+		return n, invalidArchive("archive is larger than %d bytes", MaxUploadSize)
+	}
+	if err != nil && err != io.EOF {
+		c.err = err
+	}
+	return n, err
+}
+
+// uploadDestination returns the key of the generation which the archive uploaded to key becomes.
+func uploadDestination(key string) (string, bool) {
+	dst, ok := strings.CutPrefix(key, UploadPrefix)
+	if !ok || !isUploadedArchive(dst) {
+		return "", false
+	}
+	if _, _, err := extractWorldInfoFromKey(dst); err != nil {
+		return "", false
+	}
+	return dst, true
+}
+
+// CheckUpload returns ErrNotFound unless an archive to be completed with CompleteUpload exists at key.
+func (ws *WorldService) CheckUpload(ctx context.Context, key string) error {
+	if _, ok := uploadDestination(key); !ok {
+		return ErrNotFound
+	}
+
+	objs, err := ws.storage.ListObjects(ctx, key)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if obj.Key == key {
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// CompleteUpload validates the archive which a user uploaded to key under UploadPrefix,
+// and adds it to the world as a new generation. Invalid archives are deleted.
+// It reads the whole archive, so it may take long.
+func (ws *WorldService) CompleteUpload(ctx context.Context, key, createdBy string) (*web.WorldGeneration, error) {
+	dst, ok := uploadDestination(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	obj, err := ws.storage.GetObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer obj.Body.Close()
+
+	var info *archiveInfo
+	if obj.Size > MaxUploadSize {
+		err = invalidArchive("archive is larger than %d bytes", MaxUploadSize)
+	} else {
+		info, err = inspectArchive(obj.Body, archiveFormat(dst), ws.tempDir)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidArchive) {
+			if err := ws.storage.DeleteObjects(ctx, []string{key}); err != nil {
+				slog.ErrorContext(ctx, "Unable to delete invalid archive", slog.String("key", key), slog.Any("error", err))
+			}
+		}
+		return nil, err
+	}
+
+	if err := ws.storage.CopyObject(ctx, key, dst); err != nil {
+		return nil, err
+	}
+	if err := ws.storage.DeleteObjects(ctx, []string{key}); err != nil {
+		// It'll be deleted on prune.
+		slog.ErrorContext(ctx, "Unable to delete uploaded archive", slog.String("key", key), slog.Any("error", err))
+	}
+
+	if err := ws.RecordGeneration(ctx, dst, GenerationInfo{
+		Size:      obj.Size,
+		MCVersion: info.level.Data.Version.Name,
+		CreatedBy: createdBy,
+		Checksum:  info.checksum,
+		Level:     info.level.Info(),
+	}); err != nil {
+		return nil, err
+	}
+
+	gen, err := ws.GetGeneration(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+	result := toGenerationEntity(gen)
+	return &result, nil
+}
+
+// pruneUploads deletes uploaded archives which were never completed.
+func (ws *WorldService) pruneUploads(ctx context.Context) error {
+	objs, err := ws.storage.ListObjects(ctx, UploadPrefix)
+	if err != nil {
+		return err
+	}
+
+	threshold := time.Now().Add(-uploadGracePeriod)
+	var stale []string
+	for _, obj := range objs {
+		if obj.Timestamp.Before(threshold) {
+			stale = append(stale, obj.Key)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	return ws.storage.DeleteObjects(ctx, stale)
+}
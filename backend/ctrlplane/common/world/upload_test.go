@@ -0,0 +1,159 @@
+package world
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func nbtName(buf *bytes.Buffer, name string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(name)))
+	buf.WriteString(name)
+}
+
+// createLevelDat creates a gzipped level.dat which only has fields read by inspectArchive.
+func createLevelDat(levelName, version string, seed int64) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(10)
+	nbtName(&buf, "")
+	buf.WriteByte(10)
+	nbtName(&buf, "Data")
+
+	buf.WriteByte(8)
+	nbtName(&buf, "LevelName")
+	nbtName(&buf, levelName)
+
+	buf.WriteByte(10)
+	nbtName(&buf, "Version")
+	buf.WriteByte(8)
+	nbtName(&buf, "Name")
+	nbtName(&buf, version)
+	buf.WriteByte(0)
+
+	buf.WriteByte(10)
+	nbtName(&buf, "WorldGenSettings")
+	buf.WriteByte(4)
+	nbtName(&buf, "seed")
+	binary.Write(&buf, binary.BigEndian, seed)
+	buf.WriteByte(0)
+
+	buf.WriteByte(0)
+	buf.WriteByte(0)
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	gw.Write(buf.Bytes())
+	gw.Close()
+	return out.Bytes()
+}
+
+type testEntry struct {
+	name    string
+	content []byte
+}
+
+func createTarGz(entries ...testEntry) []byte {
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		if strings.HasSuffix(e.name, "/") {
+			tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeDir, Mode: 0o755})
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.content))})
+		tw.Write(e.content)
+	}
+	tw.Close()
+	gw.Close()
+	return out.Bytes()
+}
+
+func createZip(entries ...testEntry) []byte {
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, e := range entries {
+		w, _ := zw.Create(e.name)
+		w.Write(e.content)
+	}
+	zw.Close()
+	return out.Bytes()
+}
+
+// createForgedZip creates a ZIP archive with valid, and forged whose content is larger than its header says.
+func createForgedZip(valid, forged testEntry) []byte {
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	w, _ := zw.Create(valid.name)
+	w.Write(valid.content)
+	w, _ = zw.CreateRaw(&zip.FileHeader{
+		Name:               forged.name,
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(forged.content),
+		CompressedSize64:   uint64(len(forged.content)),
+		UncompressedSize64: 1,
+	})
+	w.Write(forged.content)
+	zw.Close()
+	return out.Bytes()
+}
+
+var _ = Describe("Upload", func() {
+	levelDatContent := createLevelDat("sample world", "1.20.4", -3416194518646519871)
+
+	It("should read level.dat in tar.gz", func() {
+		archive := createTarGz(
+			testEntry{name: "./"},
+			testEntry{name: "./world/"},
+			testEntry{name: "./world/level.dat", content: levelDatContent},
+			testEntry{name: "./world/region/r.0.0.mca", content: []byte("region")},
+		)
+
+		info, err := inspectArchive(bytes.NewReader(archive), "tar.gz", GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.level.Info().MinecraftVersion).To(Equal("1.20.4"))
+		Expect(info.level.Info().Seed).To(Equal("-3416194518646519871"))
+		Expect(info.checksum).To(HaveLen(64))
+	})
+
+	It("should read level.dat in zip", func() {
+		archive := createZip(
+			testEntry{name: "level.dat", content: levelDatContent},
+		)
+
+		info, err := inspectArchive(bytes.NewReader(archive), "zip", GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.level.Info().MinecraftVersion).To(Equal("1.20.4"))
+	})
+
+	DescribeTable("should reject invalid archives", func(archive []byte, format string) {
+		_, err := inspectArchive(bytes.NewReader(archive), format, GinkgoT().TempDir())
+		Expect(err).To(MatchError(ErrInvalidArchive))
+	},
+		Entry("no level.dat", createTarGz(testEntry{name: "world/region/r.0.0.mca", content: []byte("region")}), "tar.gz"),
+		Entry("broken level.dat", createZip(testEntry{name: "world/level.dat", content: []byte("broken")}), "zip"),
+		Entry("path traversal in tar", createTarGz(
+			testEntry{name: "world/level.dat", content: levelDatContent},
+			testEntry{name: "world/../../evil", content: []byte("evil")},
+		), "tar.gz"),
+		Entry("path traversal in zip", createZip(
+			testEntry{name: "world/level.dat", content: levelDatContent},
+			testEntry{name: `..\evil`, content: []byte("evil")},
+		), "zip"),
+		Entry("absolute path", createTarGz(
+			testEntry{name: "/world/level.dat", content: levelDatContent},
+		), "tar.gz"),
+		Entry("not an archive", []byte("not an archive"), "tar.gz"),
+		Entry("forged size in zip", createForgedZip(
+			testEntry{name: "world/level.dat", content: levelDatContent},
+			testEntry{name: "world/region/r.0.0.mca", content: []byte("region")},
+		), "zip"),
+	)
+})
@@ -0,0 +1,112 @@
+package world
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/manifest"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+)
+
+// verifyBatchSize is the number of generations VerifyGenerations downloads at once,
+// so that each run finishes in a reasonable time even with a large bucket.
+const verifyBatchSize = 20
+
+// verifyGeneration downloads the object and returns whether it matches the recorded checksum.
+// Blobs which manifests refer to must also exist.
+func (ws *WorldService) verifyGeneration(ctx context.Context, gen *model.WorldGeneration, blobs func() (map[string]storage.ObjectMetaData, error)) (bool, error) {
+	obj, err := ws.storage.GetObject(ctx, gen.Key)
+	if err != nil {
+		return false, err
+	}
+	defer obj.Body.Close()
+
+	hash := sha256.New()
+	r := io.TeeReader(obj.Body, hash)
+
+	var m *manifest.Manifest
+	if gen.Format == FormatManifest {
+		m, err = manifest.Read(r)
+		if err != nil {
+			// Unreadable manifest can't restore the world anyway.
+			m = nil
+		}
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return false, err
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != gen.Checksum {
+		return false, nil
+	}
+
+	if gen.Format == FormatManifest {
+		if m == nil {
+			return false, nil
+		}
+		existing, err := blobs()
+		if err != nil {
+			return false, err
+		}
+		for _, hash := range m.Blobs() {
+			if _, ok := existing[hash]; !ok {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// VerifyGenerations re-verifies generations which have not been verified for the longest time,
+// and flags the ones which don't match their checksum as corrupt. It returns keys of corrupt generations found.
+func (ws *WorldService) VerifyGenerations(ctx context.Context) ([]string, error) {
+	var gens []model.WorldGeneration
+	if err := ws.db.NewSelect().Model(&gens).
+		Where("checksum <> ''").
+		OrderExpr("verified_at ASC NULLS FIRST").
+		Limit(verifyBatchSize).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	var blobCache map[string]storage.ObjectMetaData
+	blobs := func() (map[string]storage.ObjectMetaData, error) {
+		if blobCache == nil {
+			var err error
+			if blobCache, err = ws.listBlobs(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return blobCache, nil
+	}
+
+	var corrupt []string
+	var errs []error
+	for i := range gens {
+		gen := &gens[i]
+
+		ok, err := ws.verifyGeneration(ctx, gen, blobs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", gen.Key, err))
+			continue
+		}
+		if !ok {
+			corrupt = append(corrupt, gen.Key)
+		}
+
+		gen.Corrupt = !ok
+		gen.VerifiedAt = time.Now()
+		if _, err := ws.db.NewUpdate().Model(gen).Column("corrupt", "verified_at").WherePK().Exec(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return corrupt, errors.Join(errs...)
+}
@@ -2,98 +2,115 @@ package world
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/kofuk/premises/backend/common/entity/web"
-	"github.com/kofuk/premises/backend/common/s3wrap"
+	"github.com/kofuk/premises/backend/common/manifest"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	"github.com/uptrace/bun"
 )
 
 type WorldService struct {
-	s3     *s3wrap.Client
-	bucket string
+	storage storage.Storage
+	db      *bun.DB
+	tempDir string
 }
 
-func New(ctx context.Context, bucket string, forcePathStyle bool) (*WorldService, error) {
-	client, err := s3wrap.New(ctx, forcePathStyle)
-	if err != nil {
-		return nil, err
+type Option func(ws *WorldService)
+
+// WithTempDir sets the directory where uploaded archives are saved while they are validated.
+// It should be on a disk rather than tmpfs, as archives can be as large as MaxUploadSize.
+func WithTempDir(dir string) Option {
+	return func(ws *WorldService) {
+		ws.tempDir = dir
 	}
+}
 
-	return &WorldService{
-		s3:     client,
-		bucket: bucket,
-	}, nil
+func New(db *bun.DB, store storage.Storage, opts ...Option) *WorldService {
+	ws := &WorldService{
+		storage: store,
+		db:      db,
+	}
+	for _, opt := range opts {
+		opt(ws)
+	}
+	return ws
+}
+
+// StorageHandler returns the handler which serves URLs of the storage, or nil if the storage doesn't need it.
+func (ws *WorldService) StorageHandler() http.Handler {
+	if handler, ok := ws.storage.(http.Handler); ok {
+		return handler
+	}
+	return nil
+}
+
+// archiveFormat returns the archive format of the key, or an empty string if it is unknown.
+func archiveFormat(key string) string {
+	for _, format := range []string{"tar.zst", "tar.xz", "tar.gz", "zip", FormatManifest} {
+		if strings.HasSuffix(key, "."+format) {
+			return format
+		}
+	}
+	return ""
 }
 
 func extractWorldInfoFromKey(key string) (string, string, error) {
+	if strings.HasPrefix(key, manifest.BlobPrefix) || strings.HasPrefix(key, UploadPrefix) || strings.HasPrefix(key, DatapackPrefix) || strings.HasPrefix(key, ModSetPrefix) || strings.HasPrefix(key, CrashReportPrefix) {
+		return "", "", errNotWorld
+	}
 	splitIndex := strings.IndexRune(key, '/')
 	if splitIndex < 0 {
 		return "", "", fmt.Errorf("invalid backup key: %s", key)
 	}
 	world := string(key[0:splitIndex])
 	name := string(key[splitIndex+1:])
-	if strings.HasSuffix(name, ".tar.zst") {
-		name = strings.TrimSuffix(name, ".tar.zst")
-	} else if strings.HasSuffix(name, ".tar.xz") {
-		name = strings.TrimSuffix(name, ".tar.xz")
-	} else {
-		name = strings.TrimSuffix(name, ".zip")
+	if format := archiveFormat(name); format != "" {
+		name = strings.TrimSuffix(name, "."+format)
 	}
 	return world, name, nil
 }
 
-func (ws *WorldService) GetWorlds(ctx context.Context) ([]web.World, error) {
-	worlds := make(map[string]web.World)
-	objects, err := ws.s3.ListObjects(ctx, ws.bucket)
+// IsGenerationKey reports whether the key is of a generation, rather than other objects such as blobs and datapacks.
+func IsGenerationKey(key string) bool {
+	worldName, gen, err := extractWorldInfoFromKey(key)
 	if err != nil {
-		return nil, err
+		return false
 	}
+	return IsValidWorldName(worldName) && gen != "" && !strings.ContainsAny(gen, "/\\") && archiveFormat(key) != ""
+}
 
-	for _, obj := range objects {
-		world, gen, err := extractWorldInfoFromKey(obj.Key)
-		if err != nil {
-			return nil, err
-		}
-		worlds[world] = web.World{
-			WorldName: world,
-			Generations: append(worlds[world].Generations, web.WorldGeneration{
-				Gen:       gen,
-				ID:        obj.Key,
-				Timestamp: int(obj.Timestamp.UnixMilli()),
-			}),
-		}
+// DeleteWorld deletes the generation. It returns ErrNotFound if id is not a key of a generation.
+func (ws *WorldService) DeleteWorld(ctx context.Context, id string) error {
+	if !IsGenerationKey(id) {
+		return ErrNotFound
 	}
-
-	for _, world := range worlds {
-		sort.Slice(world.Generations, func(i, j int) bool {
-			return world.Generations[i].Timestamp > world.Generations[j].Timestamp
-		})
+	if err := ws.storage.DeleteObjects(ctx, []string{id}); err != nil {
+		return err
 	}
+	return ws.forgetGenerations(ctx, []string{id})
+}
 
-	var keys []string
-	for k := range worlds {
-		keys = append(keys, k)
+func (ws *WorldService) GetLatestWorldKey(ctx context.Context, world string) (string, error) {
+	// Timestamps in the storage are reset when generations are copied, so the catalog is preferred.
+	var key string
+	err := ws.db.NewSelect().Model((*model.WorldGeneration)(nil)).Column("key").Where("world_name = ? AND NOT corrupt", world).Order("created_at DESC").Limit(1).Scan(ctx, &key)
+	if err == nil {
+		return key, nil
 	}
-	sort.Strings(keys)
-
-	result := make([]web.World, 0)
-	for _, k := range keys {
-		result = append(result, worlds[k])
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
 	}
 
-	return result, nil
-}
-
-func (ws *WorldService) DeleteWorld(ctx context.Context, id string) error {
-	return ws.s3.DeleteObjects(ctx, ws.bucket, []string{id})
-}
-
-func (ws *WorldService) GetLatestWorldKey(ctx context.Context, world string) (string, error) {
-	objects, err := ws.s3.ListObjects(ctx, ws.bucket, s3wrap.WithPrefix(world+"/"))
+	// The world may not be in the catalog yet.
+	objects, err := ws.storage.ListObjects(ctx, world+"/")
 	if err != nil {
 		return "", err
 	}
@@ -106,7 +123,17 @@ func (ws *WorldService) GetLatestWorldKey(ctx context.Context, world string) (st
 		return objects[i].Timestamp.Unix() > objects[j].Timestamp.Unix()
 	})
 
-	return objects[0].Key, nil
+	var corrupt []string
+	if err := ws.db.NewSelect().Model((*model.WorldGeneration)(nil)).Column("key").Where("world_name = ? AND corrupt", world).Scan(ctx, &corrupt); err != nil {
+		return "", err
+	}
+	for _, obj := range objects {
+		if !slices.Contains(corrupt, obj.Key) {
+			return obj.Key, nil
+		}
+	}
+
+	return "", fmt.Errorf("no intact generation of world: %s", world)
 }
 
 func (ws *WorldService) GetPresignedGetURL(ctx context.Context, id string) (string, error) {
@@ -114,7 +141,7 @@ func (ws *WorldService) GetPresignedGetURL(ctx context.Context, id string) (stri
 }
 
 func (ws *WorldService) GetPresignedGetURLWithLifetime(ctx context.Context, id string, dur time.Duration) (string, error) {
-	return ws.s3.GetPresignedGetURL(ctx, ws.bucket, id, dur)
+	return ws.storage.GetPresignedGetURL(ctx, id, dur)
 }
 
 func (ws *WorldService) GetPresignedPutURL(ctx context.Context, id string) (string, error) {
@@ -122,41 +149,7 @@ func (ws *WorldService) GetPresignedPutURL(ctx context.Context, id string) (stri
 }
 
 func (ws *WorldService) GetPresignedPutURLWithLifetime(ctx context.Context, id string, dur time.Duration) (string, error) {
-	return ws.s3.GetPresignedPutURL(ctx, ws.bucket, id, dur)
-}
-
-func groupByPrefix(objs []s3wrap.ObjectMetaData) map[string][]s3wrap.ObjectMetaData {
-	result := make(map[string][]s3wrap.ObjectMetaData)
-	for _, obj := range objs {
-		pk := strings.SplitN(obj.Key, "/", 2)
-		if len(pk) != 2 {
-			continue
-		}
-
-		result[pk[0]] = append(result[pk[0]], obj)
-	}
-
-	return result
-}
-
-func (w *WorldService) pruneSlice(ctx context.Context, objs []s3wrap.ObjectMetaData, preserveCount int) error {
-	if len(objs) <= preserveCount {
-		return nil
-	}
-
-	sort.Slice(objs, func(i, j int) bool {
-		return objs[i].Timestamp.Unix() > objs[j].Timestamp.Unix()
-	})
-
-	var keys []string
-	for _, obj := range objs[preserveCount:] {
-		keys = append(keys, obj.Key)
-	}
-	if err := w.s3.DeleteObjects(ctx, w.bucket, keys); err != nil {
-		return err
-	}
-
-	return nil
+	return ws.storage.GetPresignedPutURL(ctx, id, dur)
 }
 
 type PruneError struct {
@@ -168,20 +161,40 @@ func (e PruneError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Prefix, e.Err.Error())
 }
 
-func (w *WorldService) Prune(ctx context.Context, preserveCount int) error {
-	objs, err := w.s3.ListObjects(ctx, w.bucket)
+// Prune deletes generations which retention policies of the worlds don't retain,
+// and then blobs of incremental generations which are no longer referred to, stale uploads and expired crash reports.
+func (w *WorldService) Prune(ctx context.Context) error {
+	plan, err := w.PlanPrune(ctx)
 	if err != nil {
 		return err
 	}
 
-	groupedObjs := groupByPrefix(objs)
-
 	var errs []error
-	for prefix, objs := range groupedObjs {
-		if err := w.pruneSlice(ctx, objs, preserveCount); err != nil {
-			errs = append(errs, PruneError{Prefix: prefix, Err: err})
+	for worldName, gens := range plan {
+		keys := make([]string, 0, len(gens))
+		for _, gen := range gens {
+			keys = append(keys, gen.Key)
+		}
+		if err := w.storage.DeleteObjects(ctx, keys); err != nil {
+			errs = append(errs, PruneError{Prefix: worldName, Err: err})
+			continue
+		}
+		if err := w.forgetGenerations(ctx, keys); err != nil {
+			errs = append(errs, PruneError{Prefix: worldName, Err: err})
 		}
 	}
 
+	if err := w.CollectGarbage(ctx); err != nil {
+		errs = append(errs, PruneError{Prefix: manifest.BlobPrefix, Err: err})
+	}
+
+	if err := w.pruneUploads(ctx); err != nil {
+		errs = append(errs, PruneError{Prefix: UploadPrefix, Err: err})
+	}
+
+	if err := w.pruneCrashReports(ctx); err != nil {
+		errs = append(errs, PruneError{Prefix: CrashReportPrefix, Err: err})
+	}
+
 	return errors.Join(errs...)
 }
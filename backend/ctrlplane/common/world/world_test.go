@@ -1,9 +1,11 @@
 package world
 
 import (
+	"slices"
 	"testing"
+	"time"
 
-	"github.com/kofuk/premises/backend/common/s3wrap"
+	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -45,6 +47,12 @@ var _ = Describe("World", func() {
 			"foo",
 			"bar",
 		),
+		Entry(
+			".tar.gz file",
+			"foo/bar.tar.gz",
+			"foo",
+			"bar",
+		),
 		Entry(
 			"unknown extension",
 			"foo/bar.pptx",
@@ -53,58 +61,129 @@ var _ = Describe("World", func() {
 		),
 	)
 
+	DescribeTable("archiveFormat", func(key, format string) {
+		Expect(archiveFormat(key)).To(Equal(format))
+	},
+		Entry("tar.zst", "foo/2026-10-17 12:00:00.tar.zst", "tar.zst"),
+		Entry("tar.xz", "foo/bar.tar.xz", "tar.xz"),
+		Entry("tar.gz", "foo/user_uploaded_world.tar.gz", "tar.gz"),
+		Entry("zip", "foo/user_uploaded_world.zip", "zip"),
+		Entry("unknown", "foo/bar.pptx", ""),
+	)
+
 	It("should raise error if key is invalid", func() {
 		_, _, err := extractWorldInfoFromKey("foo.tar.zst")
 		Expect(err).To(HaveOccurred())
 	})
 
-	DescribeTable("should group by prefix", func(objs []s3wrap.ObjectMetaData, expected map[string][]s3wrap.ObjectMetaData) {
-		out := groupByPrefix(objs)
-		Expect(out).To(Equal(expected))
+	It("should not treat datapacks as worlds", func() {
+		_, _, err := extractWorldInfoFromKey("@datapacks/foo.zip")
+		Expect(err).To(MatchError(errNotWorld))
+	})
+
+	DescribeTable("IsValidWorldName", func(name string, valid bool) {
+		Expect(IsValidWorldName(name)).To(Equal(valid))
 	},
-		Entry(
-			"Empty slice",
-			[]s3wrap.ObjectMetaData{},
-			make(map[string][]s3wrap.ObjectMetaData),
+		Entry("normal", "foo", true),
+		Entry("multibyte", "ああ", true),
+		Entry("empty", "", false),
+		Entry("slash", "foo/bar", false),
+		Entry("backslash", `foo\bar`, false),
+		Entry("reserved prefix", "@blobs", false),
+		Entry("dot", ".", false),
+		Entry("dot dot", "..", false),
+		Entry("leading dots", "..foo", true),
+	)
+
+	DescribeTable("IsGenerationKey", func(key string, expected bool) {
+		Expect(IsGenerationKey(key)).To(Equal(expected))
+	},
+		Entry("archive", "foo/bar.tar.zst", true),
+		Entry("manifest", "foo/bar.manifest.json", true),
+		Entry("blob", "@blobs/ab/abcdef", false),
+		Entry("datapack", "@datapacks/foo.zip", false),
+		Entry("mod set", "@modsets/foo/bar.zip", false),
+		Entry("crash report", "@crashreports/1/20240101-000000.txt", false),
+		Entry("upload", "@uploads/foo.zip", false),
+		Entry("no world", "bar.tar.zst", false),
+		Entry("nested", "foo/bar/baz.tar.zst", false),
+		Entry("dot dot world", "../bar.tar.zst", false),
+		Entry("unknown extension", "foo/bar.pptx", false),
+		Entry("empty generation", "foo/.tar.zst", false),
+	)
+
+	DescribeTable("IsValidRetentionPolicy", func(policy model.WorldRetentionPolicy, valid bool) {
+		Expect(IsValidRetentionPolicy(&policy)).To(Equal(valid))
+	},
+		Entry("default", DefaultRetentionPolicy, true),
+		Entry("daily and weekly", model.WorldRetentionPolicy{KeepLast: 1, KeepDaily: 7, KeepWeekly: 4}, true),
+		Entry("keep nothing", model.WorldRetentionPolicy{KeepLast: 0, KeepDaily: 7}, false),
+		Entry("negative", model.WorldRetentionPolicy{KeepLast: 3, KeepWeekly: -1}, false),
+		Entry("too many", model.WorldRetentionPolicy{KeepLast: 1001}, false),
+	)
+
+	DescribeTable("selectExpired", func(policy model.WorldRetentionPolicy, ages []int, pinned []int, corrupt []int, expected []int) {
+		now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+		// ages are hours before now, from newest to oldest
+		var gens []model.WorldGeneration
+		for i, age := range ages {
+			gens = append(gens, model.WorldGeneration{
+				ID:        uint(i),
+				CreatedAt: now.Add(-time.Duration(age) * time.Hour),
+				Pinned:    slices.Contains(pinned, i),
+				Corrupt:   slices.Contains(corrupt, i),
+			})
+		}
+
+		actual := []int{}
+		for _, gen := range selectExpired(gens, &policy) {
+			actual = append(actual, int(gen.ID))
+		}
+		Expect(actual).To(Equal(expected))
+	},
+		Entry("keep last",
+			model.WorldRetentionPolicy{KeepLast: 3},
+			[]int{1, 2, 3, 4, 5}, nil, nil,
+			[]int{3, 4},
 		),
-		Entry(
-			"Different prefixes",
-			[]s3wrap.ObjectMetaData{
-				{Key: "prefix1/object1"},
-				{Key: "prefix1/object2"},
-				{Key: "prefix2/object3"},
-				{Key: "prefix2/object4"},
-			},
-			map[string][]s3wrap.ObjectMetaData{
-				"prefix1": {{Key: "prefix1/object1"}, {Key: "prefix1/object2"}},
-				"prefix2": {{Key: "prefix2/object3"}, {Key: "prefix2/object4"}},
-			},
+		Entry("fewer generations than keep last",
+			model.WorldRetentionPolicy{KeepLast: 3},
+			[]int{1, 2}, nil, nil,
+			[]int{},
 		),
-		Entry(
-			"Objects without prefixes",
-			[]s3wrap.ObjectMetaData{
-				{Key: "object"},
-			},
-			map[string][]s3wrap.ObjectMetaData{},
+		Entry("pinned generations are kept",
+			model.WorldRetentionPolicy{KeepLast: 1},
+			[]int{1, 2, 3, 4}, []int{2}, nil,
+			[]int{1, 3},
 		),
-		Entry(
-			"Multiple slashes in keys",
-			[]s3wrap.ObjectMetaData{
-				{Key: "prefix1/subprefix1/object1"},
-				{Key: "prefix1/subprefix2/object2"},
-				{Key: "prefix2/subprefix1/object3"},
-				{Key: "prefix2/subprefix2/object4"},
-			},
-			map[string][]s3wrap.ObjectMetaData{
-				"prefix1": {
-					{Key: "prefix1/subprefix1/object1"},
-					{Key: "prefix1/subprefix2/object2"},
-				},
-				"prefix2": {
-					{Key: "prefix2/subprefix1/object3"},
-					{Key: "prefix2/subprefix2/object4"},
-				},
-			},
+		Entry("daily",
+			// 10:00, 06:00 and 02:00 today, 22:00 and 14:00 yesterday, 2 days ago and 3 days ago
+			model.WorldRetentionPolicy{KeepLast: 1, KeepDaily: 2},
+			[]int{2, 6, 10, 14, 22, 48, 72}, nil, nil,
+			[]int{1, 2, 4, 5, 6},
+		),
+		Entry("weekly",
+			// 2026-10-17 is Saturday
+			model.WorldRetentionPolicy{KeepLast: 1, KeepWeekly: 2},
+			[]int{1, 24, 24 * 6, 24 * 8, 24 * 13, 24 * 20}, nil, nil,
+			[]int{1, 3, 4, 5},
+		),
+		Entry("corrupt generations are not counted",
+			model.WorldRetentionPolicy{KeepLast: 2},
+			[]int{1, 2, 3, 4}, nil, []int{0},
+			[]int{0, 3},
+		),
+		Entry("corrupt generations are not counted in buckets",
+			// the newest one yesterday is corrupt
+			model.WorldRetentionPolicy{KeepLast: 1, KeepDaily: 2},
+			[]int{2, 14, 22, 48}, nil, []int{1},
+			[]int{1, 3},
+		),
+		Entry("pinned corrupt generations are kept",
+			model.WorldRetentionPolicy{KeepLast: 1},
+			[]int{1, 2, 3}, []int{0}, []int{0},
+			[]int{2},
 		),
 	)
 })
@@ -51,6 +51,7 @@ require (
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.28.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/kelseyhightower/envconfig v1.4.0 // indirect
+	github.com/klauspost/compress v1.18.5 // indirect
 	github.com/labstack/echo-contrib/v5 v5.0.1 // indirect
 	github.com/labstack/echo-opentelemetry v0.0.2 // indirect
 	github.com/labstack/echo/v5 v5.0.4 // indirect
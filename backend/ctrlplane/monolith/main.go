@@ -10,6 +10,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/kofuk/premises/backend/common/otel"
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
 	"github.com/kofuk/premises/backend/ctrlplane/common/config"
 	"github.com/kofuk/premises/backend/ctrlplane/common/cron"
 	"github.com/kofuk/premises/backend/ctrlplane/common/db"
@@ -19,9 +20,14 @@ import (
 	"github.com/kofuk/premises/backend/ctrlplane/common/launcher"
 	"github.com/kofuk/premises/backend/ctrlplane/common/launcher/server"
 	"github.com/kofuk/premises/backend/ctrlplane/common/longpoll"
+	"github.com/kofuk/premises/backend/ctrlplane/common/mcversions"
 	"github.com/kofuk/premises/backend/ctrlplane/common/proxy"
+	"github.com/kofuk/premises/backend/ctrlplane/common/schedule"
+	"github.com/kofuk/premises/backend/ctrlplane/common/servers"
 	"github.com/kofuk/premises/backend/ctrlplane/common/streaming"
+	"github.com/kofuk/premises/backend/ctrlplane/common/webhook"
 	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/uptrace/bun"
@@ -69,6 +75,22 @@ func createLongPoll(redis *redis.Client) *longpoll.LongPollService {
 	return longpoll.NewLongPollService(redis, "runner-action")
 }
 
+func createWorldService(ctx context.Context, db *bun.DB, cfg *config.Config) (*world.WorldService, error) {
+	store, err := storage.New(ctx, storage.Options{
+		Backend:          cfg.StorageBackend,
+		S3Bucket:         cfg.S3Bucket,
+		S3ForcePathStyle: cfg.S3ForcePathStyle,
+		LocalPath:        cfg.StorageLocalPath,
+		LocalBaseURL:     cfg.Origin + "/_/storage",
+		LocalSecret:      []byte(cfg.Secret),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return world.New(db, store, world.WithTempDir(cfg.UploadTempDir)), nil
+}
+
 func createKVS(redis *redis.Client) kvs.KeyValueStore {
 	return kvs.New(kvs.NewRedis(redis))
 }
@@ -100,17 +122,25 @@ func startWeb(ctx context.Context, cfg *config.Config) {
 		os.Exit(1)
 	}
 
+	if err := servers.MigrateLegacyKeys(ctx, redis); err != nil {
+		slog.ErrorContext(ctx, "Failed to migrate legacy keys", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	kvs := createKVS(redis)
 
-	launcherService := launcher.NewLauncherService(cfg, kvs, server.NewConohaServer(cfg), streaming.NewStreamingService(redis))
+	webhookService := webhook.New(db, servers.New(db, cfg.GameDomain))
+	streamingService := streaming.NewStreamingService(redis)
+	streamingService.AddHook(webhookService.HandleMessage)
+	launcherService := launcher.NewLauncherService(cfg, kvs, server.NewConohaServer(cfg), streamingService)
 
-	worldService, err := world.New(ctx, cfg.S3Bucket, cfg.S3ForcePathStyle)
+	worldService, err := createWorldService(ctx, db, cfg)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to create world service", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	handler, err := handler.NewHandler(cfg, ":10000", db, redis, worldService, createLongPoll(redis), kvs, launcherService)
+	handler, err := handler.NewHandler(cfg, ":10000", db, redis, worldService, createLongPoll(redis), kvs, launcherService, streamingService, webhookService)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to initialize handler", slog.Any("error", err))
 		os.Exit(1)
@@ -124,13 +154,19 @@ func startWeb(ctx context.Context, cfg *config.Config) {
 }
 
 func startProxy(ctx context.Context, cfg *config.Config) {
+	db, err := createDatabaseClient(cfg)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create database client", slog.Any("error", err))
+		os.Exit(1)
+	}
+
 	redis, err := createRedisClient(ctx, cfg)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to create redis client", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	proxy, err := proxy.NewProxyHandler(cfg, createKVS(redis), createLongPoll(redis))
+	proxy, err := proxy.NewProxyHandler(cfg, createKVS(redis), createLongPoll(redis), servers.New(db, cfg.GameDomain))
 	if err != nil {
 		slog.ErrorContext(ctx, "Error initializing proxy handler", slog.Any("error", err))
 		os.Exit(1)
@@ -144,14 +180,41 @@ func startProxy(ctx context.Context, cfg *config.Config) {
 }
 
 func startCron(ctx context.Context, config *config.Config) {
-	worldService, err := world.New(ctx, config.S3Bucket, config.S3ForcePathStyle)
+	db, err := createDatabaseClient(config)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create database client", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	worldService, err := createWorldService(ctx, db, config)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to create world service", slog.Any("error", err))
 		os.Exit(1)
 	}
 
+	redis, err := createRedisClient(ctx, config)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create redis client", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	kvs := createKVS(redis)
+	streamingService := streaming.NewStreamingService(redis)
+	streamingService.AddHook(webhook.New(db, servers.New(db, config.GameDomain)).HandleMessage)
+	launcherService := launcher.NewLauncherService(config, kvs, server.NewConohaServer(config), streamingService)
+
 	slog.InfoContext(ctx, "Starting cron server...")
-	cron := cron.NewCronService(config, worldService)
+	cron := cron.NewCronService(
+		config,
+		worldService,
+		servers.New(db, config.GameDomain),
+		schedule.New(db),
+		launcherService,
+		mcversions.New(kvs),
+		createLongPoll(redis),
+		streamingService,
+		audit.New(db),
+	)
 	if err := cron.Run(ctx); err != nil {
 		slog.ErrorContext(ctx, "Error in cron", slog.Any("error", err))
 		os.Exit(1)
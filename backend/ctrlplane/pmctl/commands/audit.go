@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/audit"
+	"github.com/spf13/cobra"
+)
+
+func NewAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "audit",
+		Long: "Audit log related functionality.",
+	}
+	cmd.AddCommand(NewAuditListCommand())
+
+	return cmd
+}
+
+type AuditListOptions struct {
+	Limit  int
+	Offset int
+}
+
+func NewAuditListCommand() *cobra.Command {
+	var options AuditListOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List audit log entries, newest first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunAuditList(options)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.IntVarP(&options.Limit, "limit", "n", 50, "Maximum number of entries to show")
+	flags.IntVar(&options.Offset, "offset", 0, "Number of entries to skip")
+
+	return cmd
+}
+
+func RunAuditList(options AuditListOptions) error {
+	db := createClient()
+
+	entries, total, err := audit.New(db).List(context.TODO(), options.Limit, options.Offset)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tACTOR\tACTION\tTARGET\tRESULT")
+	for _, e := range entries {
+		actor := "-"
+		if e.Actor != nil {
+			actor = e.Actor.Name
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", e.ID, e.CreatedAt.Local().Format(time.RFC3339), actor, e.Action, e.Target, e.Result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Showing %d of %d entries\n", len(entries), total)
+
+	return nil
+}
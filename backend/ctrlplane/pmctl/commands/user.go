@@ -3,10 +3,12 @@ package commands
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 
+	"github.com/kofuk/premises/backend/ctrlplane/common/auth"
 	"github.com/kofuk/premises/backend/ctrlplane/common/db"
 	"github.com/kofuk/premises/backend/ctrlplane/common/db/model"
 	"github.com/spf13/cobra"
@@ -22,6 +24,7 @@ func NewUserCommand() *cobra.Command {
 	cmd.AddCommand(NewAddCommand())
 	cmd.AddCommand(NewResetPasswordCommand())
 	cmd.AddCommand(NewRenameCommand())
+	cmd.AddCommand(NewSetScopesCommand())
 
 	return cmd
 }
@@ -31,6 +34,7 @@ type AddUserOptions struct {
 	Password      string
 	PasswordStdin bool
 	Initialized   bool
+	Scopes        []string
 }
 
 type ResetPasswordOptions struct {
@@ -44,6 +48,11 @@ type RenameOptions struct {
 	NewName string
 }
 
+type SetScopesOptions struct {
+	Name   string
+	Scopes []string
+}
+
 func NewAddCommand() *cobra.Command {
 	var options AddUserOptions
 
@@ -61,6 +70,7 @@ func NewAddCommand() *cobra.Command {
 	flags.StringVarP(&options.Password, "password", "p", "", "Password")
 	flags.BoolVar(&options.PasswordStdin, "password-stdin", false, "Read password from stdin")
 	flags.BoolVar(&options.Initialized, "initialized", false, "Mark this user as initialized")
+	flags.StringSliceVar(&options.Scopes, "scope", auth.ScopesToStrings(auth.DefaultUserScopes), "Scopes granted to the user")
 
 	return cmd
 }
@@ -104,6 +114,25 @@ func NewRenameCommand() *cobra.Command {
 	return cmd
 }
 
+func NewSetScopesCommand() *cobra.Command {
+	var options SetScopesOptions
+
+	cmd := &cobra.Command{
+		Use:   "set-scopes",
+		Short: "Replace scopes granted to an existing user",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSetScopes(options)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&options.Name, "username", "u", "", "Username")
+	flags.StringSliceVar(&options.Scopes, "scope", nil, "Scopes granted to the user")
+
+	return cmd
+}
+
 func createClient() *bun.DB {
 	host := os.Getenv("PREMISES_POSTGRES_HOST")
 	portStr := os.Getenv("PREMISES_POSTGRES_PORT")
@@ -159,6 +188,11 @@ func RunAddUser(options AddUserOptions) error {
 		}
 	}
 
+	scopes, err := auth.ParseScopes(options.Scopes)
+	if err != nil {
+		return err
+	}
+
 	db := createClient()
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -170,6 +204,7 @@ func RunAddUser(options AddUserOptions) error {
 		Name:        options.Name,
 		Password:    string(hashedPassword),
 		Initialized: options.Initialized,
+		Scopes:      auth.ScopesToStrings(scopes),
 	}
 
 	if _, err := db.NewInsert().Model(user).Exec(context.TODO()); err != nil {
@@ -212,3 +247,29 @@ func RunRename(options RenameOptions) error {
 
 	return nil
 }
+
+func RunSetScopes(options SetScopesOptions) error {
+	if len(options.Scopes) == 0 {
+		return errors.New("at least one scope must be specified with --scope")
+	}
+	scopes, err := auth.ParseScopes(options.Scopes)
+	if err != nil {
+		return err
+	}
+
+	db := createClient()
+
+	user := &model.User{
+		Scopes: auth.ScopesToStrings(scopes),
+	}
+
+	result, err := db.NewUpdate().Model(user).Column("scopes").Where("name = ? AND deleted_at IS NULL", options.Name).Exec(context.TODO())
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("user not found: %s", options.Name)
+	}
+
+	return nil
+}
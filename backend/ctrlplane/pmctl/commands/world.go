@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/kofuk/premises/backend/ctrlplane/common/world"
+	"github.com/kofuk/premises/backend/ctrlplane/common/world/storage"
+	"github.com/spf13/cobra"
+)
+
+func NewWorldCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "world",
+		Long: "World-related functionality.",
+	}
+	cmd.AddCommand(NewWorldRenameCommand())
+	cmd.AddCommand(NewWorldDuplicateCommand())
+	cmd.AddCommand(NewWorldForkCommand())
+
+	return cmd
+}
+
+type CopyWorldOptions struct {
+	Name    string
+	NewName string
+}
+
+type ForkWorldOptions struct {
+	ID      string
+	NewName string
+}
+
+func NewWorldRenameCommand() *cobra.Command {
+	var options CopyWorldOptions
+
+	cmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Rename a world with all its generations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunWorldRename(options)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&options.Name, "world", "w", "", "World name")
+	flags.StringVarP(&options.NewName, "new-name", "t", "", "New world name")
+
+	return cmd
+}
+
+func NewWorldDuplicateCommand() *cobra.Command {
+	var options CopyWorldOptions
+
+	cmd := &cobra.Command{
+		Use:   "duplicate",
+		Short: "Copy a world with all its generations to a new name",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunWorldDuplicate(options)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&options.Name, "world", "w", "", "World name")
+	flags.StringVarP(&options.NewName, "new-name", "t", "", "Name of the copy")
+
+	return cmd
+}
+
+func NewWorldForkCommand() *cobra.Command {
+	var options ForkWorldOptions
+
+	cmd := &cobra.Command{
+		Use:   "fork",
+		Short: "Create a new world from a generation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunWorldFork(options)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVar(&options.ID, "id", "", "ID of the generation (e.g. foo/2006-01-02 15:04:05.tar.zst)")
+	flags.StringVarP(&options.NewName, "new-name", "t", "", "Name of the new world")
+
+	return cmd
+}
+
+func createWorldService() *world.WorldService {
+	backend := os.Getenv("PREMISES_STORAGE_BACKEND")
+	if backend == "" {
+		backend = "s3"
+	}
+	forcePathStyle, _ := strconv.ParseBool(os.Getenv("PREMISES_S3_FORCE_PATH_STYLE"))
+
+	// pmctl doesn't issue URLs of the local storage, so its URL and secret are not needed.
+	store, err := storage.New(context.TODO(), storage.Options{
+		Backend:          backend,
+		S3Bucket:         os.Getenv("PREMISES_S3_BUCKET"),
+		S3ForcePathStyle: forcePathStyle,
+		LocalPath:        os.Getenv("PREMISES_STORAGE_LOCAL_PATH"),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to create storage client:", err)
+		os.Exit(1)
+	}
+
+	return world.New(createClient(), store)
+}
+
+func RunWorldRename(options CopyWorldOptions) error {
+	return createWorldService().RenameWorld(context.TODO(), options.Name, options.NewName)
+}
+
+func RunWorldDuplicate(options CopyWorldOptions) error {
+	return createWorldService().DuplicateWorld(context.TODO(), options.Name, options.NewName)
+}
+
+func RunWorldFork(options ForkWorldOptions) error {
+	return createWorldService().ForkWorld(context.TODO(), options.ID, options.NewName)
+}
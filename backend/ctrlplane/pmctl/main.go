@@ -14,6 +14,8 @@ func main() {
 
 	cmd.AddCommand(admincli.NewUserCommand())
 	cmd.AddCommand(admincli.NewCopyStaticCommand())
+	cmd.AddCommand(admincli.NewAuditCommand())
+	cmd.AddCommand(admincli.NewWorldCommand())
 
 	err := cmd.Execute()
 	if err != nil {
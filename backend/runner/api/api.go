@@ -52,8 +52,8 @@ func (c *Client) CreateWorldDownloadURL(ctx context.Context, worldID string) (*w
 	return &respData, nil
 }
 
-func (c *Client) CreateWorldUploadURL(ctx context.Context, worldName string) (*web.CreateWorldUploadURLResponse, error) {
-	req := web.CreateWorldUploadURLRequest{WorldName: worldName}
+func (c *Client) CreateWorldUploadURL(ctx context.Context, worldName, format string) (*web.CreateWorldUploadURLResponse, error) {
+	req := web.CreateWorldUploadURLRequest{WorldName: worldName, Format: format}
 
 	url, err := buildURL(c.endpoint, "/_/world/upload-url")
 	if err != nil {
@@ -73,6 +73,109 @@ func (c *Client) CreateWorldUploadURL(ctx context.Context, worldName string) (*w
 	return &respData, nil
 }
 
+func (c *Client) CompleteWorldUpload(ctx context.Context, req web.CompleteWorldUploadRequest) error {
+	url, err := buildURL(c.endpoint, "/_/world/upload-complete")
+	if err != nil {
+		return err
+	}
+
+	_, err = c.transport.Request(ctx, http.MethodPost, url, req)
+	return err
+}
+
+func (c *Client) createBlobURLs(ctx context.Context, path string, hashes []string) (map[string]string, error) {
+	req := web.CreateBlobURLsRequest{Hashes: hashes}
+
+	url, err := buildURL(c.endpoint, path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.transport.Request(ctx, http.MethodPost, url, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var respData web.CreateBlobURLsResponse
+	if err := json.Unmarshal(resp, &respData); err != nil {
+		return nil, err
+	}
+
+	return respData.URLs, nil
+}
+
+// CreateBlobUploadURLs returns upload URLs for blobs which don't exist yet.
+func (c *Client) CreateBlobUploadURLs(ctx context.Context, hashes []string) (map[string]string, error) {
+	return c.createBlobURLs(ctx, "/_/world/blobs/upload-urls", hashes)
+}
+
+func (c *Client) CreateBlobDownloadURLs(ctx context.Context, hashes []string) (map[string]string, error) {
+	return c.createBlobURLs(ctx, "/_/world/blobs/download-urls", hashes)
+}
+
+// GetWorldDatapacks returns datapacks attached to the world with URLs to download them.
+func (c *Client) GetWorldDatapacks(ctx context.Context, worldName string) ([]web.DatapackDownload, error) {
+	req := web.GetWorldDatapacksRequest{WorldName: worldName}
+
+	url, err := buildURL(c.endpoint, "/_/world/datapacks")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.transport.Request(ctx, http.MethodPost, url, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var respData web.GetWorldDatapacksResponse
+	if err := json.Unmarshal(resp, &respData); err != nil {
+		return nil, err
+	}
+
+	return respData.Datapacks, nil
+}
+
+// GetModSet returns files in the mod set with URLs to download them.
+func (c *Client) GetModSet(ctx context.Context, name string) ([]web.ModSetFileDownload, error) {
+	req := web.GetModSetRequest{Name: name}
+
+	url, err := buildURL(c.endpoint, "/_/modset")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.transport.Request(ctx, http.MethodPost, url, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var respData web.GetModSetResponse
+	if err := json.Unmarshal(resp, &respData); err != nil {
+		return nil, err
+	}
+
+	return respData.Files, nil
+}
+
+func (c *Client) CreateCrashReportUploadURL(ctx context.Context) (*web.CreateCrashReportUploadURLResponse, error) {
+	url, err := buildURL(c.endpoint, "/_/crashreport/upload-url")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.transport.Request(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var respData web.CreateCrashReportUploadURLResponse
+	if err := json.Unmarshal(resp, &respData); err != nil {
+		return nil, err
+	}
+
+	return &respData, nil
+}
+
 func (c *Client) GetLatestWorldID(ctx context.Context, worldName string) (*web.GetLatestWorldIDResponse, error) {
 	url, err := buildURL(c.endpoint, "/_/world/latest-id/"+worldName)
 	if err != nil {
@@ -31,7 +31,21 @@ type Server struct {
 }
 
 func (s *Server) HandleActionStop(ctx context.Context, action *runner.Action) error {
-	return rpc.ToLauncher.Notify(ctx, "game/stop", nil)
+	input := types.StopInput{
+		Actor: action.Actor,
+	}
+	// Actions from older control planes have no stop config, and stop immediately.
+	if action.Stop != nil {
+		input.Delay = action.Stop.Delay
+	}
+
+	return rpc.ToLauncher.Notify(ctx, "game/stop", input)
+}
+
+func (s *Server) HandleActionCancelStop(ctx context.Context, action *runner.Action) error {
+	return rpc.ToLauncher.Notify(ctx, "game/stop/cancel", types.CancelStopInput{
+		Actor: action.Actor,
+	})
 }
 
 func (s *Server) HandleActionSnapshot(ctx context.Context, action *runner.Action) error {
@@ -61,7 +75,10 @@ func (s *Server) HandleActionReconfigure(ctx context.Context, action *runner.Act
 		return errors.New("missing config")
 	}
 
-	return rpc.ToLauncher.Notify(ctx, "game/reconfigure", action.Config)
+	return rpc.ToLauncher.Notify(ctx, "game/reconfigure", types.ReconfigureInput{
+		Config: *action.Config,
+		Actor:  action.Actor,
+	})
 }
 
 func (s *Server) HandleActionConnRequest(ctx context.Context, action *runner.Action) error {
@@ -72,6 +89,39 @@ func (s *Server) HandleActionConnRequest(ctx context.Context, action *runner.Act
 	return rpc.ToConnector.Notify(ctx, "proxy/open", action.ConnReq)
 }
 
+func (s *Server) HandleActionConsole(ctx context.Context, action *runner.Action) error {
+	if action.Console == nil {
+		return errors.New("missing console request")
+	}
+
+	result := &runner.ConsoleExtra{
+		ID: action.Console.ID,
+	}
+
+	var output types.ConsoleOutput
+	if err := rpc.ToLauncher.Call(ctx, "game/console", types.ConsoleInput{
+		Command: action.Console.Command,
+	}, &output); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Response = output.Response
+	}
+
+	// Result is sent back to the control plane even if the command failed, because the caller is waiting for it.
+	s.msgChan <- OutboundMessage{
+		Dispatch: true,
+		Event: runner.Event{
+			Type: runner.EventConsole,
+			Metadata: runner.RequestMeta{
+				Traceparent: otel.TraceContextFromContext(ctx),
+			},
+			Console: result,
+		},
+	}
+
+	return nil
+}
+
 func NewServer(addr string, authKey string, msgChan chan OutboundMessage) *Server {
 	s := &Server{
 		client:        api.NewClient(addr, authKey, http.DefaultClient),
@@ -80,10 +130,12 @@ func NewServer(addr string, authKey string, msgChan chan OutboundMessage) *Serve
 	}
 
 	s.actionMappers[runner.ActionStop] = s.HandleActionStop
+	s.actionMappers[runner.ActionCancelStop] = s.HandleActionCancelStop
 	s.actionMappers[runner.ActionSnapshot] = s.HandleActionSnapshot
 	s.actionMappers[runner.ActionUndo] = s.HandleActionUndo
 	s.actionMappers[runner.ActionReconfigure] = s.HandleActionReconfigure
 	s.actionMappers[runner.ActionConnReq] = s.HandleActionConnRequest
+	s.actionMappers[runner.ActionConsole] = s.HandleActionConsole
 
 	return s
 }
@@ -3,11 +3,14 @@ package mclauncher
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/kofuk/premises/backend/common/entity"
 	"github.com/kofuk/premises/backend/common/entity/runner"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/quickundo"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/reconfigure"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/shutdown"
 	"github.com/kofuk/premises/backend/runner/exterior"
 	"github.com/kofuk/premises/backend/runner/rpc"
 	"github.com/kofuk/premises/backend/runner/rpc/types"
@@ -17,30 +20,48 @@ import (
 )
 
 type RPCHandler struct {
-	s                *rpc.Server
-	quickUndoService *quickundo.QuickUndoService
-	rconClient       *rcon.Rcon
+	s                  *rpc.Server
+	quickUndoService   *quickundo.QuickUndoService
+	reconfigureService *reconfigure.ReconfigureService
+	shutdownService    *shutdown.ShutdownService
+	rconClient         *rcon.Rcon
 }
 
-func NewRPCHandler(s *rpc.Server, quickUndoService *quickundo.QuickUndoService, rconClient *rcon.Rcon) *RPCHandler {
+func NewRPCHandler(s *rpc.Server, quickUndoService *quickundo.QuickUndoService, reconfigureService *reconfigure.ReconfigureService, shutdownService *shutdown.ShutdownService, rconClient *rcon.Rcon) *RPCHandler {
 	return &RPCHandler{
-		s:                s,
-		quickUndoService: quickUndoService,
-		rconClient:       rconClient,
+		s:                  s,
+		quickUndoService:   quickUndoService,
+		reconfigureService: reconfigureService,
+		shutdownService:    shutdownService,
+		rconClient:         rconClient,
 	}
 }
 
 func (h *RPCHandler) HandleGameStop(ctx context.Context, req *rpc.AbstractRequest) error {
-	if err := h.rconClient.Stop(ctx); err != nil {
+	var input types.StopInput
+	if err := req.Bind(&input); err != nil {
 		return err
 	}
 
-	exterior.DispatchEvent(ctx, runner.Event{
-		Type: runner.EventStatus,
-		Status: &runner.StatusExtra{
-			EventCode: entity.EventStopping,
-		},
-	})
+	return h.shutdownService.Schedule(ctx, time.Duration(input.Delay)*time.Second, shutdown.ReasonRequested, input.Actor)
+}
+
+func (h *RPCHandler) HandleGameStopCancel(ctx context.Context, req *rpc.AbstractRequest) error {
+	var input types.CancelStopInput
+	if err := req.Bind(&input); err != nil {
+		return err
+	}
+
+	if !h.shutdownService.Cancel(ctx, input.Actor) {
+		exterior.DispatchEvent(ctx, runner.Event{
+			Type: runner.EventInfo,
+			Info: &runner.InfoExtra{
+				InfoCode: entity.InfoNoPendingStop,
+				Actor:    input.Actor,
+				IsError:  false,
+			},
+		})
+	}
 
 	return nil
 }
@@ -107,8 +128,60 @@ func (h *RPCHandler) HandleSnapshotUndo(ctx context.Context, req *rpc.AbstractRe
 	return nil
 }
 
+func (h *RPCHandler) HandleGameReconfigure(ctx context.Context, req *rpc.AbstractRequest) error {
+	var input types.ReconfigureInput
+	if err := req.Bind(&input); err != nil {
+		return err
+	}
+
+	if err := h.reconfigureService.Apply(ctx, &input.Config); err != nil {
+		slog.ErrorContext(ctx, "Failed to reconfigure server", slog.Any("error", err))
+
+		exterior.DispatchEvent(ctx, runner.Event{
+			Type: runner.EventInfo,
+			Info: &runner.InfoExtra{
+				InfoCode: entity.InfoReconfigureError,
+				Actor:    input.Actor,
+				IsError:  true,
+			},
+		})
+
+		return err
+	}
+
+	exterior.DispatchEvent(ctx, runner.Event{
+		Type: runner.EventInfo,
+		Info: &runner.InfoExtra{
+			InfoCode: entity.InfoReconfigureDone,
+			Actor:    input.Actor,
+			IsError:  false,
+		},
+	})
+
+	return nil
+}
+
+func (h *RPCHandler) HandleGameConsole(ctx context.Context, req *rpc.AbstractRequest) (any, error) {
+	var input types.ConsoleInput
+	if err := req.Bind(&input); err != nil {
+		return nil, err
+	}
+
+	response, err := h.rconClient.Execute(ctx, input.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ConsoleOutput{
+		Response: response,
+	}, nil
+}
+
 func (h *RPCHandler) Bind() {
 	h.s.RegisterNotifyMethod("game/stop", h.HandleGameStop)
+	h.s.RegisterNotifyMethod("game/stop/cancel", h.HandleGameStopCancel)
 	h.s.RegisterNotifyMethod("snapshot/create", h.HandleSnapshotCreate)
 	h.s.RegisterNotifyMethod("snapshot/undo", h.HandleSnapshotUndo)
+	h.s.RegisterNotifyMethod("game/reconfigure", h.HandleGameReconfigure)
+	h.s.RegisterMethod("game/console", h.HandleGameConsole)
 }
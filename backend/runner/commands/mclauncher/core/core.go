@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"errors"
+	"io"
+	"sync/atomic"
 
 	"github.com/kofuk/premises/backend/runner/env"
 	"github.com/kofuk/premises/backend/runner/system"
@@ -33,6 +35,16 @@ func (c *launcherContext) State() StateRepository {
 	return c.state
 }
 
+// WithContext returns a copy of c which has ctx as its context.
+func WithContext(c LauncherContext, ctx context.Context) LauncherContext {
+	return &launcherContext{
+		ctx:      ctx,
+		settings: c.Settings(),
+		env:      c.Env(),
+		state:    c.State(),
+	}
+}
+
 type HandlerFunc func(c LauncherContext) error
 
 type Middleware interface {
@@ -50,12 +62,16 @@ func (m *stopMiddleware) Wrap(next HandlerFunc) HandlerFunc {
 var StopMiddleware Middleware = &stopMiddleware{}
 
 type LauncherCore struct {
-	handler               HandlerFunc
-	settings              SettingsRepository
-	env                   env.EnvProvider
-	CommandExecutor       system.CommandExecutor
+	handler         HandlerFunc
+	settings        SettingsRepository
+	env             env.EnvProvider
+	CommandExecutor system.CommandExecutor
+	// If set, output of Minecraft server is copied to this writer.
+	Output                io.Writer
 	state                 StateRepository
 	beforeLaunchListeners []BeforeLaunchListener
+	crashListeners        []CrashListener
+	restartRequested      atomic.Bool
 }
 
 func NewLauncherCore(settings SettingsRepository, env env.EnvProvider, state StateRepository) *LauncherCore {
@@ -81,6 +97,15 @@ func (l *LauncherCore) AddBeforeLaunchListener(listener BeforeLaunchListener) {
 	l.beforeLaunchListeners = append(l.beforeLaunchListeners, listener)
 }
 
+// RequestRestart makes the launcher start the server again when it exits normally next time.
+func (l *LauncherCore) RequestRestart() {
+	l.restartRequested.Store(true)
+}
+
+func (l *LauncherCore) CancelRestart() {
+	l.restartRequested.Store(false)
+}
+
 func (l *LauncherCore) createContext(ctx context.Context) LauncherContext {
 	return &launcherContext{
 		ctx:      ctx,
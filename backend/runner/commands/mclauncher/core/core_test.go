@@ -32,10 +32,13 @@ var _ = Describe("LauncherCore", func() {
 
 		sut = core.NewLauncherCore(settingsRepository, envProvider, stateRepository)
 		sut.CommandExecutor = executor
+
+		settingsRepository.EXPECT().SetCommandLine(gomock.Any()).AnyTimes()
 	})
 
 	It("should launch successfully", func() {
 		settingsRepository.EXPECT().GetServerPath().Return("/usr/bin/true")
+		settingsRepository.EXPECT().GetLaunchArgs().Return(nil)
 		executor.EXPECT().Start(gomock.Any(), "/usr/bin/true", []string{}, gomock.Any()).Times(1).Return(&system.CommandHandle{}, nil)
 		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
 
@@ -43,12 +46,110 @@ var _ = Describe("LauncherCore", func() {
 		Expect(err).ShouldNot(HaveOccurred())
 	})
 
+	It("should launch with Java using launch args", func() {
+		settingsRepository.EXPECT().GetServerPath().Return("/servers.d/1.21.jar")
+		settingsRepository.EXPECT().GetLaunchArgs().Return([]string{"@/servers.d/forge/args.txt"})
+		settingsRepository.EXPECT().GetAllowedMemSize(gomock.Any()).Return(1024)
+		settingsRepository.EXPECT().GetJVMProfile().Return("")
+		settingsRepository.EXPECT().GetOtlpEndpoint().Return("")
+		executor.EXPECT().Start(gomock.Any(), gomock.Any(), []string{"-Xmx1024M", "-Xms1024M", "@/servers.d/forge/args.txt", "nogui"}, gomock.Any()).Return(&system.CommandHandle{}, nil)
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
+
+		err := sut.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should launch with Aikar's flags", func() {
+		settingsRepository.EXPECT().GetServerPath().Return("/servers.d/1.21.jar")
+		settingsRepository.EXPECT().GetLaunchArgs().Return([]string{"@/servers.d/forge/args.txt"})
+		settingsRepository.EXPECT().GetAllowedMemSize(gomock.Any()).Return(16 * 1024)
+		settingsRepository.EXPECT().GetJVMProfile().Return("aikar")
+		settingsRepository.EXPECT().GetOtlpEndpoint().Return("")
+		executor.EXPECT().Start(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ any, args []string, _ ...any) (*system.CommandHandle, error) {
+			Expect(args[:3]).To(Equal([]string{"-Xmx16384M", "-Xms16384M", "-XX:+UseG1GC"}))
+			Expect(args).To(ContainElement("-XX:G1HeapRegionSize=16M"))
+			Expect(args[len(args)-2:]).To(Equal([]string{"@/servers.d/forge/args.txt", "nogui"}))
+			return &system.CommandHandle{}, nil
+		})
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
+
+		err := sut.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should launch with custom JVM options", func() {
+		settingsRepository.EXPECT().GetServerPath().Return("/servers.d/1.21.jar")
+		settingsRepository.EXPECT().GetLaunchArgs().Return([]string{"@/servers.d/forge/args.txt"})
+		settingsRepository.EXPECT().GetAllowedMemSize(gomock.Any()).Return(1024)
+		settingsRepository.EXPECT().GetJVMProfile().Return("custom")
+		settingsRepository.EXPECT().GetJVMArgs().Return([]string{"-XX:+UseShenandoahGC"})
+		settingsRepository.EXPECT().GetOtlpEndpoint().Return("")
+		executor.EXPECT().Start(gomock.Any(), gomock.Any(), []string{"-Xmx1024M", "-Xms1024M", "-XX:+UseShenandoahGC", "@/servers.d/forge/args.txt", "nogui"}, gomock.Any()).Return(&system.CommandHandle{}, nil)
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
+
+		err := sut.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should not use ZGC with a small heap", func() {
+		settingsRepository.EXPECT().GetServerPath().Return("/servers.d/1.21.jar")
+		settingsRepository.EXPECT().GetLaunchArgs().Return([]string{"@/servers.d/forge/args.txt"})
+		settingsRepository.EXPECT().GetAllowedMemSize(gomock.Any()).Return(1024)
+		settingsRepository.EXPECT().GetJVMProfile().Return("zgc")
+		settingsRepository.EXPECT().GetOtlpEndpoint().Return("")
+		executor.EXPECT().Start(gomock.Any(), gomock.Any(), []string{"-Xmx1024M", "-Xms1024M", "@/servers.d/forge/args.txt", "nogui"}, gomock.Any()).Return(&system.CommandHandle{}, nil)
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
+
+		err := sut.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
 	It("should retry in case of failure", func() {
 		settingsRepository.EXPECT().GetServerPath().Return("/usr/bin/false")
+		settingsRepository.EXPECT().GetLaunchArgs().Return(nil)
 		gomock.InOrder(
 			executor.EXPECT().Start(gomock.Any(), "/usr/bin/false", []string{}, gomock.Any()).Times(2).Return(nil, errors.New("error")),
 			executor.EXPECT().Start(gomock.Any(), "/usr/bin/false", []string{}, gomock.Any()).Return(&system.CommandHandle{}, nil),
 		)
+		settingsRepository.EXPECT().GetMaxCrashLoops().AnyTimes().Return(0)
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
+
+		err := sut.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should give up after crashing repeatedly", func() {
+		settingsRepository.EXPECT().GetServerPath().Return("/usr/bin/false")
+		settingsRepository.EXPECT().GetLaunchArgs().Return(nil)
+		settingsRepository.EXPECT().GetMaxCrashLoops().AnyTimes().Return(2)
+		executor.EXPECT().Start(gomock.Any(), "/usr/bin/false", []string{}, gomock.Any()).Times(2).Return(nil, errors.New("error"))
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
+
+		var crashes []core.Crash
+		sut.AddCrashListener(func(c core.LauncherContext, crash *core.Crash) error {
+			crashes = append(crashes, *crash)
+			return nil
+		})
+
+		err := sut.Start(GinkgoT().Context())
+		Expect(err).To(MatchError(core.ErrCrashLoop))
+		Expect(crashes).To(HaveLen(2))
+		Expect(crashes[0].Count).To(Equal(1))
+		Expect(crashes[0].GivingUp).To(BeFalse())
+		Expect(crashes[1].Count).To(Equal(2))
+		Expect(crashes[1].GivingUp).To(BeTrue())
+	})
+
+	It("should start again if restart is requested", func() {
+		settingsRepository.EXPECT().GetServerPath().Return("/usr/bin/true")
+		settingsRepository.EXPECT().GetLaunchArgs().Return(nil)
+		gomock.InOrder(
+			executor.EXPECT().Start(gomock.Any(), "/usr/bin/true", []string{}, gomock.Any()).DoAndReturn(func(_, _, _ any, _ ...any) (*system.CommandHandle, error) {
+				sut.RequestRestart()
+				return &system.CommandHandle{}, nil
+			}),
+			executor.EXPECT().Start(gomock.Any(), "/usr/bin/true", []string{}, gomock.Any()).Return(&system.CommandHandle{}, nil),
+		)
 		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().Return("/tmp")
 
 		err := sut.Start(GinkgoT().Context())
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxCrashLoops is the number of consecutive crashes after which the server is not restarted anymore,
+// if it is not configured.
+const DefaultMaxCrashLoops = 5
+
+// crashLoopResetTime is how long the server has to keep running to be regarded as recovered from previous crashes.
+const crashLoopResetTime = 10 * time.Minute
+
+// logTailSize is the size of the last output of the server which is kept to report crashes.
+const logTailSize = 64 * 1024
+
+var ErrCrashLoop = errors.New("server crashed repeatedly")
+
+// ExitError is the error of the Minecraft server which exited unsuccessfully.
+type ExitError struct {
+	ProcessState *os.ProcessState
+}
+
+func (e *ExitError) Error() string {
+	return e.ProcessState.String()
+}
+
+// Crash describes an abnormal exit of the Minecraft server.
+type Crash struct {
+	// Err is the error which the server exited with.
+	// It is nil if the server exited successfully but wrote a crash report.
+	Err error
+	// ReportPath is the newest crash report which the server wrote, or empty if there's none.
+	ReportPath string
+	// LogTail is the last part of the output of the server.
+	LogTail []byte
+	// Count is the number of consecutive crashes including this one.
+	Count int
+	// GivingUp is true if the server is not restarted anymore.
+	GivingUp bool
+}
+
+type CrashListener func(c LauncherContext, crash *Crash) error
+
+func (l *LauncherCore) AddCrashListener(listener CrashListener) {
+	l.crashListeners = append(l.crashListeners, listener)
+}
+
+// newestCrashReport returns the newest crash report in dir written after since, or empty string if there's none.
+func newestCrashReport(dir string, since time.Time) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest = filepath.Join(dir, entry.Name())
+			newestTime = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// tailWriter keeps the last part of data written to it.
+type tailWriter struct {
+	m    sync.Mutex
+	buf  []byte
+	size int
+	// truncated is true if the head of the data was dropped.
+	truncated bool
+}
+
+func newTailWriter(size int) *tailWriter {
+	return &tailWriter{
+		size: size,
+	}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.size {
+		w.buf = append(w.buf[:0], w.buf[len(w.buf)-w.size:]...)
+		w.truncated = true
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) Reset() {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	w.buf = w.buf[:0]
+	w.truncated = false
+}
+
+// Bytes returns the kept data. If the head of the data was dropped, it starts from the next line.
+func (w *tailWriter) Bytes() []byte {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	data := w.buf
+	if w.truncated {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return bytes.Clone(data)
+}
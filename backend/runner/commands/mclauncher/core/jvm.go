@@ -0,0 +1,94 @@
+package core
+
+import (
+	"log/slog"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	coreUtil "github.com/kofuk/premises/backend/runner/commands/mclauncher/core/util"
+)
+
+// aikarFlags are the G1 options recommended by Aikar (https://docs.papermc.io/paper/aikars-flags).
+var aikarFlags = []string{
+	"-XX:+UseG1GC",
+	"-XX:+ParallelRefProcEnabled",
+	"-XX:MaxGCPauseMillis=200",
+	"-XX:+UnlockExperimentalVMOptions",
+	"-XX:+DisableExplicitGC",
+	"-XX:+AlwaysPreTouch",
+	"-XX:G1HeapWastePercent=5",
+	"-XX:G1MixedGCCountTarget=4",
+	"-XX:G1MixedGCLiveThresholdPercent=90",
+	"-XX:G1RSetUpdatingPauseIntervalPercent=5",
+	"-XX:SurvivorRatio=32",
+	"-XX:+PerfDisableSharedMem",
+	"-XX:MaxTenuringThreshold=1",
+	"-Dusing.aikars.flags=https://mcflags.emc.gs",
+	"-Daikars.new.flags=true",
+}
+
+// aikarLargeHeapSize is the heap size in MiB from which Aikar's flags for large heaps are used.
+const aikarLargeHeapSize = 12 * 1024
+
+func aikarSizeFlags(heapSize int) []string {
+	if heapSize >= aikarLargeHeapSize {
+		return []string{
+			"-XX:G1NewSizePercent=40",
+			"-XX:G1MaxNewSizePercent=50",
+			"-XX:G1HeapRegionSize=16M",
+			"-XX:G1ReservePercent=15",
+			"-XX:InitiatingHeapOccupancyPercent=20",
+		}
+	}
+	return []string{
+		"-XX:G1NewSizePercent=30",
+		"-XX:G1MaxNewSizePercent=40",
+		"-XX:G1HeapRegionSize=8M",
+		"-XX:G1ReservePercent=20",
+		"-XX:InitiatingHeapOccupancyPercent=15",
+	}
+}
+
+func zgcFlags(javaVersion int) []string {
+	flags := []string{"-XX:+UseZGC"}
+	if javaVersion < 23 {
+		// ZGC is generational by default since Java 23.
+		flags = append(flags, "-XX:+ZGenerational")
+	}
+	return append(flags, "-XX:+AlwaysPreTouch", "-XX:+DisableExplicitGC", "-XX:+PerfDisableSharedMem")
+}
+
+// jvmProfileOptions returns JVM options of the profile configured by the user.
+// If the profile is unavailable with the Java installation or the heap size, no options are returned,
+// which is the same as the default profile.
+func jvmProfileOptions(c LauncherContext, javaPath string, heapSize int) []string {
+	switch profile := c.Settings().GetJVMProfile(); profile {
+	case "", runner.JVMProfileDefault:
+		return nil
+
+	case runner.JVMProfileAikar:
+		return append(append([]string{}, aikarFlags...), aikarSizeFlags(heapSize)...)
+
+	case runner.JVMProfileZGC:
+		if heapSize < runner.ZGCMinHeapSize {
+			slog.WarnContext(c.Context(), "Heap is too small for ZGC, using the default profile", slog.Int("heap_size", heapSize))
+			return nil
+		}
+		javaVersion, err := coreUtil.FindJavaVersion(c.Context(), javaPath)
+		if err != nil {
+			slog.WarnContext(c.Context(), "Unable to detect Java version, using the default profile", slog.Any("error", err))
+			return nil
+		}
+		if javaVersion < runner.ZGCMinJavaVersion {
+			slog.WarnContext(c.Context(), "Java is too old for ZGC, using the default profile", slog.Int("java_version", javaVersion))
+			return nil
+		}
+		return zgcFlags(javaVersion)
+
+	case runner.JVMProfileCustom:
+		return c.Settings().GetJVMArgs()
+
+	default:
+		slog.WarnContext(c.Context(), "Unknown JVM profile, using the default profile", slog.String("profile", profile))
+		return nil
+	}
+}
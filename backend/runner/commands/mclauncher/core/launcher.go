@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math/rand/v2"
+	"path/filepath"
 	"time"
 
 	coreUtil "github.com/kofuk/premises/backend/runner/commands/mclauncher/core/util"
@@ -15,6 +16,13 @@ import (
 
 func (l *LauncherCore) executeWithBackOff(c LauncherContext, cmdline []string, workDir string) error {
 	backOffWaitTime := 2
+	crashCount := 0
+
+	logTail := newTailWriter(logTailSize)
+	options := []system.CmdOption{system.WithWorkingDir(workDir), system.WithOutputTee(logTail)}
+	if l.Output != nil {
+		options = append(options, system.WithOutputTee(l.Output))
+	}
 
 	for {
 		for _, listener := range l.beforeLaunchListeners {
@@ -23,8 +31,13 @@ func (l *LauncherCore) executeWithBackOff(c LauncherContext, cmdline []string, w
 			}
 		}
 
+		logTail.Reset()
+		startedAt := time.Now()
+
+		var reportPath string
+
 		slog.DebugContext(c.Context(), "Starting minecraft server...")
-		handle, err := l.CommandExecutor.Start(c.Context(), cmdline[0], cmdline[1:], system.WithWorkingDir(workDir))
+		handle, err := l.CommandExecutor.Start(c.Context(), cmdline[0], cmdline[1:], options...)
 		if err != nil {
 			slog.ErrorContext(c.Context(), "Failed to start Minecraft server", slog.Any("error", err))
 		} else {
@@ -32,18 +45,64 @@ func (l *LauncherCore) executeWithBackOff(c LauncherContext, cmdline []string, w
 				Pid: handle.Pid,
 			}, nil)
 
-			err := handle.Wait()
+			err = handle.Wait()
+			if state := handle.ProcessState(); err == nil && state != nil && !state.Success() {
+				err = &ExitError{ProcessState: state}
+			}
 
 			rpc.ToMeter.Call(c.Context(), "target/unregister", types.RegisterMeterTargetInput{
 				Pid: handle.Pid,
 			}, nil)
 
-			if err != nil {
-				slog.ErrorContext(c.Context(), "Minecraft server exited with error", slog.Any("error", err))
-			} else {
+			if c.Context().Err() != nil {
+				// The server was killed because the runner is shutting down.
+				return err
+			}
+
+			// Some crashes are reported only with crash reports.
+			reportPath = newestCrashReport(filepath.Join(workDir, "crash-reports"), startedAt)
+
+			if err == nil && l.restartRequested.CompareAndSwap(true, false) {
+				slog.InfoContext(c.Context(), "Minecraft server exited, restarting as requested")
+				backOffWaitTime = 2
+				crashCount = 0
+				continue
+			} else if err == nil && reportPath == "" {
 				slog.InfoContext(c.Context(), "Minecraft server exited")
 				return nil
 			}
+
+			slog.ErrorContext(c.Context(), "Minecraft server crashed", slog.Any("error", err), slog.String("crash_report", reportPath))
+		}
+
+		if time.Since(startedAt) >= crashLoopResetTime {
+			// It ran for a while, so this is not a crash loop.
+			backOffWaitTime = 2
+			crashCount = 0
+		}
+		crashCount++
+
+		maxCrashLoops := c.Settings().GetMaxCrashLoops()
+		if maxCrashLoops <= 0 {
+			maxCrashLoops = DefaultMaxCrashLoops
+		}
+
+		crash := &Crash{
+			Err:        err,
+			ReportPath: reportPath,
+			LogTail:    logTail.Bytes(),
+			Count:      crashCount,
+			GivingUp:   crashCount >= maxCrashLoops,
+		}
+		for _, listener := range l.crashListeners {
+			if err := listener(c, crash); err != nil {
+				slog.ErrorContext(c.Context(), "failed to execute crash listener", slog.Any("error", err))
+			}
+		}
+
+		if crash.GivingUp {
+			slog.ErrorContext(c.Context(), "Minecraft server crashed repeatedly, giving up", slog.Int("count", crashCount))
+			return ErrCrashLoop
 		}
 
 		timer := time.NewTimer(time.Duration(backOffWaitTime)*time.Second + time.Duration(rand.Float64()*500.0)*time.Millisecond)
@@ -57,33 +116,44 @@ func (l *LauncherCore) executeWithBackOff(c LauncherContext, cmdline []string, w
 	}
 }
 
+// javaCommandLine returns the Java command with JVM options.
+func javaCommandLine(c LauncherContext) []string {
+	memSize := c.Settings().GetAllowedMemSize(c.Context())
+	javaPath := coreUtil.FindJavaPath(c.Context())
+	commandLine := []string{
+		javaPath,
+		fmt.Sprintf("-Xmx%dM", memSize),
+		fmt.Sprintf("-Xms%dM", memSize),
+	}
+	commandLine = append(commandLine, jvmProfileOptions(c, javaPath, memSize)...)
+
+	if otlpEndpoint := c.Settings().GetOtlpEndpoint(); otlpEndpoint != "" {
+		commandLine = append(
+			commandLine,
+			fmt.Sprintf("-javaagent:%s", c.Env().GetDataPath("resources/opentelemetry-javaagent.jar")),
+			fmt.Sprintf("-Dotel.javaagent.configuration-file=%s", c.Env().GetDataPath("resources/opentelemetry-javaagent.properties")),
+			"-Dotel.service.name=minecraft-server",
+			fmt.Sprintf("-Dotel.exporter.otlp.endpoint=%s", otlpEndpoint),
+			fmt.Sprintf("-Dotel.metric.export.interval=%d", max(c.Settings().GetMetricExportIntervalMs(), 1000)),
+		)
+	}
+
+	return commandLine
+}
+
 func (l *LauncherCore) startMinecraft(c LauncherContext) error {
 	serverPath := c.Settings().GetServerPath()
 	workDir := c.Env().GetDataPath("gamedata")
 
 	var commandLine []string
-	if util.IsJar(c.Context(), serverPath) {
+	if launchArgs := c.Settings().GetLaunchArgs(); len(launchArgs) > 0 {
+		// Server flavors tell how to launch them, e.g. with an argument file.
+		commandLine = append(javaCommandLine(c), launchArgs...)
+		commandLine = append(commandLine, "nogui")
+	} else if util.IsJar(c.Context(), serverPath) {
 		// If this is JAR file, execute it with Java.
-		memSize := c.Settings().GetAllowedMemSize(c.Context())
-		commandLine = []string{
-			coreUtil.FindJavaPath(c.Context()),
-			fmt.Sprintf("-Xmx%dM", memSize),
-			fmt.Sprintf("-Xms%dM", memSize),
-		}
-
-		if otlpEndpoint := c.Settings().GetOtlpEndpoint(); otlpEndpoint != "" {
-			commandLine = append(
-				commandLine,
-				fmt.Sprintf("-javaagent:%s", c.Env().GetDataPath("resources/opentelemetry-javaagent.jar")),
-				fmt.Sprintf("-Dotel.javaagent.configuration-file=%s", c.Env().GetDataPath("resources/opentelemetry-javaagent.properties")),
-				"-Dotel.service.name=minecraft-server",
-				fmt.Sprintf("-Dotel.exporter.otlp.endpoint=%s", otlpEndpoint),
-				fmt.Sprintf("-Dotel.metric.export.interval=%d", max(c.Settings().GetMetricExportIntervalMs(), 1000)),
-			)
-		}
-
 		commandLine = append(
-			commandLine,
+			javaCommandLine(c),
 			"-jar",
 			serverPath,
 			"nogui",
@@ -94,6 +164,7 @@ func (l *LauncherCore) startMinecraft(c LauncherContext) error {
 			serverPath,
 		}
 	}
+	c.Settings().SetCommandLine(commandLine)
 
 	return l.executeWithBackOff(c, commandLine, workDir)
 }
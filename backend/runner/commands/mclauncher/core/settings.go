@@ -8,18 +8,37 @@ type SettingsRepository interface {
 	GetAllowedMemSize(ctx context.Context) int
 	GetServerPath() string
 	SetServerPath(path string)
+	// GetLaunchArgs returns arguments following JVM options to launch the server.
+	// If they are empty, the server path is launched.
+	GetLaunchArgs() []string
+	SetLaunchArgs(args []string)
 	GetMinecraftVersion() string
 	SetMinecraftVersion(version string)
+	GetServerFlavor() string
+	GetModSet() string
 	AutoVersionEnabled() bool
 	GetWorldName() string
 	GetWorldResourceID() string
 	SetWorldResourceID(resourceID string)
 	IsNewWorld() bool
 	GetMotd() string
+	SetMotd(motd string)
 	GetDifficulty() string
+	SetDifficulty(difficulty string)
 	GetLevelType() string
 	GetSeed() string
 	ServerPropertiesOverrides() map[string]string
+	SetServerPropertiesOverrides(overrides map[string]string)
 	GetOtlpEndpoint() string
 	GetMetricExportIntervalMs() int
+	// GetMaxCrashLoops returns the number of consecutive crashes after which the server is not restarted anymore.
+	// Zero means DefaultMaxCrashLoops.
+	GetMaxCrashLoops() int
+	// GetJVMProfile returns the set of JVM options to launch the server with. Empty means the default profile.
+	GetJVMProfile() string
+	// GetJVMArgs returns the extra JVM options of the custom JVM profile.
+	GetJVMArgs() []string
+	// GetCommandLine returns the command which the server was launched with.
+	GetCommandLine() []string
+	SetCommandLine(commandLine []string)
 }
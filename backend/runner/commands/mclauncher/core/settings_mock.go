@@ -68,6 +68,20 @@ func (mr *MockSettingsRepositoryMockRecorder) GetAllowedMemSize(ctx any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllowedMemSize", reflect.TypeOf((*MockSettingsRepository)(nil).GetAllowedMemSize), ctx)
 }
 
+// GetCommandLine mocks base method.
+func (m *MockSettingsRepository) GetCommandLine() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommandLine")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetCommandLine indicates an expected call of GetCommandLine.
+func (mr *MockSettingsRepositoryMockRecorder) GetCommandLine() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommandLine", reflect.TypeOf((*MockSettingsRepository)(nil).GetCommandLine))
+}
+
 // GetDifficulty mocks base method.
 func (m *MockSettingsRepository) GetDifficulty() string {
 	m.ctrl.T.Helper()
@@ -82,6 +96,48 @@ func (mr *MockSettingsRepositoryMockRecorder) GetDifficulty() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDifficulty", reflect.TypeOf((*MockSettingsRepository)(nil).GetDifficulty))
 }
 
+// GetJVMArgs mocks base method.
+func (m *MockSettingsRepository) GetJVMArgs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJVMArgs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetJVMArgs indicates an expected call of GetJVMArgs.
+func (mr *MockSettingsRepositoryMockRecorder) GetJVMArgs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJVMArgs", reflect.TypeOf((*MockSettingsRepository)(nil).GetJVMArgs))
+}
+
+// GetJVMProfile mocks base method.
+func (m *MockSettingsRepository) GetJVMProfile() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJVMProfile")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetJVMProfile indicates an expected call of GetJVMProfile.
+func (mr *MockSettingsRepositoryMockRecorder) GetJVMProfile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJVMProfile", reflect.TypeOf((*MockSettingsRepository)(nil).GetJVMProfile))
+}
+
+// GetLaunchArgs mocks base method.
+func (m *MockSettingsRepository) GetLaunchArgs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLaunchArgs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetLaunchArgs indicates an expected call of GetLaunchArgs.
+func (mr *MockSettingsRepositoryMockRecorder) GetLaunchArgs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLaunchArgs", reflect.TypeOf((*MockSettingsRepository)(nil).GetLaunchArgs))
+}
+
 // GetLevelType mocks base method.
 func (m *MockSettingsRepository) GetLevelType() string {
 	m.ctrl.T.Helper()
@@ -96,6 +152,20 @@ func (mr *MockSettingsRepositoryMockRecorder) GetLevelType() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLevelType", reflect.TypeOf((*MockSettingsRepository)(nil).GetLevelType))
 }
 
+// GetMaxCrashLoops mocks base method.
+func (m *MockSettingsRepository) GetMaxCrashLoops() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxCrashLoops")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// GetMaxCrashLoops indicates an expected call of GetMaxCrashLoops.
+func (mr *MockSettingsRepositoryMockRecorder) GetMaxCrashLoops() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxCrashLoops", reflect.TypeOf((*MockSettingsRepository)(nil).GetMaxCrashLoops))
+}
+
 // GetMetricExportIntervalMs mocks base method.
 func (m *MockSettingsRepository) GetMetricExportIntervalMs() int {
 	m.ctrl.T.Helper()
@@ -124,6 +194,20 @@ func (mr *MockSettingsRepositoryMockRecorder) GetMinecraftVersion() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMinecraftVersion", reflect.TypeOf((*MockSettingsRepository)(nil).GetMinecraftVersion))
 }
 
+// GetModSet mocks base method.
+func (m *MockSettingsRepository) GetModSet() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetModSet")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetModSet indicates an expected call of GetModSet.
+func (mr *MockSettingsRepositoryMockRecorder) GetModSet() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetModSet", reflect.TypeOf((*MockSettingsRepository)(nil).GetModSet))
+}
+
 // GetMotd mocks base method.
 func (m *MockSettingsRepository) GetMotd() string {
 	m.ctrl.T.Helper()
@@ -166,6 +250,20 @@ func (mr *MockSettingsRepositoryMockRecorder) GetSeed() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeed", reflect.TypeOf((*MockSettingsRepository)(nil).GetSeed))
 }
 
+// GetServerFlavor mocks base method.
+func (m *MockSettingsRepository) GetServerFlavor() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServerFlavor")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetServerFlavor indicates an expected call of GetServerFlavor.
+func (mr *MockSettingsRepositoryMockRecorder) GetServerFlavor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerFlavor", reflect.TypeOf((*MockSettingsRepository)(nil).GetServerFlavor))
+}
+
 // GetServerPath mocks base method.
 func (m *MockSettingsRepository) GetServerPath() string {
 	m.ctrl.T.Helper()
@@ -236,6 +334,42 @@ func (mr *MockSettingsRepositoryMockRecorder) ServerPropertiesOverrides() *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServerPropertiesOverrides", reflect.TypeOf((*MockSettingsRepository)(nil).ServerPropertiesOverrides))
 }
 
+// SetCommandLine mocks base method.
+func (m *MockSettingsRepository) SetCommandLine(commandLine []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCommandLine", commandLine)
+}
+
+// SetCommandLine indicates an expected call of SetCommandLine.
+func (mr *MockSettingsRepositoryMockRecorder) SetCommandLine(commandLine any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCommandLine", reflect.TypeOf((*MockSettingsRepository)(nil).SetCommandLine), commandLine)
+}
+
+// SetDifficulty mocks base method.
+func (m *MockSettingsRepository) SetDifficulty(difficulty string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDifficulty", difficulty)
+}
+
+// SetDifficulty indicates an expected call of SetDifficulty.
+func (mr *MockSettingsRepositoryMockRecorder) SetDifficulty(difficulty any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDifficulty", reflect.TypeOf((*MockSettingsRepository)(nil).SetDifficulty), difficulty)
+}
+
+// SetLaunchArgs mocks base method.
+func (m *MockSettingsRepository) SetLaunchArgs(args []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLaunchArgs", args)
+}
+
+// SetLaunchArgs indicates an expected call of SetLaunchArgs.
+func (mr *MockSettingsRepositoryMockRecorder) SetLaunchArgs(args any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLaunchArgs", reflect.TypeOf((*MockSettingsRepository)(nil).SetLaunchArgs), args)
+}
+
 // SetMinecraftVersion mocks base method.
 func (m *MockSettingsRepository) SetMinecraftVersion(version string) {
 	m.ctrl.T.Helper()
@@ -248,6 +382,18 @@ func (mr *MockSettingsRepositoryMockRecorder) SetMinecraftVersion(version any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMinecraftVersion", reflect.TypeOf((*MockSettingsRepository)(nil).SetMinecraftVersion), version)
 }
 
+// SetMotd mocks base method.
+func (m *MockSettingsRepository) SetMotd(motd string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMotd", motd)
+}
+
+// SetMotd indicates an expected call of SetMotd.
+func (mr *MockSettingsRepositoryMockRecorder) SetMotd(motd any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMotd", reflect.TypeOf((*MockSettingsRepository)(nil).SetMotd), motd)
+}
+
 // SetServerPath mocks base method.
 func (m *MockSettingsRepository) SetServerPath(path string) {
 	m.ctrl.T.Helper()
@@ -260,6 +406,18 @@ func (mr *MockSettingsRepositoryMockRecorder) SetServerPath(path any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServerPath", reflect.TypeOf((*MockSettingsRepository)(nil).SetServerPath), path)
 }
 
+// SetServerPropertiesOverrides mocks base method.
+func (m *MockSettingsRepository) SetServerPropertiesOverrides(overrides map[string]string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetServerPropertiesOverrides", overrides)
+}
+
+// SetServerPropertiesOverrides indicates an expected call of SetServerPropertiesOverrides.
+func (mr *MockSettingsRepositoryMockRecorder) SetServerPropertiesOverrides(overrides any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServerPropertiesOverrides", reflect.TypeOf((*MockSettingsRepository)(nil).SetServerPropertiesOverrides), overrides)
+}
+
 // SetWorldResourceID mocks base method.
 func (m *MockSettingsRepository) SetWorldResourceID(resourceID string) {
 	m.ctrl.T.Helper()
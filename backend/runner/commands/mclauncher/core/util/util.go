@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"os/exec"
+	"regexp"
+	"strconv"
 
 	"github.com/kofuk/go-queryalternatives"
 	"github.com/kofuk/premises/backend/runner/system"
@@ -34,3 +37,34 @@ func FindJavaPath(ctx context.Context) string {
 
 	return path
 }
+
+var javaVersionRegexp = regexp.MustCompile(`version "(\d+)(?:\.(\d+))?`)
+
+// parseJavaVersion extracts the major version from the output of `java -version`.
+func parseJavaVersion(output string) (int, error) {
+	match := javaVersionRegexp.FindStringSubmatch(output)
+	if match == nil {
+		return 0, errors.New("unknown java version")
+	}
+
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, err
+	}
+	if major == 1 && match[2] != "" {
+		// Java 8 and older are versioned like 1.8.0
+		return strconv.Atoi(match[2])
+	}
+	return major, nil
+}
+
+// FindJavaVersion returns the major version of the Java installation.
+func FindJavaVersion(ctx context.Context, javaPath string) (int, error) {
+	// `java -version` prints to stderr.
+	output, err := exec.CommandContext(ctx, javaPath, "-version").CombinedOutput()
+	if err != nil {
+		return 0, err
+	}
+
+	return parseJavaVersion(string(output))
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("parseJavaVersion", func(output string, version int, valid bool) {
+	actual, err := parseJavaVersion(output)
+	if valid {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(version))
+	} else {
+		Expect(err).To(HaveOccurred())
+	}
+},
+	Entry("OpenJDK 21", "openjdk version \"21.0.2\" 2024-01-16\nOpenJDK Runtime Environment (build 21.0.2+13-Ubuntu-122.04.1)\n", 21, true),
+	Entry("OpenJDK 17", "openjdk version \"17.0.10\" 2024-01-16\n", 17, true),
+	Entry("without minor version", "openjdk version \"22\" 2024-03-19\n", 22, true),
+	Entry("Java 8", "openjdk version \"1.8.0_402\"\n", 8, true),
+	Entry("not Java", "bash: java: command not found\n", 0, false),
+)
+
+var _ = Describe("FindJavaVersion", func() {
+	fakeJava := func(script string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "java")
+		Expect(os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)).To(Succeed())
+		return path
+	}
+
+	It("should read the version printed to stderr", func() {
+		javaPath := fakeJava(`echo 'openjdk version "21.0.4" 2024-07-16' >&2`)
+
+		version, err := FindJavaVersion(GinkgoT().Context(), javaPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal(21))
+	})
+
+	It("should fail if java fails", func() {
+		javaPath := fakeJava(`exit 1`)
+
+		_, err := FindJavaVersion(GinkgoT().Context(), javaPath)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Util Suite")
+}
@@ -0,0 +1,128 @@
+package crashreport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/retry"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/exterior"
+)
+
+// maxCrashReportSize is the size of crash reports which are included in the uploaded report.
+// Crash reports are usually a few dozens of KiB.
+const maxCrashReportSize = 1024 * 1024
+
+type Uploader interface {
+	CreateCrashReportUploadURL(ctx context.Context) (*web.CreateCrashReportUploadURLResponse, error)
+}
+
+// CrashReportService uploads crash reports and the last output of the server when it crashes.
+type CrashReportService struct {
+	uploader   Uploader
+	httpClient *http.Client
+}
+
+func NewCrashReportService(uploader Uploader, httpClient *http.Client) *CrashReportService {
+	return &CrashReportService{
+		uploader:   uploader,
+		httpClient: httpClient,
+	}
+}
+
+func (s *CrashReportService) Register(launcher *core.LauncherCore) {
+	launcher.AddCrashListener(s.HandleCrash)
+}
+
+// buildReport concatenates the crash report and the log tail into a text file.
+func buildReport(crash *core.Crash) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "Consecutive crashes: %d\n", crash.Count)
+	if crash.Err != nil {
+		fmt.Fprintf(&buf, "Exit status: %v\n", crash.Err)
+	} else {
+		fmt.Fprintf(&buf, "Exit status: exited successfully\n")
+	}
+	if crash.GivingUp {
+		fmt.Fprintf(&buf, "The server will not be restarted anymore.\n")
+	}
+
+	if crash.ReportPath != "" {
+		fmt.Fprintf(&buf, "\n---- %s ----\n", filepath.Base(crash.ReportPath))
+		if f, err := os.Open(crash.ReportPath); err == nil {
+			io.Copy(&buf, io.LimitReader(f, maxCrashReportSize))
+			f.Close()
+		} else {
+			fmt.Fprintf(&buf, "Unable to read the crash report: %v\n", err)
+		}
+	}
+
+	fmt.Fprintf(&buf, "\n---- Latest log ----\n")
+	buf.Write(crash.LogTail)
+
+	return buf.Bytes()
+}
+
+func (s *CrashReportService) upload(ctx context.Context, report []byte) (string, error) {
+	uploadURL, err := s.uploader.CreateCrashReportUploadURL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = retry.Retry(ctx, func(ctx context.Context) (retry.Void, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL.URL, bytes.NewReader(report))
+		if err != nil {
+			return retry.V, err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return retry.V, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return retry.V, fmt.Errorf("upload failed: %s", resp.Status)
+		}
+
+		io.CopyN(io.Discard, resp.Body, 10*1024)
+
+		return retry.V, nil
+	}, time.Minute)
+	if err != nil {
+		return "", err
+	}
+
+	return uploadURL.ReportID, nil
+}
+
+// HandleCrash uploads the report of the crash and notifies the control plane of it.
+// The control plane is notified even if the upload failed.
+func (s *CrashReportService) HandleCrash(c core.LauncherContext, crash *core.Crash) error {
+	reportID, err := s.upload(c.Context(), buildReport(crash))
+	if err != nil {
+		slog.ErrorContext(c.Context(), "Unable to upload crash report", slog.Any("error", err))
+	}
+
+	exterior.DispatchEvent(c.Context(), runner.Event{
+		Type: runner.EventStatus,
+		Status: &runner.StatusExtra{
+			EventCode:   entity.EventCrashed,
+			CrashReport: reportID,
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,106 @@
+package crashreport_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/crashreport"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeUploader struct {
+	url string
+	err error
+}
+
+func (u *fakeUploader) CreateCrashReportUploadURL(ctx context.Context) (*web.CreateCrashReportUploadURLResponse, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	return &web.CreateCrashReportUploadURLResponse{
+		URL:      u.url,
+		ReportID: "@crashreports/1/20240101-000000.txt",
+	}, nil
+}
+
+var _ = Describe("CrashReportService", func() {
+	var (
+		ctrl            *gomock.Controller
+		launcherContext *core.MockLauncherContext
+		server          *httptest.Server
+		uploaded        chan string
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		launcherContext = core.NewMockLauncherContext(ctrl)
+		launcherContext.EXPECT().Context().AnyTimes().Return(GinkgoT().Context())
+
+		uploaded = make(chan string, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPut))
+			body, _ := io.ReadAll(r.Body)
+			uploaded <- string(body)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("should upload the crash report and the log", func() {
+		reportPath := filepath.Join(GinkgoT().TempDir(), "crash-2024-01-01_00.00.00-server.txt")
+		Expect(os.WriteFile(reportPath, []byte("java.lang.NullPointerException\n"), 0644)).To(Succeed())
+
+		service := crashreport.NewCrashReportService(&fakeUploader{url: server.URL}, http.DefaultClient)
+		err := service.HandleCrash(launcherContext, &core.Crash{
+			Err:        errors.New("exit status 1"),
+			ReportPath: reportPath,
+			LogTail:    []byte("[Server thread/ERROR]: Encountered an unexpected exception\n"),
+			Count:      3,
+			GivingUp:   true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var report string
+		Eventually(uploaded).Should(Receive(&report))
+		Expect(report).To(ContainSubstring("Consecutive crashes: 3"))
+		Expect(report).To(ContainSubstring("Exit status: exit status 1"))
+		Expect(report).To(ContainSubstring("The server will not be restarted anymore."))
+		Expect(report).To(ContainSubstring("---- crash-2024-01-01_00.00.00-server.txt ----\njava.lang.NullPointerException\n"))
+		Expect(report).To(ContainSubstring("---- Latest log ----\n[Server thread/ERROR]: Encountered an unexpected exception\n"))
+	})
+
+	It("should upload only the log if there's no crash report", func() {
+		service := crashreport.NewCrashReportService(&fakeUploader{url: server.URL}, http.DefaultClient)
+		err := service.HandleCrash(launcherContext, &core.Crash{
+			LogTail: []byte("Killed\n"),
+			Count:   1,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		var report string
+		Eventually(uploaded).Should(Receive(&report))
+		Expect(report).NotTo(ContainSubstring("not be restarted"))
+		Expect(report).To(ContainSubstring("---- Latest log ----\nKilled\n"))
+	})
+
+	It("should fail if the upload URL is unavailable", func() {
+		service := crashreport.NewCrashReportService(&fakeUploader{err: errors.New("error")}, http.DefaultClient)
+		err := service.HandleCrash(launcherContext, &core.Crash{Count: 1})
+		Expect(err).To(HaveOccurred())
+		Expect(uploaded).NotTo(Receive())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CrashReport Suite")
+}
@@ -5,12 +5,19 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/kofuk/premises/backend/common/entity/runner"
 	"github.com/kofuk/premises/backend/common/mc/launchermeta"
+	"github.com/kofuk/premises/backend/runner/api"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/crashreport"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/autobackup"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/autoversion"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/datapack"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/eula"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/flavor"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/modset"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/monitoring"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/monitoring/watchdog"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/serverjar"
@@ -19,10 +26,14 @@ import (
 	worldService "github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/world/service"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/quickundo"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/reconfigure"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/repository"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/serverlog"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/shutdown"
 	"github.com/kofuk/premises/backend/runner/env"
 	"github.com/kofuk/premises/backend/runner/metadata"
 	"github.com/kofuk/premises/backend/runner/rpc"
+	"github.com/kofuk/premises/backend/runner/system"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
@@ -36,6 +47,11 @@ func Run(ctx context.Context, config *runner.Config, args []string) int {
 
 	launcher := core.NewLauncherCore(settingsRepository, env.DefaultEnvProvider, stateRepository)
 
+	logStreamer := serverlog.NewLogStreamer()
+	launcher.Output = logStreamer
+	go logStreamer.Run(ctx)
+	defer logStreamer.Flush(ctx)
+
 	quickUndoService := quickundo.NewQuickUndoService(rpc.ToSnapshotHelper)
 	quickUndoService.Register(launcher)
 
@@ -43,7 +59,14 @@ func Run(ctx context.Context, config *runner.Config, args []string) int {
 		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
 
-	worldService := worldService.NewWorldService(config.ControlPlane, config.AuthKey, httpClient)
+	crashReportService := crashreport.NewCrashReportService(api.NewClient(config.ControlPlane, config.AuthKey, httpClient), httpClient)
+	crashReportService.Register(launcher)
+
+	var worldServiceOptions []worldService.Option
+	if config.GameConfig.World.BackupFormat != "" {
+		worldServiceOptions = append(worldServiceOptions, worldService.WithBackupFormat(config.GameConfig.World.BackupFormat))
+	}
+	worldService := worldService.NewWorldService(config.ControlPlane, config.AuthKey, httpClient, worldServiceOptions...)
 
 	launchermetaOptions := []launchermeta.Option{
 		launchermeta.WithHTTPClient(httpClient),
@@ -57,27 +80,53 @@ func Run(ctx context.Context, config *runner.Config, args []string) int {
 
 	rconClient := rcon.NewRcon(rcon.NewRconExecutor("127.0.0.2:25575", "x"))
 
+	reconfigureService := reconfigure.NewReconfigureService(rconClient, settingsRepository, config.GameConfig.Operators, config.GameConfig.Whitelist)
+	reconfigureService.Register(launcher)
+
+	shutdownService := shutdown.NewShutdownService(rconClient)
+
 	launcher.Use(monitoring.NewMonitoringMiddleware(
 		watchdog.NewLivenessWatchdog(),
 		watchdog.NewOneTimeInitWatchdog(rconClient, config.GameConfig.Operators, config.GameConfig.Whitelist),
-		watchdog.NewActivenessWatchdog(rconClient, config.GameConfig.Server.InactiveTimeout),
+		watchdog.NewActivenessWatchdog(rconClient, shutdownService, config.GameConfig.Server.InactiveTimeout),
+		watchdog.NewPlayersWatchdog(rconClient),
 	))
 	launcher.Use(eula.NewEulaMiddleware())
 	launcher.Use(serverproperties.NewServerPropertiesMiddleware())
+	launcher.Use(modset.NewModSetMiddleware(
+		api.NewClient(config.ControlPlane, config.AuthKey, httpClient),
+		httpClient,
+	))
+	launcher.Use(flavor.NewFlavorMiddleware(httpClient, system.DefaultExecutor))
 	launcher.Use(serverjar.NewServerJarMiddleware(
 		launchermetaClient,
 		httpClient,
 	))
 	launcher.Use(autoversion.NewAutoVersionMiddleware())
+	launcher.Use(datapack.NewDatapackMiddleware(
+		api.NewClient(config.ControlPlane, config.AuthKey, httpClient),
+		httpClient,
+		rconClient,
+	))
+	launcher.Use(autobackup.NewAutoBackupMiddleware(
+		rconClient,
+		worldService,
+		autobackup.NewRPCSnapshotter(rpc.ToSnapshotHelper),
+		time.Duration(config.GameConfig.World.AutoBackupInterval)*time.Minute,
+	))
 	launcher.Use(middlewareWorld.NewWorldMiddleware(worldService))
 
-	rpcHandler := NewRPCHandler(rpc.DefaultServer, quickUndoService, rconClient)
+	rpcHandler := NewRPCHandler(rpc.DefaultServer, quickUndoService, reconfigureService, shutdownService, rconClient)
 	rpcHandler.Bind()
 
 	if err := launcher.Start(ctx); errors.Is(err, core.ErrRestart) {
 		slog.InfoContext(ctx, "Restart...")
 
 		return 100
+	} else if errors.Is(err, core.ErrCrashLoop) {
+		// Exit successfully so that the launcher is not restarted again.
+		slog.ErrorContext(ctx, "Server crashed repeatedly, giving up", slog.Any("error", err))
+		return 0
 	} else if err != nil {
 		slog.ErrorContext(ctx, "Failed to start launcher", slog.Any("error", err))
 		return 1
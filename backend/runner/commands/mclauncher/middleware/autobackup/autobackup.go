@@ -0,0 +1,168 @@
+package autobackup
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/world/service"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/exterior"
+	"github.com/kofuk/premises/backend/runner/rpc"
+	"github.com/kofuk/premises/backend/runner/rpc/types"
+)
+
+type Snapshotter interface {
+	// TakeSnapshot creates a read-only copy of gamedata and returns its path.
+	TakeSnapshot(ctx context.Context) (string, error)
+}
+
+type RPCSnapshotter struct {
+	rpcClient *rpc.Client
+}
+
+var _ Snapshotter = (*RPCSnapshotter)(nil)
+
+func NewRPCSnapshotter(rpcClient *rpc.Client) *RPCSnapshotter {
+	return &RPCSnapshotter{
+		rpcClient: rpcClient,
+	}
+}
+
+func (s *RPCSnapshotter) TakeSnapshot(ctx context.Context) (string, error) {
+	var snapshotInfo types.SnapshotHelperOutput
+	if err := s.rpcClient.Call(ctx, "snapshot/backup", nil, &snapshotInfo); err != nil {
+		return "", err
+	}
+	return snapshotInfo.Path, nil
+}
+
+type AutoBackupMiddleware struct {
+	rcon         *rcon.Rcon
+	worldService service.WorldServiceInterface
+	snapshotter  Snapshotter
+	interval     time.Duration
+}
+
+var _ core.Middleware = (*AutoBackupMiddleware)(nil)
+
+// NewAutoBackupMiddleware creates a middleware which uploads the world every interval while the server is running.
+// Non-positive interval disables backups.
+func NewAutoBackupMiddleware(rcon *rcon.Rcon, worldService service.WorldServiceInterface, snapshotter Snapshotter, interval time.Duration) *AutoBackupMiddleware {
+	return &AutoBackupMiddleware{
+		rcon:         rcon,
+		worldService: worldService,
+		snapshotter:  snapshotter,
+		interval:     interval,
+	}
+}
+
+func sendInfo(ctx context.Context, infoCode entity.InfoCode, isError bool) {
+	exterior.DispatchEvent(ctx, runner.Event{
+		Type: runner.EventInfo,
+		Info: &runner.InfoExtra{
+			InfoCode: infoCode,
+			IsError:  isError,
+		},
+	})
+}
+
+// prepare flushes the world and returns the directory to upload it from.
+// Saving is left disabled only if the world has to be uploaded from the live directory.
+func (m *AutoBackupMiddleware) prepare(c core.LauncherContext) (string, bool, error) {
+	ctx := c.Context()
+
+	if err := m.rcon.SaveOff(ctx); err != nil {
+		return "", false, err
+	}
+	if err := m.rcon.SaveAllFlush(ctx); err != nil {
+		return "", true, err
+	}
+
+	snapshotPath, err := m.snapshotter.TakeSnapshot(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Unable to take snapshot. Uploading the live world with saving disabled", slog.Any("error", err))
+		return c.Env().GetDataPath("gamedata"), true, nil
+	}
+
+	return snapshotPath, false, m.rcon.SaveOn(ctx)
+}
+
+func (m *AutoBackupMiddleware) backup(c core.LauncherContext) error {
+	ctx := c.Context()
+
+	sendInfo(ctx, entity.InfoAutoBackupStarted, false)
+
+	baseDir, saveOff, err := m.prepare(c)
+	if saveOff {
+		defer func() {
+			if err := m.rcon.SaveOn(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to run save-on", slog.Any("error", err))
+			}
+		}()
+	}
+	if err != nil {
+		return err
+	}
+
+	worldKey, err := m.worldService.UploadAutoBackup(ctx, c.Settings().GetWorldName(), baseDir, c.Env())
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Auto backup completed", slog.String("world_key", worldKey))
+
+	return nil
+}
+
+func (m *AutoBackupMiddleware) run(c core.LauncherContext) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := m.backup(c); err != nil {
+			if c.Context().Err() != nil {
+				// The server is stopping. The world will be uploaded by the world middleware.
+				return
+			}
+			slog.ErrorContext(c.Context(), "Failed to back up world", slog.Any("error", err))
+			sendInfo(c.Context(), entity.InfoAutoBackupError, true)
+			continue
+		}
+
+		sendInfo(c.Context(), entity.InfoAutoBackupDone, false)
+	}
+}
+
+func (m *AutoBackupMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
+	return func(c core.LauncherContext) error {
+		if m.interval <= 0 {
+			return next(c)
+		}
+
+		ctx, cancel := context.WithCancel(c.Context())
+
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			m.run(core.WithContext(c, ctx))
+		})
+
+		err := next(c)
+
+		// Wait for the running backup so that it doesn't race with the final upload.
+		cancel()
+		wg.Wait()
+
+		return err
+	}
+}
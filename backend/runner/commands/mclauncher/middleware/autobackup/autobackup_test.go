@@ -0,0 +1,127 @@
+package autobackup_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/autobackup"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/world/service"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/env"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSnapshotter struct {
+	path string
+	err  error
+}
+
+func (s *fakeSnapshotter) TakeSnapshot(ctx context.Context) (string, error) {
+	return s.path, s.err
+}
+
+// waitMiddleware plays the role of the running server until done is closed.
+type waitMiddleware struct {
+	done chan struct{}
+}
+
+func (m *waitMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
+	return func(c core.LauncherContext) error {
+		select {
+		case <-m.done:
+		case <-time.After(5 * time.Second):
+		}
+		return nil
+	}
+}
+
+var _ = Describe("AutoBackupMiddleware", func() {
+	var (
+		ctrl               *gomock.Controller
+		settingsRepository *core.MockSettingsRepository
+		envProvider        *env.MockEnvProvider
+		stateRepository    *core.MockStateRepository
+		rconExecutor       *rcon.MockRconExecutorInterface
+		worldService       *service.MockWorldServiceInterface
+		launcher           *core.LauncherCore
+		uploaded           chan struct{}
+		closeUploaded      func()
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		settingsRepository = core.NewMockSettingsRepository(ctrl)
+		envProvider = env.NewMockEnvProvider(ctrl)
+		stateRepository = core.NewMockStateRepository(ctrl)
+		rconExecutor = rcon.NewMockRconExecutorInterface(ctrl)
+		worldService = service.NewMockWorldServiceInterface(ctrl)
+
+		uploaded = make(chan struct{})
+		closeUploaded = sync.OnceFunc(func() { close(uploaded) })
+
+		launcher = core.NewLauncherCore(settingsRepository, envProvider, stateRepository)
+		launcher.Use(&waitMiddleware{done: uploaded})
+
+		settingsRepository.EXPECT().GetWorldName().AnyTimes().Return("foo")
+	})
+
+	It("should upload the snapshot with saving enabled again", func() {
+		rconExecutor.EXPECT().Exec(gomock.Any(), "save-off").MinTimes(1).Return("", nil)
+		rconExecutor.EXPECT().Exec(gomock.Any(), "save-all flush").MinTimes(1).Return("", nil)
+		rconExecutor.EXPECT().Exec(gomock.Any(), "save-on").MinTimes(1).Return("", nil)
+		worldService.EXPECT().UploadAutoBackup(gomock.Any(), "foo", "/snapshot", gomock.Any()).MinTimes(1).DoAndReturn(
+			func(ctx context.Context, worldName, baseDir string, envProvider env.EnvProvider) (string, error) {
+				closeUploaded()
+				return "foo/backup.tar.zst", nil
+			},
+		)
+
+		launcher.Use(autobackup.NewAutoBackupMiddleware(rcon.NewRcon(rconExecutor), worldService, &fakeSnapshotter{path: "/snapshot"}, 10*time.Millisecond))
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should upload the live world if snapshot is unavailable", func() {
+		envProvider.EXPECT().GetDataPath("gamedata").AnyTimes().Return("/gamedata")
+
+		gomock.InOrder(
+			rconExecutor.EXPECT().Exec(gomock.Any(), "save-off").Return("", nil),
+			rconExecutor.EXPECT().Exec(gomock.Any(), "save-all flush").Return("", nil),
+			worldService.EXPECT().UploadAutoBackup(gomock.Any(), "foo", "/gamedata", gomock.Any()).DoAndReturn(
+				func(ctx context.Context, worldName, baseDir string, envProvider env.EnvProvider) (string, error) {
+					closeUploaded()
+					return "foo/backup.tar.zst", nil
+				},
+			),
+			rconExecutor.EXPECT().Exec(gomock.Any(), "save-on").Return("", nil),
+		)
+		rconExecutor.EXPECT().Exec(gomock.Any(), gomock.Any()).AnyTimes().Return("", nil)
+		worldService.EXPECT().UploadAutoBackup(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return("", context.Canceled)
+
+		launcher.Use(autobackup.NewAutoBackupMiddleware(rcon.NewRcon(rconExecutor), worldService, &fakeSnapshotter{err: errors.New("not supported")}, 10*time.Millisecond))
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should do nothing if disabled", func() {
+		closeUploaded()
+
+		launcher.Use(autobackup.NewAutoBackupMiddleware(rcon.NewRcon(rconExecutor), worldService, &fakeSnapshotter{}, 0))
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AutoBackupMiddleware Suite")
+}
@@ -2,11 +2,38 @@ package autoversion
 
 import (
 	"log/slog"
+	"regexp"
+	"strings"
 
+	"github.com/kofuk/premises/backend/common/mc/leveldat"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
-	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/autoversion/leveldat"
 )
 
+var (
+	pre114Pattern = regexp.MustCompile(`^1\.14(\.[12])? Pre-Release [1-5]$`)
+)
+
+func CanonicalizeVersionName(name string) string {
+	if strings.Contains(name, "Pre-Release") {
+		if match := pre114Pattern.MatchString(name); !match {
+			// The pre-release version (except for the specific versions) of level.dat stores
+			// a different string than the downloadable version name.
+			// We will fix this here.
+			name = strings.Replace(name, " Pre-Release ", "-pre", 1)
+		}
+	}
+
+	return name
+}
+
+func getCanonicalServerVersion(levelDatPath string) (string, error) {
+	levelDat, err := leveldat.ReadFile(levelDatPath)
+	if err != nil {
+		return "", err
+	}
+	return CanonicalizeVersionName(levelDat.Data.Version.Name), nil
+}
+
 type AutoVersionMiddleware struct {
 }
 
@@ -20,7 +47,7 @@ func (m *AutoVersionMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
 	return func(c core.LauncherContext) error {
 		if c.Settings().AutoVersionEnabled() {
 			slog.InfoContext(c.Context(), "Detecting server version from existing level.dat")
-			if version, err := leveldat.GetCanonicalServerVersion(c.Env().GetDataPath("gamedata/world/level.dat")); err != nil {
+			if version, err := getCanonicalServerVersion(c.Env().GetDataPath("gamedata/world/level.dat")); err != nil {
 				// Don't exit here, just log the error
 				slog.ErrorContext(c.Context(), "failed to detect server version", slog.Any("error", err))
 			} else {
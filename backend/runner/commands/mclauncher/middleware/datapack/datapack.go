@@ -0,0 +1,256 @@
+package datapack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/retry"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/exterior"
+)
+
+// managedListFile lists datapacks installed by the middleware, so that detached ones can be removed.
+// It is saved in the world, and uploaded with it.
+const managedListFile = ".premises-datapacks.json"
+
+type DatapackSource interface {
+	GetWorldDatapacks(ctx context.Context, worldName string) ([]web.DatapackDownload, error)
+}
+
+type DatapackMiddleware struct {
+	source     DatapackSource
+	httpClient *http.Client
+	rcon       *rcon.Rcon
+}
+
+var _ core.Middleware = (*DatapackMiddleware)(nil)
+
+// NewDatapackMiddleware creates a middleware which installs datapacks attached to the world before launch,
+// and verifies that the server enabled them.
+func NewDatapackMiddleware(source DatapackSource, httpClient *http.Client, rcon *rcon.Rcon) *DatapackMiddleware {
+	return &DatapackMiddleware{
+		source:     source,
+		httpClient: httpClient,
+		rcon:       rcon,
+	}
+}
+
+// packID returns the ID of the datapack in /datapack commands.
+func packID(name string) string {
+	return "file/" + name + ".zip"
+}
+
+// isValidDatapackName reports whether the name can be used as a file name in the datapacks directory.
+// This is the same rule as the control plane validates names of uploaded datapacks with.
+func isValidDatapackName(name string) bool {
+	return name != "" && len(name) <= 255 && !strings.ContainsAny(name, "@/\\\"") && !strings.HasPrefix(name, ".")
+}
+
+// readManagedList returns names in the managed list. Invalid names are skipped,
+// because the list is in the world and may have been modified by anyone who uploaded it.
+func readManagedList(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, managedListFile))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil
+	}
+	return slices.DeleteFunc(names, func(name string) bool {
+		return !isValidDatapackName(name)
+	})
+}
+
+func writeManagedList(dir string, names []string) error {
+	data, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, managedListFile), data, 0644)
+}
+
+func (m *DatapackMiddleware) download(ctx context.Context, datapack web.DatapackDownload, dest string) error {
+	tmpPath := dest + ".tmp"
+	defer os.Remove(tmpPath)
+
+	_, err := retry.Retry(ctx, func(ctx context.Context) (retry.Void, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, datapack.URL, nil)
+		if err != nil {
+			return retry.V, err
+		}
+
+		resp, err := m.httpClient.Do(req)
+		if err != nil {
+			return retry.V, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			io.CopyN(io.Discard, resp.Body, 10*1024)
+			return retry.V, fmt.Errorf("downloading datapack %s failed with status code %d", datapack.Name, resp.StatusCode)
+		}
+
+		file, err := os.Create(tmpPath)
+		if err != nil {
+			return retry.V, err
+		}
+		defer file.Close()
+
+		if _, err := io.Copy(file, resp.Body); err != nil {
+			return retry.V, err
+		}
+		return retry.V, file.Close()
+	}, time.Minute)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, dest)
+}
+
+// install downloads the datapacks attached to the world, and removes ones which were detached.
+// It returns names of the installed datapacks.
+func (m *DatapackMiddleware) install(c core.LauncherContext) ([]string, error) {
+	ctx := c.Context()
+
+	datapacks, err := m.source.GetWorldDatapacks(ctx, c.Settings().GetWorldName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datapacks: %w", err)
+	}
+
+	dir := c.Env().GetDataPath("gamedata/world/datapacks")
+	previous := readManagedList(dir)
+	if len(datapacks) == 0 && len(previous) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	datapacks = slices.DeleteFunc(datapacks, func(datapack web.DatapackDownload) bool {
+		if isValidDatapackName(datapack.Name) {
+			return false
+		}
+		slog.WarnContext(ctx, "Skipping datapack with invalid name", slog.String("name", datapack.Name))
+		return true
+	})
+
+	names := make([]string, 0, len(datapacks))
+	for _, datapack := range datapacks {
+		names = append(names, datapack.Name)
+	}
+
+	for _, name := range previous {
+		if slices.Contains(names, name) {
+			continue
+		}
+		slog.InfoContext(ctx, "Removing detached datapack", slog.String("name", name))
+		if err := os.Remove(filepath.Join(dir, name+".zip")); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	for _, datapack := range datapacks {
+		slog.InfoContext(ctx, "Installing datapack", slog.String("name", datapack.Name))
+		if err := m.download(ctx, datapack, filepath.Join(dir, datapack.Name+".zip")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeManagedList(dir, names); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// verify checks that the server enabled the installed datapacks, and reports them.
+// Datapacks which the server found but didn't enable are enabled here.
+func (m *DatapackMiddleware) verify(ctx context.Context, names []string) error {
+	// This waits for the server to accept rcon connections.
+	list, err := m.rcon.DatapackList(ctx)
+	if err != nil {
+		return err
+	}
+
+	states := make([]runner.DatapackState, 0, len(names))
+	allEnabled := true
+	for _, name := range names {
+		id := packID(name)
+		enabled := slices.Contains(list.Enabled, id)
+		if !enabled && slices.Contains(list.Available, id) {
+			if err := m.rcon.EnableDatapack(ctx, id); err != nil {
+				slog.ErrorContext(ctx, "Failed to enable datapack", slog.String("name", name), slog.Any("error", err))
+			} else {
+				enabled = true
+			}
+		}
+		if !enabled {
+			slog.WarnContext(ctx, "Datapack is not enabled", slog.String("name", name))
+			allEnabled = false
+		}
+		states = append(states, runner.DatapackState{Name: name, Enabled: enabled})
+	}
+
+	exterior.SendEvent(ctx, runner.Event{
+		Type: runner.EventDatapacks,
+		Datapacks: &runner.DatapacksExtra{
+			Datapacks: states,
+		},
+	})
+
+	if !allEnabled {
+		exterior.DispatchEvent(ctx, runner.Event{
+			Type: runner.EventInfo,
+			Info: &runner.InfoExtra{
+				InfoCode: entity.InfoDatapackError,
+				IsError:  true,
+			},
+		})
+	}
+
+	return nil
+}
+
+func (m *DatapackMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
+	return func(c core.LauncherContext) error {
+		names, err := m.install(c)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return next(c)
+		}
+
+		ctx, cancel := context.WithCancel(c.Context())
+
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			if err := m.verify(ctx, names); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to verify datapacks", slog.Any("error", err))
+			}
+		})
+
+		err = next(c)
+
+		cancel()
+		wg.Wait()
+
+		return err
+	}
+}
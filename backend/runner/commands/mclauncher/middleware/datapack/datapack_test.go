@@ -0,0 +1,160 @@
+package datapack_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/datapack"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/env"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSource struct {
+	datapacks []web.DatapackDownload
+}
+
+func (s *fakeSource) GetWorldDatapacks(ctx context.Context, worldName string) ([]web.DatapackDownload, error) {
+	return s.datapacks, nil
+}
+
+// waitMiddleware plays the role of the running server until done is closed.
+type waitMiddleware struct {
+	done chan struct{}
+}
+
+func (m *waitMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
+	return func(c core.LauncherContext) error {
+		select {
+		case <-m.done:
+		case <-time.After(5 * time.Second):
+		}
+		return nil
+	}
+}
+
+var _ = Describe("DatapackMiddleware", func() {
+	var (
+		tempDir            string
+		ctrl               *gomock.Controller
+		settingsRepository *core.MockSettingsRepository
+		envProvider        *env.MockEnvProvider
+		stateRepository    *core.MockStateRepository
+		rconExecutor       *rcon.MockRconExecutorInterface
+		launcher           *core.LauncherCore
+		server             *httptest.Server
+		verified           chan struct{}
+		closeVerified      func()
+	)
+
+	BeforeEach(func() {
+		tempDir = GinkgoT().TempDir()
+		ctrl = gomock.NewController(GinkgoT())
+		settingsRepository = core.NewMockSettingsRepository(ctrl)
+		envProvider = env.NewMockEnvProvider(ctrl)
+		stateRepository = core.NewMockStateRepository(ctrl)
+		rconExecutor = rcon.NewMockRconExecutorInterface(ctrl)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("datapack" + r.URL.Path))
+		}))
+		DeferCleanup(server.Close)
+
+		verified = make(chan struct{})
+		closeVerified = sync.OnceFunc(func() { close(verified) })
+
+		launcher = core.NewLauncherCore(settingsRepository, envProvider, stateRepository)
+		launcher.Use(&waitMiddleware{done: verified})
+
+		settingsRepository.EXPECT().GetWorldName().AnyTimes().Return("foo")
+		envProvider.EXPECT().GetDataPath("gamedata/world/datapacks").AnyTimes().Return(tempDir)
+	})
+
+	It("should install datapacks and enable ones which the server didn't", func() {
+		Expect(os.WriteFile(filepath.Join(tempDir, ".premises-datapacks.json"), []byte(`["old"]`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, "old.zip"), []byte("old"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, "manual.zip"), []byte("manual"), 0644)).To(Succeed())
+
+		source := &fakeSource{
+			datapacks: []web.DatapackDownload{
+				{Name: "bar", URL: server.URL + "/bar"},
+				{Name: "baz", URL: server.URL + "/baz"},
+			},
+		}
+
+		rconExecutor.EXPECT().Exec(gomock.Any(), "datapack list").Return(
+			"There are 2 data pack(s) enabled: [vanilla (built-in)], [file/bar.zip (world)]There are 1 data pack(s) available: [file/baz.zip (world)]", nil)
+		rconExecutor.EXPECT().Exec(gomock.Any(), `datapack enable "file/baz.zip"`).DoAndReturn(func(ctx context.Context, cmd string) (string, error) {
+			closeVerified()
+			return "", nil
+		})
+
+		launcher.Use(datapack.NewDatapackMiddleware(source, server.Client(), rcon.NewRcon(rconExecutor)))
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(os.ReadFile(filepath.Join(tempDir, "bar.zip"))).To(Equal([]byte("datapack/bar")))
+		Expect(os.ReadFile(filepath.Join(tempDir, "baz.zip"))).To(Equal([]byte("datapack/baz")))
+		Expect(filepath.Join(tempDir, "old.zip")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tempDir, "manual.zip")).To(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(tempDir, ".premises-datapacks.json"))).To(MatchJSON(`["bar", "baz"]`))
+	})
+
+	It("should skip datapacks with invalid names", func() {
+		Expect(os.WriteFile(filepath.Join(tempDir, ".premises-datapacks.json"), []byte(`["sub/victim", ".hidden"]`), 0644)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(tempDir, "sub"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, "sub", "victim.zip"), []byte("victim"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tempDir, ".hidden.zip"), []byte("hidden"), 0644)).To(Succeed())
+
+		source := &fakeSource{
+			datapacks: []web.DatapackDownload{
+				{Name: "bar", URL: server.URL + "/bar"},
+				{Name: "../evil", URL: server.URL + "/evil"},
+			},
+		}
+
+		rconExecutor.EXPECT().Exec(gomock.Any(), "datapack list").DoAndReturn(func(ctx context.Context, cmd string) (string, error) {
+			closeVerified()
+			return "There are 2 data pack(s) enabled: [vanilla (built-in)], [file/bar.zip (world)]", nil
+		})
+
+		launcher.Use(datapack.NewDatapackMiddleware(source, server.Client(), rcon.NewRcon(rconExecutor)))
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(os.ReadFile(filepath.Join(tempDir, "bar.zip"))).To(Equal([]byte("datapack/bar")))
+		Expect(filepath.Join(tempDir, "..", "evil.zip")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(tempDir, "sub", "victim.zip")).To(BeAnExistingFile())
+		Expect(filepath.Join(tempDir, ".hidden.zip")).To(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(tempDir, ".premises-datapacks.json"))).To(MatchJSON(`["bar"]`))
+	})
+
+	It("should do nothing without datapacks", func() {
+		closeVerified()
+
+		launcher.Use(datapack.NewDatapackMiddleware(&fakeSource{}, server.Client(), rcon.NewRcon(rconExecutor)))
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+
+		entries, _ := os.ReadDir(tempDir)
+		Expect(entries).To(BeEmpty())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DatapackMiddleware Suite")
+}
@@ -0,0 +1,212 @@
+package flavor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/system"
+)
+
+// fabricInstaller installs the server launcher of Fabric with its installer, which loads the vanilla server with Fabric Loader.
+type fabricInstaller struct {
+	fetcher  *fetcher
+	executor system.CommandExecutor
+	metaURL  string
+}
+
+func newFabricInstaller(fetcher *fetcher, executor system.CommandExecutor) *fabricInstaller {
+	return &fabricInstaller{
+		fetcher:  fetcher,
+		executor: executor,
+		metaURL:  "https://meta.fabricmc.net",
+	}
+}
+
+type fabricVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+	// URL is the URL of the installer in the Maven repository, which only installers have.
+	URL string `json:"url"`
+}
+
+// pickFabricVersion returns the first stable version, or the first one if none of them is stable.
+func pickFabricVersion(versions []fabricVersion) (string, error) {
+	for _, version := range versions {
+		if version.Stable {
+			return version.Version, nil
+		}
+	}
+	if len(versions) == 0 {
+		return "", errNoVersion
+	}
+	return versions[0].Version, nil
+}
+
+// Resolve returns "<loader version>-<installer version>", as the server launcher depends on both of them.
+func (i *fabricInstaller) Resolve(ctx context.Context, mcVersion string) (string, error) {
+	var loaders []struct {
+		Loader fabricVersion `json:"loader"`
+	}
+	if err := i.fetcher.getJSON(ctx, i.metaURL+"/v2/versions/loader/"+url.PathEscape(mcVersion), &loaders); err != nil {
+		return "", err
+	}
+	loaderVersions := make([]fabricVersion, 0, len(loaders))
+	for _, loader := range loaders {
+		loaderVersions = append(loaderVersions, loader.Loader)
+	}
+	loader, err := pickFabricVersion(loaderVersions)
+	if err != nil {
+		return "", err
+	}
+
+	var installers []fabricVersion
+	if err := i.fetcher.getJSON(ctx, i.metaURL+"/v2/versions/installer", &installers); err != nil {
+		return "", err
+	}
+	installer, err := pickFabricVersion(installers)
+	if err != nil {
+		return "", err
+	}
+
+	return loader + "-" + installer, nil
+}
+
+func splitFabricVersion(version string) (string, string, error) {
+	// Installer versions don't contain "-".
+	j := strings.LastIndexByte(version, '-')
+	if j < 0 {
+		return "", "", fmt.Errorf("malformed version: %s", version)
+	}
+	return version[:j], version[j+1:], nil
+}
+
+func (i *fabricInstaller) Install(ctx context.Context, mcVersion, version, dir string) error {
+	loader, installerVersion, err := splitFabricVersion(version)
+	if err != nil {
+		return err
+	}
+
+	// The server launcher served by the meta server has no checksum, so it is generated with the installer in the Maven repository.
+	var installers []fabricVersion
+	if err := i.fetcher.getJSON(ctx, i.metaURL+"/v2/versions/installer", &installers); err != nil {
+		return err
+	}
+	index := slices.IndexFunc(installers, func(installer fabricVersion) bool {
+		return installer.Version == installerVersion
+	})
+	if index < 0 || installers[index].URL == "" {
+		return fmt.Errorf("installer %s not found", installerVersion)
+	}
+	installerURL := installers[index].URL
+
+	sum, err := i.fetcher.mavenChecksum(ctx, installerURL)
+	if err != nil {
+		return err
+	}
+	installerPath := filepath.Join(dir, "fabric-installer.jar")
+	if err := i.fetcher.download(ctx, installerURL, installerPath, sum); err != nil {
+		return err
+	}
+	defer os.Remove(installerPath)
+
+	// The vanilla server is not downloaded as we already have it.
+	if err := runJava(ctx, i.executor, dir, "-jar", installerPath, "server", "-dir", dir, "-mcversion", mcVersion, "-loader", loader); err != nil {
+		return err
+	}
+
+	// The exit status of the installer is not checked, so see if it generated the server launcher.
+	if !fileExists(filepath.Join(dir, "fabric-server-launch.jar")) {
+		return errors.New("installer generated no server launcher")
+	}
+	return nil
+}
+
+func (i *fabricInstaller) LaunchArgs(c core.LauncherContext, version, dir string) ([]string, error) {
+	// The server launcher reads the location of the vanilla server from the working directory.
+	// Without it, the launcher downloads the vanilla server again.
+	properties := fmt.Sprintf("serverJar=%s\n", c.Settings().GetServerPath())
+	if err := os.WriteFile(c.Env().GetDataPath("gamedata", "fabric-server-launcher.properties"), []byte(properties), 0644); err != nil {
+		return nil, err
+	}
+
+	return []string{"-jar", filepath.Join(dir, "fabric-server-launch.jar")}, nil
+}
+
+// quiltInstaller installs Quilt Loader with its installer.
+type quiltInstaller struct {
+	fetcher  *fetcher
+	executor system.CommandExecutor
+	metaURL  string
+}
+
+func newQuiltInstaller(fetcher *fetcher, executor system.CommandExecutor) *quiltInstaller {
+	return &quiltInstaller{
+		fetcher:  fetcher,
+		executor: executor,
+		metaURL:  "https://meta.quiltmc.org",
+	}
+}
+
+func (i *quiltInstaller) Resolve(ctx context.Context, mcVersion string) (string, error) {
+	var loaders []struct {
+		Loader struct {
+			Version string `json:"version"`
+		} `json:"loader"`
+	}
+	if err := i.fetcher.getJSON(ctx, i.metaURL+"/v3/versions/loader/"+url.PathEscape(mcVersion), &loaders); err != nil {
+		return "", err
+	}
+	versions := make([]string, 0, len(loaders))
+	for _, loader := range loaders {
+		versions = append(versions, loader.Loader.Version)
+	}
+	return pickVersion(versions)
+}
+
+func (i *quiltInstaller) Install(ctx context.Context, mcVersion, version, dir string) error {
+	var installers []struct {
+		URL     string `json:"url"`
+		Version string `json:"version"`
+	}
+	if err := i.fetcher.getJSON(ctx, i.metaURL+"/v3/versions/installer", &installers); err != nil {
+		return err
+	}
+	if len(installers) == 0 {
+		return errNoVersion
+	}
+
+	sum, err := i.fetcher.mavenChecksum(ctx, installers[0].URL)
+	if err != nil {
+		return err
+	}
+	installerPath := filepath.Join(dir, "quilt-installer.jar")
+	if err := i.fetcher.download(ctx, installers[0].URL, installerPath, sum); err != nil {
+		return err
+	}
+	defer os.Remove(installerPath)
+
+	// The vanilla server is not downloaded as we already have it.
+	if err := runJava(ctx, i.executor, dir, "-jar", installerPath, "install", "server", mcVersion, version, "--install-dir="+dir); err != nil {
+		return err
+	}
+
+	if !fileExists(filepath.Join(dir, "quilt-server-launch.jar")) {
+		return errors.New("installer generated no server launcher")
+	}
+	return nil
+}
+
+func (i *quiltInstaller) LaunchArgs(c core.LauncherContext, version, dir string) ([]string, error) {
+	return []string{
+		"-Dloader.gameJarPath=" + c.Settings().GetServerPath(),
+		"-jar",
+		filepath.Join(dir, "quilt-server-launch.jar"),
+	}, nil
+}
@@ -0,0 +1,344 @@
+package flavor
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/retry"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	coreUtil "github.com/kofuk/premises/backend/runner/commands/mclauncher/core/util"
+	"github.com/kofuk/premises/backend/runner/system"
+	"github.com/kofuk/premises/backend/runner/util"
+)
+
+// installedMarker is created in the installation directory after the installation succeeded.
+const installedMarker = ".premises-installed"
+
+const userAgent = "Premises (https://github.com/kofuk/premises)"
+
+// Installer installs a server flavor.
+type Installer interface {
+	// Resolve returns the version of the loader or the build to install for the Minecraft version.
+	Resolve(ctx context.Context, mcVersion string) (string, error)
+	// Install installs the resolved version into dir.
+	Install(ctx context.Context, mcVersion, version, dir string) error
+	// LaunchArgs returns arguments following JVM options to launch the server installed in dir.
+	LaunchArgs(c core.LauncherContext, version, dir string) ([]string, error)
+}
+
+type FlavorMiddleware struct {
+	installers map[string]Installer
+}
+
+var _ core.Middleware = (*FlavorMiddleware)(nil)
+
+type Option func(m *FlavorMiddleware)
+
+// WithInstaller replaces the installer of the flavor.
+func WithInstaller(flavor string, installer Installer) Option {
+	return func(m *FlavorMiddleware) {
+		m.installers[flavor] = installer
+	}
+}
+
+// NewFlavorMiddleware creates a middleware which installs the server flavor for the Minecraft version and
+// tells the launcher how to launch it. It must run after the vanilla server is prepared.
+func NewFlavorMiddleware(httpClient *http.Client, executor system.CommandExecutor, options ...Option) *FlavorMiddleware {
+	f := &fetcher{httpClient: httpClient}
+	m := &FlavorMiddleware{
+		installers: map[string]Installer{
+			runner.FlavorFabric:   newFabricInstaller(f, executor),
+			runner.FlavorQuilt:    newQuiltInstaller(f, executor),
+			runner.FlavorPaper:    newPaperInstaller(f),
+			runner.FlavorForge:    newForgeInstaller(f, executor),
+			runner.FlavorNeoForge: newNeoForgeInstaller(f, executor),
+		},
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+func installDirName(mcVersion, version string) string {
+	return mcVersion + "-" + version
+}
+
+func isInstalled(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, installedMarker))
+	return err == nil
+}
+
+// findInstalled returns the newest installation for the Minecraft version in flavorDir.
+func findInstalled(flavorDir, mcVersion string) (string, bool) {
+	ents, err := os.ReadDir(flavorDir)
+	if err != nil {
+		return "", false
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, ent := range ents {
+		version, ok := strings.CutPrefix(ent.Name(), mcVersion+"-")
+		if !ok || !ent.IsDir() || !isInstalled(filepath.Join(flavorDir, ent.Name())) {
+			continue
+		}
+		info, err := ent.Info()
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest = version
+			newestTime = info.ModTime()
+		}
+	}
+	return newest, newest != ""
+}
+
+// install installs the flavor unless it is cached in servers.d, and returns the version and the directory of it.
+func (m *FlavorMiddleware) install(c core.LauncherContext, flavor string, installer Installer) (string, string, error) {
+	ctx := c.Context()
+	mcVersion := c.Settings().GetMinecraftVersion()
+	flavorDir := c.Env().GetDataPath("servers.d", flavor)
+
+	version, err := installer.Resolve(ctx, mcVersion)
+	if err != nil {
+		// Use the cached one if we can't reach the repository of the flavor.
+		if cached, ok := findInstalled(flavorDir, mcVersion); ok {
+			slog.WarnContext(ctx, "Unable to resolve the server flavor. Using the cached one", slog.String("flavor", flavor), slog.String("version", cached), slog.Any("error", err))
+			return cached, filepath.Join(flavorDir, installDirName(mcVersion, cached)), nil
+		}
+		return "", "", fmt.Errorf("unable to resolve %s for Minecraft %s: %w", flavor, mcVersion, err)
+	}
+
+	dir := filepath.Join(flavorDir, installDirName(mcVersion, version))
+	if isInstalled(dir) {
+		return version, dir, nil
+	}
+
+	slog.InfoContext(ctx, "Installing server flavor", slog.String("flavor", flavor), slog.String("minecraft_version", mcVersion), slog.String("version", version))
+
+	// Install into a temporary directory so that a partial installation is never used.
+	tmpDir := dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := installer.Install(ctx, mcVersion, version, tmpDir); err != nil {
+		return "", "", fmt.Errorf("unable to install %s %s: %w", flavor, version, err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, installedMarker), nil, 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		return "", "", err
+	}
+
+	return version, dir, nil
+}
+
+func (m *FlavorMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
+	return func(c core.LauncherContext) error {
+		flavor := c.Settings().GetServerFlavor()
+		if flavor == "" || flavor == runner.FlavorVanilla {
+			return next(c)
+		}
+
+		installer, ok := m.installers[flavor]
+		if !ok {
+			return fmt.Errorf("unsupported server flavor: %s", flavor)
+		}
+
+		version, dir, err := m.install(c, flavor, installer)
+		if err != nil {
+			return err
+		}
+
+		args, err := installer.LaunchArgs(c, version, dir)
+		if err != nil {
+			return err
+		}
+		c.Settings().SetLaunchArgs(args)
+
+		return next(c)
+	}
+}
+
+// fetcher talks to repositories of server flavors.
+type fetcher struct {
+	httpClient *http.Client
+}
+
+func (f *fetcher) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		io.CopyN(io.Discard, resp.Body, 10*1024)
+		resp.Body.Close()
+		return nil, fmt.Errorf("request to %s failed with status code %d", url, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (f *fetcher) getJSON(ctx context.Context, url string, v any) error {
+	_, err := retry.Retry(ctx, func(ctx context.Context) (retry.Void, error) {
+		resp, err := f.get(ctx, url)
+		if err != nil {
+			return retry.V, err
+		}
+		defer resp.Body.Close()
+
+		return retry.V, json.NewDecoder(resp.Body).Decode(v)
+	}, time.Minute)
+	return err
+}
+
+// checksum is the expected digest of a download.
+type checksum struct {
+	newHash func() hash.Hash
+	// digest is hex-encoded.
+	digest string
+}
+
+func sha256Checksum(digest string) checksum {
+	return checksum{newHash: sha256.New, digest: digest}
+}
+
+func sha1Checksum(digest string) checksum {
+	return checksum{newHash: sha1.New, digest: digest}
+}
+
+// parseChecksumFile parses the content of a checksum file, which may be followed by the file name.
+func parseChecksumFile(content string, size int) (string, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return "", false
+	}
+	digest := strings.ToLower(fields[0])
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != size {
+		return "", false
+	}
+	return digest, true
+}
+
+func (f *fetcher) getChecksumFile(ctx context.Context, url string) ([]byte, error) {
+	resp, err := f.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1024))
+}
+
+// mavenChecksum fetches the checksum of the artifact from the Maven repository, preferring SHA-256 to SHA-1.
+func (f *fetcher) mavenChecksum(ctx context.Context, artifactURL string) (checksum, error) {
+	var errs []error
+	for _, candidate := range []struct {
+		ext      string
+		size     int
+		checksum func(string) checksum
+	}{
+		{".sha256", sha256.Size, sha256Checksum},
+		{".sha1", sha1.Size, sha1Checksum},
+	} {
+		// Not retried, as repositories may not have checksums of either algorithm.
+		content, err := f.getChecksumFile(ctx, artifactURL+candidate.ext)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		digest, ok := parseChecksumFile(string(content), candidate.size)
+		if !ok {
+			errs = append(errs, fmt.Errorf("malformed checksum: %s%s", artifactURL, candidate.ext))
+			continue
+		}
+		return candidate.checksum(digest), nil
+	}
+	return checksum{}, fmt.Errorf("no checksum is available for %s: %w", artifactURL, errors.Join(errs...))
+}
+
+// download downloads the file to dest, and fails unless its digest matches sum.
+func (f *fetcher) download(ctx context.Context, url, dest string, sum checksum) error {
+	digest, err := retry.Retry(ctx, func(ctx context.Context) (string, error) {
+		resp, err := f.get(ctx, url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		file, err := os.Create(dest)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+
+		hash := sum.newHash()
+		if _, err := io.Copy(io.MultiWriter(file, hash), util.NewProgressReader(ctx, resp.Body, entity.EventGameDownload, int(resp.ContentLength))); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), file.Close()
+	}, 5*time.Minute)
+	if err == nil && digest != sum.digest {
+		err = fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, sum.digest, digest)
+	}
+	if err != nil {
+		// Best effort to clean up the incomplete or corrupted download
+		os.Remove(dest)
+	}
+	return err
+}
+
+// runJava runs the JAR file, which is typically an installer, in dir.
+func runJava(ctx context.Context, executor system.CommandExecutor, dir string, args ...string) error {
+	return executor.Run(ctx, coreUtil.FindJavaPath(ctx), args, system.WithWorkingDir(dir))
+}
+
+// isStableVersion reports whether the version has no pre-release suffix such as "-beta".
+func isStableVersion(version string) bool {
+	return !strings.Contains(version, "-")
+}
+
+var errNoVersion = errors.New("no version is available")
+
+// pickVersion returns the first stable version in versions, or the first one if none of them is stable.
+func pickVersion(versions []string) (string, error) {
+	if len(versions) == 0 {
+		return "", errNoVersion
+	}
+	if i := slices.IndexFunc(versions, isStableVersion); i >= 0 {
+		return versions[i], nil
+	}
+	return versions[0], nil
+}
@@ -0,0 +1,119 @@
+package flavor_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/flavor"
+	"github.com/kofuk/premises/backend/runner/env"
+	"github.com/kofuk/premises/backend/runner/system"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeInstaller struct {
+	version    string
+	resolveErr error
+	installed  []string
+}
+
+func (i *fakeInstaller) Resolve(ctx context.Context, mcVersion string) (string, error) {
+	return i.version, i.resolveErr
+}
+
+func (i *fakeInstaller) Install(ctx context.Context, mcVersion, version, dir string) error {
+	i.installed = append(i.installed, mcVersion+"-"+version)
+	return os.WriteFile(filepath.Join(dir, "server.jar"), []byte("server"), 0644)
+}
+
+func (i *fakeInstaller) LaunchArgs(c core.LauncherContext, version, dir string) ([]string, error) {
+	return []string{"-jar", filepath.Join(dir, "server.jar")}, nil
+}
+
+var _ = Describe("FlavorMiddleware", func() {
+	var (
+		ctrl               *gomock.Controller
+		settingsRepository *core.MockSettingsRepository
+		envProvider        *env.MockEnvProvider
+		stateRepository    *core.MockStateRepository
+		launcher           *core.LauncherCore
+		installer          *fakeInstaller
+		tempDir            string
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		settingsRepository = core.NewMockSettingsRepository(ctrl)
+		envProvider = env.NewMockEnvProvider(ctrl)
+		stateRepository = core.NewMockStateRepository(ctrl)
+		installer = &fakeInstaller{version: "0.1.0"}
+		tempDir = GinkgoT().TempDir()
+
+		launcher = core.NewLauncherCore(settingsRepository, envProvider, stateRepository)
+		launcher.Use(core.StopMiddleware)
+		launcher.Use(flavor.NewFlavorMiddleware(http.DefaultClient, system.DefaultExecutor, flavor.WithInstaller(runner.FlavorFabric, installer)))
+
+		settingsRepository.EXPECT().GetMinecraftVersion().AnyTimes().Return("1.21.1")
+		envProvider.EXPECT().GetDataPath("servers.d", runner.FlavorFabric).AnyTimes().Return(filepath.Join(tempDir, "fabric"))
+	})
+
+	It("should do nothing for vanilla", func() {
+		settingsRepository.EXPECT().GetServerFlavor().Return(runner.FlavorVanilla)
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(installer.installed).To(BeEmpty())
+	})
+
+	It("should install the flavor and set launch args", func() {
+		settingsRepository.EXPECT().GetServerFlavor().Return(runner.FlavorFabric)
+		settingsRepository.EXPECT().SetLaunchArgs([]string{"-jar", filepath.Join(tempDir, "fabric/1.21.1-0.1.0/server.jar")})
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(installer.installed).To(Equal([]string{"1.21.1-0.1.0"}))
+		Expect(filepath.Join(tempDir, "fabric/1.21.1-0.1.0/server.jar")).To(BeAnExistingFile())
+		Expect(filepath.Join(tempDir, "fabric/1.21.1-0.1.0.tmp")).NotTo(BeAnExistingFile())
+	})
+
+	It("should reuse the cached installation", func() {
+		settingsRepository.EXPECT().GetServerFlavor().Times(2).Return(runner.FlavorFabric)
+		settingsRepository.EXPECT().SetLaunchArgs(gomock.Any()).Times(2)
+
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(installer.installed).To(HaveLen(1))
+	})
+
+	It("should use the cached installation if the flavor can't be resolved", func() {
+		settingsRepository.EXPECT().GetServerFlavor().Times(2).Return(runner.FlavorFabric)
+		settingsRepository.EXPECT().SetLaunchArgs([]string{"-jar", filepath.Join(tempDir, "fabric/1.21.1-0.1.0/server.jar")}).Times(2)
+
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+
+		installer.version = ""
+		installer.resolveErr = errors.New("unreachable")
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(installer.installed).To(HaveLen(1))
+	})
+
+	It("should fail if the flavor can't be resolved and is not cached", func() {
+		settingsRepository.EXPECT().GetServerFlavor().Return(runner.FlavorFabric)
+		installer.resolveErr = errors.New("unreachable")
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).Should(HaveOccurred())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FlavorMiddleware Suite")
+}
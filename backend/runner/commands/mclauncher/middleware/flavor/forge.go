@@ -0,0 +1,213 @@
+package flavor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/system"
+)
+
+// argsFile is the argument file which is rewritten from the one generated by installers, to launch the server
+// from the game data directory.
+const argsFile = "premises_args.txt"
+
+var relativeLibraryPathRegexp = regexp.MustCompile(`(^|[\s:=])libraries(/|\s|$)`)
+
+// forgeLikeInstaller installs Forge or NeoForge with their installers.
+// Servers installed by them are launched with the argument file or the shim JAR which the installers generate.
+type forgeLikeInstaller struct {
+	fetcher  *fetcher
+	executor system.CommandExecutor
+	resolve  func(ctx context.Context, mcVersion string) (string, error)
+	// installerURL returns URL of the installer.
+	installerURL func(mcVersion, version string) string
+	// argsFilePath returns path of the argument file relative to the installation directory.
+	argsFilePath func(mcVersion, version string) string
+	// shimJarPath returns path of the shim JAR relative to the installation directory, if the installer may generate it.
+	shimJarPath func(mcVersion, version string) string
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (i *forgeLikeInstaller) Resolve(ctx context.Context, mcVersion string) (string, error) {
+	return i.resolve(ctx, mcVersion)
+}
+
+func (i *forgeLikeInstaller) Install(ctx context.Context, mcVersion, version, dir string) error {
+	installerURL := i.installerURL(mcVersion, version)
+	sum, err := i.fetcher.mavenChecksum(ctx, installerURL)
+	if err != nil {
+		return err
+	}
+	installerPath := filepath.Join(dir, "installer.jar")
+	if err := i.fetcher.download(ctx, installerURL, installerPath, sum); err != nil {
+		return err
+	}
+	defer os.Remove(installerPath)
+	defer os.Remove(installerPath + ".log")
+
+	if err := runJava(ctx, i.executor, dir, "-jar", installerPath, "--installServer", dir); err != nil {
+		return err
+	}
+
+	if fileExists(filepath.Join(dir, i.argsFilePath(mcVersion, version))) {
+		return nil
+	}
+	if i.shimJarPath != nil && fileExists(filepath.Join(dir, i.shimJarPath(mcVersion, version))) {
+		return nil
+	}
+	// Installers for Minecraft older than 1.17 generate neither of them.
+	return errors.New("installer generated nothing to launch the server")
+}
+
+func (i *forgeLikeInstaller) LaunchArgs(c core.LauncherContext, version, dir string) ([]string, error) {
+	mcVersion := c.Settings().GetMinecraftVersion()
+	if i.shimJarPath != nil {
+		// The shim JAR finds libraries next to it.
+		if shimJar := filepath.Join(dir, i.shimJarPath(mcVersion, version)); fileExists(shimJar) {
+			return []string{"-jar", shimJar}, nil
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, i.argsFilePath(mcVersion, version)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Libraries are referenced relative to the working directory, which is the game data directory.
+	args := relativeLibraryPathRegexp.ReplaceAllString(string(data), "${1}"+filepath.Join(dir, "libraries")+"${2}")
+
+	path := filepath.Join(dir, argsFile)
+	if err := os.WriteFile(path, []byte(args), 0644); err != nil {
+		return nil, err
+	}
+
+	return []string{"@" + path}, nil
+}
+
+const (
+	forgePromotionsURL = "https://files.minecraftforge.net/net/minecraftforge/forge/promotions_slim.json"
+	forgeMavenURL      = "https://maven.minecraftforge.net"
+)
+
+func newForgeInstaller(fetcher *fetcher, executor system.CommandExecutor) *forgeLikeInstaller {
+	return &forgeLikeInstaller{
+		fetcher:  fetcher,
+		executor: executor,
+		resolve: func(ctx context.Context, mcVersion string) (string, error) {
+			return resolveForge(ctx, fetcher, forgePromotionsURL, mcVersion)
+		},
+		installerURL: func(mcVersion, version string) string {
+			return fmt.Sprintf("%s/net/minecraftforge/forge/%[2]s-%[3]s/forge-%[2]s-%[3]s-installer.jar", forgeMavenURL, mcVersion, version)
+		},
+		argsFilePath: func(mcVersion, version string) string {
+			return filepath.Join("libraries/net/minecraftforge/forge", mcVersion+"-"+version, "unix_args.txt")
+		},
+		shimJarPath: func(mcVersion, version string) string {
+			return fmt.Sprintf("forge-%s-%s-shim.jar", mcVersion, version)
+		},
+	}
+}
+
+// resolveForge returns the recommended version of Forge for the Minecraft version, or the latest one if no versions are recommended.
+func resolveForge(ctx context.Context, fetcher *fetcher, promotionsURL, mcVersion string) (string, error) {
+	var promotions struct {
+		Promos map[string]string `json:"promos"`
+	}
+	if err := fetcher.getJSON(ctx, promotionsURL, &promotions); err != nil {
+		return "", err
+	}
+
+	if version, ok := promotions.Promos[mcVersion+"-recommended"]; ok {
+		return version, nil
+	}
+	if version, ok := promotions.Promos[mcVersion+"-latest"]; ok {
+		return version, nil
+	}
+	return "", errNoVersion
+}
+
+const (
+	neoForgeVersionsURL = "https://maven.neoforged.net/api/maven/versions/releases/net/neoforged/neoforge"
+	neoForgeMavenURL    = "https://maven.neoforged.net/releases"
+)
+
+func newNeoForgeInstaller(fetcher *fetcher, executor system.CommandExecutor) *forgeLikeInstaller {
+	return &forgeLikeInstaller{
+		fetcher:  fetcher,
+		executor: executor,
+		resolve: func(ctx context.Context, mcVersion string) (string, error) {
+			return resolveNeoForge(ctx, fetcher, neoForgeVersionsURL, mcVersion)
+		},
+		installerURL: func(mcVersion, version string) string {
+			return fmt.Sprintf("%s/net/neoforged/neoforge/%[2]s/neoforge-%[2]s-installer.jar", neoForgeMavenURL, url.PathEscape(version))
+		},
+		argsFilePath: func(mcVersion, version string) string {
+			return filepath.Join("libraries/net/neoforged/neoforge", version, "unix_args.txt")
+		},
+	}
+}
+
+// neoForgeVersionPrefix returns the prefix of NeoForge versions for the Minecraft version.
+// NeoForge versions are derived from Minecraft versions: 21.1.x for 1.21.1, 21.0.x for 1.21, and 26.1.0.x for 26.1.
+func neoForgeVersionPrefix(mcVersion string) (string, error) {
+	parts := strings.Split(mcVersion, ".")
+	if parts[0] == "1" {
+		parts = parts[1:]
+		if len(parts) == 1 {
+			parts = append(parts, "0")
+		}
+		if len(parts) != 2 {
+			return "", fmt.Errorf("unsupported Minecraft version: %s", mcVersion)
+		}
+	} else {
+		if len(parts) == 2 {
+			parts = append(parts, "0")
+		}
+		if len(parts) != 3 {
+			return "", fmt.Errorf("unsupported Minecraft version: %s", mcVersion)
+		}
+	}
+	return strings.Join(parts, ".") + ".", nil
+}
+
+// resolveNeoForge returns the newest stable version of NeoForge for the Minecraft version, or the newest one if none of them is stable.
+func resolveNeoForge(ctx context.Context, fetcher *fetcher, versionsURL, mcVersion string) (string, error) {
+	prefix, err := neoForgeVersionPrefix(mcVersion)
+	if err != nil {
+		return "", err
+	}
+
+	var versions struct {
+		Versions []string `json:"versions"`
+	}
+	if err := fetcher.getJSON(ctx, versionsURL, &versions); err != nil {
+		return "", err
+	}
+
+	// Versions are listed from the oldest.
+	var candidates []string
+	for _, version := range versions.Versions {
+		if strings.HasPrefix(version, prefix) {
+			candidates = append(candidates, version)
+		}
+	}
+	slices.Reverse(candidates)
+
+	version, err := pickVersion(candidates)
+	if errors.Is(err, errNoVersion) {
+		return "", fmt.Errorf("no NeoForge version is available for Minecraft %s", mcVersion)
+	}
+	return version, err
+}
@@ -0,0 +1,253 @@
+package flavor
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/env"
+	"github.com/kofuk/premises/backend/runner/system"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha1Hex(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("Installers", func() {
+	var (
+		server *httptest.Server
+		mux    *http.ServeMux
+		f      *fetcher
+	)
+
+	BeforeEach(func() {
+		mux = http.NewServeMux()
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+		f = &fetcher{httpClient: server.Client()}
+	})
+
+	serveString := func(pattern, body string) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+	}
+
+	It("should resolve Fabric and install it with the verified installer", func() {
+		serveString("GET /v2/versions/loader/1.21.1", `[{"loader":{"version":"0.17.0-beta.1","stable":false}},{"loader":{"version":"0.16.5","stable":true}}]`)
+		serveString("GET /v2/versions/installer", `[{"version":"1.1.0","stable":true,"url":"`+server.URL+`/fabric-installer-1.1.0.jar"},{"version":"1.0.1","stable":true}]`)
+		serveString("GET /fabric-installer-1.1.0.jar", "installer")
+		serveString("GET /fabric-installer-1.1.0.jar.sha256", sha256Hex("installer")+"  fabric-installer-1.1.0.jar\n")
+
+		ctrl := gomock.NewController(GinkgoT())
+		executor := system.NewMockCommandExecutor(ctrl)
+		installer := newFabricInstaller(f, executor)
+		installer.metaURL = server.URL
+
+		version, err := installer.Resolve(GinkgoT().Context(), "1.21.1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(version).To(Equal("0.16.5-1.1.0"))
+
+		dir := GinkgoT().TempDir()
+		installerPath := filepath.Join(dir, "fabric-installer.jar")
+		executor.EXPECT().Run(gomock.Any(), gomock.Any(), []string{"-jar", installerPath, "server", "-dir", dir, "-mcversion", "1.21.1", "-loader", "0.16.5"}, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, path string, args []string, options ...system.CmdOption) error {
+				Expect(os.ReadFile(installerPath)).To(Equal([]byte("installer")))
+				return os.WriteFile(filepath.Join(dir, "fabric-server-launch.jar"), []byte("launcher"), 0644)
+			})
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", version, dir)).To(Succeed())
+		Expect(installerPath).NotTo(BeAnExistingFile())
+	})
+
+	It("should fail if the installer of Fabric generated no server launcher", func() {
+		serveString("GET /v2/versions/installer", `[{"version":"1.1.0","stable":true,"url":"`+server.URL+`/fabric-installer-1.1.0.jar"}]`)
+		serveString("GET /fabric-installer-1.1.0.jar", "installer")
+		serveString("GET /fabric-installer-1.1.0.jar.sha256", sha256Hex("installer"))
+
+		ctrl := gomock.NewController(GinkgoT())
+		executor := system.NewMockCommandExecutor(ctrl)
+		installer := newFabricInstaller(f, executor)
+		installer.metaURL = server.URL
+
+		// SimpleExecutor doesn't report the exit status of the installer.
+		executor.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", "0.16.5-1.1.0", GinkgoT().TempDir())).To(MatchError("installer generated no server launcher"))
+	})
+
+	It("should install Quilt and fail if the installer generated no server launcher", func() {
+		serveString("GET /v3/versions/installer", `[{"version":"0.9.2","url":"`+server.URL+`/quilt-installer-0.9.2.jar"}]`)
+		serveString("GET /quilt-installer-0.9.2.jar", "installer")
+		serveString("GET /quilt-installer-0.9.2.jar.sha256", sha256Hex("installer"))
+
+		ctrl := gomock.NewController(GinkgoT())
+		executor := system.NewMockCommandExecutor(ctrl)
+		installer := newQuiltInstaller(f, executor)
+		installer.metaURL = server.URL
+
+		dir := GinkgoT().TempDir()
+		installerPath := filepath.Join(dir, "quilt-installer.jar")
+		executor.EXPECT().Run(gomock.Any(), gomock.Any(), []string{"-jar", installerPath, "install", "server", "1.21.1", "0.27.1", "--install-dir=" + dir}, gomock.Any()).Return(nil)
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", "0.27.1", dir)).To(MatchError("installer generated no server launcher"))
+
+		executor.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, path string, args []string, options ...system.CmdOption) error {
+				return os.WriteFile(filepath.Join(dir, "quilt-server-launch.jar"), []byte("launcher"), 0644)
+			})
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", "0.27.1", dir)).To(Succeed())
+		Expect(installerPath).NotTo(BeAnExistingFile())
+	})
+
+	It("should not run the installer of Fabric if its checksum doesn't match", func() {
+		serveString("GET /v2/versions/installer", `[{"version":"1.1.0","stable":true,"url":"`+server.URL+`/fabric-installer-1.1.0.jar"}]`)
+		serveString("GET /fabric-installer-1.1.0.jar", "tampered")
+		serveString("GET /fabric-installer-1.1.0.jar.sha256", sha256Hex("installer"))
+
+		ctrl := gomock.NewController(GinkgoT())
+		installer := newFabricInstaller(f, system.NewMockCommandExecutor(ctrl))
+		installer.metaURL = server.URL
+
+		dir := GinkgoT().TempDir()
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", "0.16.5-1.1.0", dir)).To(MatchError(ContainSubstring("checksum mismatch")))
+		Expect(filepath.Join(dir, "fabric-installer.jar")).NotTo(BeAnExistingFile())
+	})
+
+	It("should fall back to SHA-1 checksums in Maven repositories", func() {
+		serveString("GET /installer.jar.sha1", strings.ToUpper(sha1Hex("installer")))
+
+		sum, err := f.mavenChecksum(GinkgoT().Context(), server.URL+"/installer.jar")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(sum.digest).To(Equal(sha1Hex("installer")))
+
+		serveString("GET /installer.jar", "installer")
+		Expect(f.download(GinkgoT().Context(), server.URL+"/installer.jar", filepath.Join(GinkgoT().TempDir(), "installer.jar"), sum)).To(Succeed())
+	})
+
+	It("should fail without checksums in Maven repositories", func() {
+		serveString("GET /installer.jar.sha256", "not a checksum")
+
+		_, err := f.mavenChecksum(GinkgoT().Context(), server.URL+"/installer.jar")
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should resolve the newest stable build of Paper", func() {
+		serveString("GET /v3/projects/paper/versions/1.21.1/builds", `[
+			{"id":133,"channel":"BETA","downloads":{"server:default":{"url":"`+server.URL+`/paper-133.jar"}}},
+			{"id":132,"channel":"STABLE","downloads":{"server:default":{"url":"`+server.URL+`/paper-132.jar","checksums":{"sha256":"`+sha256Hex("paper")+`"}}}},
+			{"id":131,"channel":"STABLE","downloads":{"server:default":{"url":"`+server.URL+`/paper-131.jar"}}}
+		]`)
+		serveString("GET /paper-132.jar", "paper")
+
+		installer := newPaperInstaller(f)
+		installer.apiURL = server.URL
+
+		version, err := installer.Resolve(GinkgoT().Context(), "1.21.1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(version).To(Equal("132"))
+
+		dir := GinkgoT().TempDir()
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", version, dir)).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(dir, "paper.jar"))).To(Equal([]byte("paper")))
+	})
+
+	It("should not install Paper if its checksum doesn't match", func() {
+		serveString("GET /v3/projects/paper/versions/1.21.1/builds", `[
+			{"id":132,"channel":"STABLE","downloads":{"server:default":{"url":"`+server.URL+`/paper-132.jar","checksums":{"sha256":"`+sha256Hex("paper")+`"}}}},
+			{"id":131,"channel":"STABLE","downloads":{"server:default":{"url":"`+server.URL+`/paper-131.jar"}}}
+		]`)
+		serveString("GET /paper-132.jar", "tampered")
+		serveString("GET /paper-131.jar", "paper")
+
+		installer := newPaperInstaller(f)
+		installer.apiURL = server.URL
+
+		dir := GinkgoT().TempDir()
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", "132", dir)).To(MatchError(ContainSubstring("checksum mismatch")))
+		Expect(filepath.Join(dir, "paper.jar")).NotTo(BeAnExistingFile())
+		Expect(installer.Install(GinkgoT().Context(), "1.21.1", "131", dir)).To(MatchError("build has no checksum"))
+	})
+
+	It("should resolve the recommended version of Forge", func() {
+		serveString("GET /promotions_slim.json", `{"promos":{"1.20.1-latest":"47.3.0","1.20.1-recommended":"47.2.0","1.21.1-latest":"52.0.1"}}`)
+
+		version, err := resolveForge(GinkgoT().Context(), f, server.URL+"/promotions_slim.json", "1.20.1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(version).To(Equal("47.2.0"))
+
+		version, err = resolveForge(GinkgoT().Context(), f, server.URL+"/promotions_slim.json", "1.21.1")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(version).To(Equal("52.0.1"))
+
+		_, err = resolveForge(GinkgoT().Context(), f, server.URL+"/promotions_slim.json", "1.16.5")
+		Expect(err).Should(HaveOccurred())
+	})
+
+	DescribeTable("should resolve NeoForge for the Minecraft version",
+		func(mcVersion, expected string) {
+			serveString("GET /versions", `{"isSnapshot":false,"versions":["21.0.1-beta","21.0.167","21.1.1","21.1.200","21.10.1-beta","21.10.2-beta","26.1.0.1-beta"]}`)
+
+			version, err := resolveNeoForge(GinkgoT().Context(), f, server.URL+"/versions", mcVersion)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(version).To(Equal(expected))
+		},
+		Entry("x.y.0", "1.21", "21.0.167"),
+		Entry("x.y.z", "1.21.1", "21.1.200"),
+		Entry("beta only", "1.21.10", "21.10.2-beta"),
+		Entry("year-based version", "26.1", "26.1.0.1-beta"),
+	)
+
+	It("should rewrite the argument file of Forge to launch from the game data directory", func() {
+		ctrl := gomock.NewController(GinkgoT())
+		settingsRepository := core.NewMockSettingsRepository(ctrl)
+		c := core.NewMockLauncherContext(ctrl)
+		c.EXPECT().Settings().AnyTimes().Return(settingsRepository)
+		settingsRepository.EXPECT().GetMinecraftVersion().AnyTimes().Return("1.20.1")
+
+		dir := GinkgoT().TempDir()
+		argsDir := filepath.Join(dir, "libraries/net/minecraftforge/forge/1.20.1-47.2.0")
+		Expect(os.MkdirAll(argsDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(argsDir, "unix_args.txt"), []byte(
+			"-DlibraryDirectory=libraries\n-p libraries/a/a.jar:libraries/b/b.jar\n--launchTarget forgeserver\n"), 0644)).To(Succeed())
+
+		args, err := newForgeInstaller(f, nil).LaunchArgs(c, "47.2.0", dir)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(args).To(Equal([]string{"@" + filepath.Join(dir, argsFile)}))
+
+		lib := filepath.Join(dir, "libraries")
+		Expect(os.ReadFile(filepath.Join(dir, argsFile))).To(Equal([]byte(
+			"-DlibraryDirectory=" + lib + "\n-p " + lib + "/a/a.jar:" + lib + "/b/b.jar\n--launchTarget forgeserver\n")))
+	})
+
+	It("should launch Fabric with the cached vanilla server", func() {
+		ctrl := gomock.NewController(GinkgoT())
+		settingsRepository := core.NewMockSettingsRepository(ctrl)
+		envProvider := env.NewMockEnvProvider(ctrl)
+		c := core.NewMockLauncherContext(ctrl)
+		c.EXPECT().Settings().AnyTimes().Return(settingsRepository)
+		c.EXPECT().Env().AnyTimes().Return(envProvider)
+
+		gameDir := GinkgoT().TempDir()
+		settingsRepository.EXPECT().GetServerPath().Return("/servers.d/1.21.1.jar")
+		envProvider.EXPECT().GetDataPath("gamedata", "fabric-server-launcher.properties").Return(filepath.Join(gameDir, "fabric-server-launcher.properties"))
+
+		args, err := newFabricInstaller(f, nil).LaunchArgs(c, "0.16.5-1.1.0", "/servers.d/fabric/1.21.1-0.16.5-1.1.0")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(args).To(Equal([]string{"-jar", "/servers.d/fabric/1.21.1-0.16.5-1.1.0/fabric-server-launch.jar"}))
+		Expect(os.ReadFile(filepath.Join(gameDir, "fabric-server-launcher.properties"))).To(Equal([]byte("serverJar=/servers.d/1.21.1.jar\n")))
+	})
+})
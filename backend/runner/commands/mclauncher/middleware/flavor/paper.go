@@ -0,0 +1,94 @@
+package flavor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+)
+
+// paperInstaller installs builds of Paper.
+type paperInstaller struct {
+	fetcher *fetcher
+	apiURL  string
+}
+
+func newPaperInstaller(fetcher *fetcher) *paperInstaller {
+	return &paperInstaller{
+		fetcher: fetcher,
+		apiURL:  "https://fill.papermc.io",
+	}
+}
+
+type paperBuild struct {
+	ID        int    `json:"id"`
+	Channel   string `json:"channel"`
+	Downloads map[string]struct {
+		URL       string `json:"url"`
+		Checksums struct {
+			SHA256 string `json:"sha256"`
+		} `json:"checksums"`
+	} `json:"downloads"`
+}
+
+func (i *paperInstaller) getBuilds(ctx context.Context, mcVersion string) ([]paperBuild, error) {
+	var builds []paperBuild
+	if err := i.fetcher.getJSON(ctx, fmt.Sprintf("%s/v3/projects/paper/versions/%s/builds", i.apiURL, url.PathEscape(mcVersion)), &builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
+
+// Resolve returns the newest stable build, or the newest one if no builds are stable yet.
+func (i *paperInstaller) Resolve(ctx context.Context, mcVersion string) (string, error) {
+	builds, err := i.getBuilds(ctx, mcVersion)
+	if err != nil {
+		return "", err
+	}
+
+	newest, newestStable := -1, -1
+	for _, build := range builds {
+		newest = max(newest, build.ID)
+		if build.Channel == "STABLE" || build.Channel == "RECOMMENDED" {
+			newestStable = max(newestStable, build.ID)
+		}
+	}
+	if newestStable >= 0 {
+		return strconv.Itoa(newestStable), nil
+	}
+	if newest >= 0 {
+		return strconv.Itoa(newest), nil
+	}
+	return "", errNoVersion
+}
+
+func (i *paperInstaller) Install(ctx context.Context, mcVersion, version, dir string) error {
+	builds, err := i.getBuilds(ctx, mcVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, build := range builds {
+		if strconv.Itoa(build.ID) != version {
+			continue
+		}
+		download, ok := build.Downloads["server:default"]
+		if !ok {
+			return errors.New("build has no server download")
+		}
+		if download.Checksums.SHA256 == "" {
+			return errors.New("build has no checksum")
+		}
+		return i.fetcher.download(ctx, download.URL, filepath.Join(dir, "paper.jar"), sha256Checksum(strings.ToLower(download.Checksums.SHA256)))
+	}
+	return fmt.Errorf("build %s not found", version)
+}
+
+func (i *paperInstaller) LaunchArgs(c core.LauncherContext, version, dir string) ([]string, error) {
+	return []string{"-jar", filepath.Join(dir, "paper.jar")}, nil
+}
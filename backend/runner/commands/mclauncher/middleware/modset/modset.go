@@ -0,0 +1,204 @@
+package modset
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/retry"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+)
+
+// StateKeyInstalledModSet holds the installedModSet which was installed on the last launch.
+const StateKeyInstalledModSet = "github.com/kofuk/premises/runner/mclauncher/middleware/modset.InstalledModSet"
+
+type ModSetSource interface {
+	GetModSet(ctx context.Context, name string) ([]web.ModSetFileDownload, error)
+}
+
+// installedModSet records files which the middleware installed, so that unchanged ones are not downloaded again
+// and stale ones are removed.
+type installedModSet struct {
+	Name string `json:"name"`
+	// Dir is the directory the files were installed into, relative to the game data directory.
+	Dir string `json:"dir"`
+	// Files maps names of the files to their SHA-256 checksums.
+	Files map[string]string `json:"files"`
+}
+
+type ModSetMiddleware struct {
+	source     ModSetSource
+	httpClient *http.Client
+}
+
+var _ core.Middleware = (*ModSetMiddleware)(nil)
+
+// NewModSetMiddleware creates a middleware which syncs the mod set selected for the launch into mods, or plugins for Paper.
+func NewModSetMiddleware(source ModSetSource, httpClient *http.Client) *ModSetMiddleware {
+	return &ModSetMiddleware{
+		source:     source,
+		httpClient: httpClient,
+	}
+}
+
+// installDir returns the directory which the server loads the files from.
+func installDir(flavor string) string {
+	if flavor == runner.FlavorPaper {
+		return "plugins"
+	}
+	return "mods"
+}
+
+func loadInstalled(c core.LauncherContext) installedModSet {
+	var installed installedModSet
+	state, err := c.State().GetState(c.Context(), StateKeyInstalledModSet)
+	if err != nil || state == "" {
+		return installed
+	}
+	if err := json.Unmarshal([]byte(state), &installed); err != nil {
+		slog.WarnContext(c.Context(), "Unable to parse installed mod set", slog.Any("error", err))
+		return installedModSet{}
+	}
+	return installed
+}
+
+func saveInstalled(c core.LauncherContext, installed installedModSet) error {
+	if len(installed.Files) == 0 {
+		return c.State().RemoveState(c.Context(), StateKeyInstalledModSet)
+	}
+	data, err := json.Marshal(installed)
+	if err != nil {
+		return err
+	}
+	return c.State().SetState(c.Context(), StateKeyInstalledModSet, string(data))
+}
+
+func (m *ModSetMiddleware) download(ctx context.Context, file web.ModSetFileDownload, dest string) error {
+	tmpPath := dest + ".tmp"
+	defer os.Remove(tmpPath)
+
+	_, err := retry.Retry(ctx, func(ctx context.Context) (retry.Void, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL, nil)
+		if err != nil {
+			return retry.V, err
+		}
+
+		resp, err := m.httpClient.Do(req)
+		if err != nil {
+			return retry.V, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			io.CopyN(io.Discard, resp.Body, 10*1024)
+			return retry.V, fmt.Errorf("downloading %s failed with status code %d", file.Name, resp.StatusCode)
+		}
+
+		out, err := os.Create(tmpPath)
+		if err != nil {
+			return retry.V, err
+		}
+		defer out.Close()
+
+		hash := sha256.New()
+		if _, err := io.Copy(io.MultiWriter(out, hash), resp.Body); err != nil {
+			return retry.V, err
+		}
+		if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != file.Sha256 {
+			return retry.V, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file.Name, file.Sha256, checksum)
+		}
+		return retry.V, out.Close()
+	}, time.Minute)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, dest)
+}
+
+func (m *ModSetMiddleware) sync(c core.LauncherContext) error {
+	ctx := c.Context()
+	installed := loadInstalled(c)
+
+	name := c.Settings().GetModSet()
+	var files []web.ModSetFileDownload
+	if name != "" {
+		var err error
+		files, err = m.source.GetModSet(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to get mod set: %w", err)
+		}
+	}
+	if len(files) == 0 && len(installed.Files) == 0 {
+		return nil
+	}
+
+	dirName := installDir(c.Settings().GetServerFlavor())
+	dir := c.Env().GetDataPath("gamedata", dirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	wanted := make(map[string]string, len(files))
+	for _, file := range files {
+		if filepath.Base(file.Name) != file.Name {
+			return fmt.Errorf("invalid file name in mod set: %s", file.Name)
+		}
+		wanted[file.Name] = file.Sha256
+	}
+
+	result := installedModSet{Name: name, Dir: dirName, Files: make(map[string]string, len(files))}
+
+	// Remove files which are no longer in the mod set, were changed, or were installed into the other directory.
+	// Files which were put there by other means are left as they are.
+	for fileName, checksum := range installed.Files {
+		if installed.Dir == dirName && wanted[fileName] == checksum {
+			result.Files[fileName] = checksum
+			continue
+		}
+		slog.InfoContext(ctx, "Removing stale mod set file", slog.String("name", fileName))
+		if err := os.Remove(c.Env().GetDataPath("gamedata", installed.Dir, fileName)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		dest := filepath.Join(dir, file.Name)
+		if _, ok := result.Files[file.Name]; ok {
+			if _, err := os.Stat(dest); err == nil {
+				continue
+			}
+			// It was removed, e.g. on the update of Minecraft.
+		}
+
+		slog.InfoContext(ctx, "Installing mod set file", slog.String("mod_set", name), slog.String("name", file.Name))
+		if err := m.download(ctx, file, dest); err != nil {
+			// Record what is installed so far, so that the next launch doesn't leave them behind.
+			delete(result.Files, file.Name)
+			saveInstalled(c, result)
+			return err
+		}
+		result.Files[file.Name] = file.Sha256
+	}
+
+	return saveInstalled(c, result)
+}
+
+func (m *ModSetMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
+	return func(c core.LauncherContext) error {
+		if err := m.sync(c); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
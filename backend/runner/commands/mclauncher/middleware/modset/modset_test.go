@@ -0,0 +1,154 @@
+package modset_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/modset"
+	"github.com/kofuk/premises/backend/runner/env"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSource struct {
+	files []web.ModSetFileDownload
+}
+
+func (s *fakeSource) GetModSet(ctx context.Context, name string) ([]web.ModSetFileDownload, error) {
+	return s.files, nil
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("ModSetMiddleware", func() {
+	var (
+		tempDir            string
+		ctrl               *gomock.Controller
+		settingsRepository *core.MockSettingsRepository
+		envProvider        *env.MockEnvProvider
+		stateRepository    *core.MockStateRepository
+		launcher           *core.LauncherCore
+		server             *httptest.Server
+		source             *fakeSource
+		state              map[string]string
+		requests           []string
+		mu                 sync.Mutex
+	)
+
+	file := func(name, content string) web.ModSetFileDownload {
+		return web.ModSetFileDownload{Name: name, URL: server.URL + "/" + content, Sha256: checksum(content)}
+	}
+
+	BeforeEach(func() {
+		tempDir = GinkgoT().TempDir()
+		ctrl = gomock.NewController(GinkgoT())
+		settingsRepository = core.NewMockSettingsRepository(ctrl)
+		envProvider = env.NewMockEnvProvider(ctrl)
+		stateRepository = core.NewMockStateRepository(ctrl)
+
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests = append(requests, r.URL.Path)
+			mu.Unlock()
+			w.Write([]byte(r.URL.Path[1:]))
+		}))
+		DeferCleanup(server.Close)
+
+		state = map[string]string{}
+		stateRepository.EXPECT().GetState(gomock.Any(), modset.StateKeyInstalledModSet).AnyTimes().DoAndReturn(func(ctx context.Context, key string) (string, error) {
+			return state[key], nil
+		})
+		stateRepository.EXPECT().SetState(gomock.Any(), modset.StateKeyInstalledModSet, gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, key, value string) error {
+			state[key] = value
+			return nil
+		})
+		stateRepository.EXPECT().RemoveState(gomock.Any(), modset.StateKeyInstalledModSet).AnyTimes().DoAndReturn(func(ctx context.Context, key string) error {
+			delete(state, key)
+			return nil
+		})
+
+		envProvider.EXPECT().GetDataPath(gomock.Any()).AnyTimes().DoAndReturn(func(path ...string) string {
+			return filepath.Join(append([]string{tempDir}, path...)...)
+		})
+
+		source = &fakeSource{}
+		launcher = core.NewLauncherCore(settingsRepository, envProvider, stateRepository)
+		launcher.Use(core.StopMiddleware)
+		launcher.Use(modset.NewModSetMiddleware(source, server.Client()))
+	})
+
+	It("should sync the mod set and skip unchanged files on relaunch", func() {
+		settingsRepository.EXPECT().GetModSet().AnyTimes().Return("sample")
+		settingsRepository.EXPECT().GetServerFlavor().AnyTimes().Return(runner.FlavorFabric)
+
+		modsDir := filepath.Join(tempDir, "gamedata", "mods")
+		Expect(os.MkdirAll(modsDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(modsDir, "manual.jar"), []byte("manual"), 0644)).To(Succeed())
+
+		source.files = []web.ModSetFileDownload{file("a.jar", "a1"), file("b.jar", "b1")}
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(modsDir, "a.jar"))).To(Equal([]byte("a1")))
+		Expect(os.ReadFile(filepath.Join(modsDir, "b.jar"))).To(Equal([]byte("b1")))
+		Expect(requests).To(ConsistOf("/a1", "/b1"))
+
+		requests = nil
+		source.files = []web.ModSetFileDownload{file("a.jar", "a1"), file("c.jar", "c1")}
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+
+		Expect(requests).To(Equal([]string{"/c1"}))
+		Expect(os.ReadFile(filepath.Join(modsDir, "a.jar"))).To(Equal([]byte("a1")))
+		Expect(filepath.Join(modsDir, "b.jar")).NotTo(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(modsDir, "c.jar"))).To(Equal([]byte("c1")))
+		Expect(filepath.Join(modsDir, "manual.jar")).To(BeAnExistingFile())
+		Expect(state[modset.StateKeyInstalledModSet]).To(MatchJSON(`{"name":"sample","dir":"mods","files":{"a.jar":"` + checksum("a1") + `","c.jar":"` + checksum("c1") + `"}}`))
+	})
+
+	It("should install plugins for Paper and remove them when no mod set is selected", func() {
+		modSet := "sample"
+		settingsRepository.EXPECT().GetModSet().AnyTimes().DoAndReturn(func() string { return modSet })
+		settingsRepository.EXPECT().GetServerFlavor().AnyTimes().Return(runner.FlavorPaper)
+
+		source.files = []web.ModSetFileDownload{file("a.jar", "a1")}
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(filepath.Join(tempDir, "gamedata", "plugins", "a.jar")).To(BeAnExistingFile())
+
+		modSet = ""
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(filepath.Join(tempDir, "gamedata", "plugins", "a.jar")).NotTo(BeAnExistingFile())
+		Expect(state).To(BeEmpty())
+	})
+
+	It("should download files again if they were removed", func() {
+		settingsRepository.EXPECT().GetModSet().AnyTimes().Return("sample")
+		settingsRepository.EXPECT().GetServerFlavor().AnyTimes().Return(runner.FlavorForge)
+
+		source.files = []web.ModSetFileDownload{file("a.jar", "a1")}
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(os.RemoveAll(filepath.Join(tempDir, "gamedata", "mods"))).To(Succeed())
+
+		Expect(launcher.Start(GinkgoT().Context())).To(Succeed())
+		Expect(requests).To(Equal([]string{"/a1", "/a1"}))
+		Expect(filepath.Join(tempDir, "gamedata", "mods", "a.jar")).To(BeAnExistingFile())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ModSetMiddleware Suite")
+}
@@ -1,21 +1,37 @@
 package watchdog
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/shutdown"
 )
 
+// InactivityStopDelay is the countdown before the server stops because of inactivity.
+// Players who join during it cancel the stop.
+const InactivityStopDelay = time.Minute
+
+type Stopper interface {
+	Schedule(ctx context.Context, delay time.Duration, reason shutdown.Reason, actor int) error
+	CancelInactivityStop(ctx context.Context) bool
+	IsPending() bool
+}
+
 type ActivenessWatchdog struct {
 	rcon           *rcon.Rcon
+	stopper        Stopper
 	timeoutMinutes int
 	lastActive     int
+	stopping       bool
 }
 
-func NewActivenessWatchdog(rcon *rcon.Rcon, timeoutMinutes int) *ActivenessWatchdog {
+func NewActivenessWatchdog(rcon *rcon.Rcon, stopper Stopper, timeoutMinutes int) *ActivenessWatchdog {
 	return &ActivenessWatchdog{
 		rcon:           rcon,
+		stopper:        stopper,
 		timeoutMinutes: timeoutMinutes,
 		lastActive:     -1,
 	}
@@ -43,8 +59,18 @@ func (w *ActivenessWatchdog) Check(c core.LauncherContext, watchID int, status *
 		w.lastActive = watchID
 	}
 
-	if watchID%60 != 0 {
-		// Only check every 60 seconds
+	if w.stopping && !w.stopper.IsPending() {
+		// The stop was cancelled by a user, so start counting again.
+		w.stopping = false
+		w.lastActive = watchID
+	}
+
+	interval := 60
+	if w.stopping {
+		// Check more frequently so that players who join during the countdown can cancel it
+		interval = 10
+	}
+	if watchID%interval != 0 {
 		return nil
 	}
 
@@ -57,12 +83,24 @@ func (w *ActivenessWatchdog) Check(c core.LauncherContext, watchID int, status *
 
 	if active {
 		w.lastActive = watchID
-	} else {
+		if w.stopping {
+			slog.DebugContext(c.Context(), "A player joined, cancelling the stop")
+
+			w.stopping = false
+			w.stopper.CancelInactivityStop(c.Context())
+		}
+	} else if !w.stopping {
 		minutesSinceLastActive := (watchID - w.lastActive) / 60
 		if minutesSinceLastActive > w.timeoutMinutes {
+			if w.stopper.IsPending() {
+				// The server is already going to stop.
+				return nil
+			}
+
 			slog.DebugContext(c.Context(), "Server is inactive, stopping the server", slog.Int("minutes", minutesSinceLastActive))
 
-			return w.rcon.Stop(c.Context())
+			w.stopping = true
+			return w.stopper.Schedule(c.Context(), InactivityStopDelay, shutdown.ReasonInactive, 0)
 		}
 	}
 
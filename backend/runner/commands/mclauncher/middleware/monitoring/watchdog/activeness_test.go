@@ -1,20 +1,49 @@
 package watchdog_test
 
 import (
+	"context"
+	"time"
+
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/monitoring/watchdog"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/shutdown"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
 )
 
+type fakeStopper struct {
+	scheduled []time.Duration
+	reasons   []shutdown.Reason
+	cancelled int
+	pending   bool
+}
+
+func (s *fakeStopper) Schedule(ctx context.Context, delay time.Duration, reason shutdown.Reason, actor int) error {
+	s.scheduled = append(s.scheduled, delay)
+	s.reasons = append(s.reasons, reason)
+	s.pending = true
+	return nil
+}
+
+func (s *fakeStopper) CancelInactivityStop(ctx context.Context) bool {
+	s.cancelled++
+	s.pending = false
+	return true
+}
+
+func (s *fakeStopper) IsPending() bool {
+	return s.pending
+}
+
 var _ = Describe("ActivenessWatchdog", func() {
 	var (
 		ctrl     *gomock.Controller
 		executor *rcon.MockRconExecutorInterface
 		rc       *rcon.Rcon
 		lc       *core.MockLauncherContext
+		stopper  *fakeStopper
 	)
 
 	BeforeEach(func() {
@@ -23,6 +52,7 @@ var _ = Describe("ActivenessWatchdog", func() {
 		rc = rcon.NewRcon(executor)
 		lc = core.NewMockLauncherContext(ctrl)
 		lc.EXPECT().Context().AnyTimes().Return(GinkgoT().Context())
+		stopper = &fakeStopper{}
 	})
 
 	It("should stop the server after timeout", func() {
@@ -30,10 +60,9 @@ var _ = Describe("ActivenessWatchdog", func() {
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 1 of a max of 20 players online: kofun8", nil), // 0
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 60
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 120
-			executor.EXPECT().Exec(gomock.Any(), "stop").Return("", nil),                                                  // 120
 		)
 
-		wd := watchdog.NewActivenessWatchdog(rc, 1)
+		wd := watchdog.NewActivenessWatchdog(rc, stopper, 1)
 		status := &watchdog.Status{
 			Online: true,
 		}
@@ -42,6 +71,8 @@ var _ = Describe("ActivenessWatchdog", func() {
 			err := wd.Check(lc, time, status)
 			Expect(err).To(BeNil())
 		}
+		Expect(stopper.scheduled).To(Equal([]time.Duration{watchdog.InactivityStopDelay}))
+		Expect(stopper.reasons).To(Equal([]shutdown.Reason{shutdown.ReasonInactive}))
 	})
 
 	It("should calculate correct timeout even if users login/logout the server", func() {
@@ -51,10 +82,9 @@ var _ = Describe("ActivenessWatchdog", func() {
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 1 of a max of 20 players online: kofun8", nil), // 120
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 180
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 240
-			executor.EXPECT().Exec(gomock.Any(), "stop").Return("", nil),                                                  // 240
 		)
 
-		wd := watchdog.NewActivenessWatchdog(rc, 1)
+		wd := watchdog.NewActivenessWatchdog(rc, stopper, 1)
 		status := &watchdog.Status{
 			Online: true,
 		}
@@ -62,7 +92,11 @@ var _ = Describe("ActivenessWatchdog", func() {
 		for _, time := range []int{0, 60, 120, 180, 240} {
 			err := wd.Check(lc, time, status)
 			Expect(err).To(BeNil())
+			if time < 240 {
+				Expect(stopper.scheduled).To(BeEmpty())
+			}
 		}
+		Expect(stopper.scheduled).To(HaveLen(1))
 	})
 
 	It("should start counting when server goes online", func() {
@@ -71,10 +105,9 @@ var _ = Describe("ActivenessWatchdog", func() {
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 1 of a max of 20 players online: kofun8", nil), // 240
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 300
 			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 360
-			executor.EXPECT().Exec(gomock.Any(), "stop").Return("", nil),                                                  // 360
 		)
 
-		wd := watchdog.NewActivenessWatchdog(rc, 1)
+		wd := watchdog.NewActivenessWatchdog(rc, stopper, 1)
 
 		calls := []struct {
 			watchID int
@@ -97,5 +130,51 @@ var _ = Describe("ActivenessWatchdog", func() {
 			err := wd.Check(lc, call.watchID, status)
 			Expect(err).To(BeNil())
 		}
+		Expect(stopper.scheduled).To(HaveLen(1))
+	})
+
+	It("should cancel the stop if a player joins during the countdown", func() {
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 0
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 60
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 120
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 130
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 1 of a max of 20 players online: kofun8", nil), // 140
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil),       // 180
+		)
+
+		wd := watchdog.NewActivenessWatchdog(rc, stopper, 1)
+		status := &watchdog.Status{
+			Online: true,
+		}
+
+		for _, time := range []int{0, 60, 120, 125, 130, 140, 150, 180} {
+			err := wd.Check(lc, time, status)
+			Expect(err).To(BeNil())
+		}
+		Expect(stopper.scheduled).To(HaveLen(1))
+		Expect(stopper.cancelled).To(Equal(1))
+	})
+
+	It("should not schedule another stop while a stop is pending", func() {
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil), // 0
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil), // 60
+			executor.EXPECT().Exec(gomock.Any(), "list").Return("There are 0 of a max of 20 players online: ", nil), // 120
+		)
+
+		// e.g. A user requested to stop the server with a delay.
+		stopper.pending = true
+
+		wd := watchdog.NewActivenessWatchdog(rc, stopper, 1)
+		status := &watchdog.Status{
+			Online: true,
+		}
+
+		for _, time := range []int{0, 60, 120} {
+			err := wd.Check(lc, time, status)
+			Expect(err).To(BeNil())
+		}
+		Expect(stopper.scheduled).To(BeEmpty())
 	})
 })
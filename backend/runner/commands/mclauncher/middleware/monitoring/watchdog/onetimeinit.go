@@ -56,6 +56,7 @@ func (l *OneTimeInitWatchdog) Check(c core.LauncherContext, watchID int, status
 	data := &runner.StartedExtra{}
 	data.ServerVersion = c.Settings().GetMinecraftVersion()
 	data.World.Name = c.Settings().GetWorldName()
+	data.CommandLine = c.Settings().GetCommandLine()
 	seed, err := l.rcon.Seed(c.Context())
 	if err != nil {
 		slog.ErrorContext(c.Context(), "Failed to retrieve seed", slog.Any("error", err))
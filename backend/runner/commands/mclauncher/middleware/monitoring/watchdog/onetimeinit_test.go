@@ -33,6 +33,7 @@ var _ = Describe("OneTimeInitWatchdog", func() {
 		settingsRepository := core.NewMockSettingsRepository(ctrl)
 		settingsRepository.EXPECT().GetWorldName().Return("foo")
 		settingsRepository.EXPECT().GetMinecraftVersion().Return("1.16.5")
+		settingsRepository.EXPECT().GetCommandLine().Return([]string{"java", "-Xmx1024M", "-Xms1024M", "-jar", "server.jar", "nogui"})
 
 		lc.EXPECT().Settings().AnyTimes().Return(settingsRepository)
 
@@ -0,0 +1,71 @@
+package watchdog
+
+import (
+	"slices"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/exterior"
+)
+
+// This is not a real watchdog, but we'll use watchdog mechanism
+// to sample online players periodically and report changes to the control plane.
+type PlayersWatchdog struct {
+	rcon      *rcon.Rcon
+	published bool
+	players   []string
+}
+
+var _ Watchdog = (*PlayersWatchdog)(nil)
+
+func NewPlayersWatchdog(rcon *rcon.Rcon) *PlayersWatchdog {
+	return &PlayersWatchdog{
+		rcon: rcon,
+	}
+}
+
+func (w *PlayersWatchdog) Name() string {
+	return "PlayersWatchdog"
+}
+
+func (w *PlayersWatchdog) publish(c core.LauncherContext, maxPlayers int, players []string) {
+	w.published = true
+	w.players = players
+
+	exterior.SendEvent(c.Context(), runner.Event{
+		Type: runner.EventPlayers,
+		Players: &runner.PlayersExtra{
+			MaxPlayers: maxPlayers,
+			Players:    players,
+		},
+	})
+}
+
+func (w *PlayersWatchdog) Check(c core.LauncherContext, watchID int, status *Status) error {
+	if !status.Online {
+		if w.published && len(w.players) != 0 {
+			// Nobody can be online while the server is down.
+			w.publish(c, 0, nil)
+		}
+		return nil
+	}
+
+	if watchID%10 != 0 {
+		// Only check every 10 seconds
+		return nil
+	}
+
+	output, err := w.rcon.List(c.Context())
+	if err != nil {
+		return err
+	}
+
+	if w.published && slices.Equal(w.players, output.Players) {
+		return nil
+	}
+
+	w.publish(c, output.MaxPlayers, output.Players)
+
+	return nil
+}
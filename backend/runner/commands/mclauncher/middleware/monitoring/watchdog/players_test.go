@@ -0,0 +1,63 @@
+package watchdog_test
+
+import (
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/monitoring/watchdog"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("PlayersWatchdog", func() {
+	var (
+		ctrl     *gomock.Controller
+		executor *rcon.MockRconExecutorInterface
+		rc       *rcon.Rcon
+		lc       *core.MockLauncherContext
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		executor = rcon.NewMockRconExecutorInterface(ctrl)
+		rc = rcon.NewRcon(executor)
+		lc = core.NewMockLauncherContext(ctrl)
+		lc.EXPECT().Context().AnyTimes().Return(GinkgoT().Context())
+	})
+
+	It("should sample players every 10 seconds while online", func() {
+		executor.EXPECT().Exec(gomock.Any(), "list").Times(2).Return("There are 1 of a max of 20 players online: kofun8", nil)
+
+		wd := watchdog.NewPlayersWatchdog(rc)
+		status := &watchdog.Status{
+			Online: true,
+		}
+
+		for time := range 20 {
+			err := wd.Check(lc, time, status)
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("should not sample players while offline", func() {
+		wd := watchdog.NewPlayersWatchdog(rc)
+		status := &watchdog.Status{
+			Online: false,
+		}
+
+		err := wd.Check(lc, 0, status)
+		Expect(err).To(BeNil())
+	})
+
+	It("should report an error for malformed output", func() {
+		executor.EXPECT().Exec(gomock.Any(), "list").Return("", nil)
+
+		wd := watchdog.NewPlayersWatchdog(rc)
+		status := &watchdog.Status{
+			Online: true,
+		}
+
+		err := wd.Check(lc, 0, status)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -16,7 +16,8 @@ func NewServerPropertiesMiddleware() *ServerPropertiesMiddleware {
 	return &ServerPropertiesMiddleware{}
 }
 
-func (m *ServerPropertiesMiddleware) createServerPropertiesFile(c core.LauncherContext) error {
+// WriteServerPropertiesFile generates server.properties from the current settings.
+func WriteServerPropertiesFile(c core.LauncherContext) error {
 	slog.InfoContext(c.Context(), "Creating server.properties")
 
 	serverProperties := NewServerPropertiesGenerator()
@@ -45,7 +46,7 @@ func (m *ServerPropertiesMiddleware) createServerPropertiesFile(c core.LauncherC
 
 func (m *ServerPropertiesMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
 	return func(c core.LauncherContext) error {
-		if err := m.createServerPropertiesFile(c); err != nil {
+		if err := WriteServerPropertiesFile(c); err != nil {
 			return fmt.Errorf("failed to create server.properties file: %w", err)
 		}
 		return next(c)
@@ -3,14 +3,22 @@ package service
 import (
 	"archive/tar"
 	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/manifest"
+	"github.com/kofuk/premises/backend/runner/exterior"
 	"github.com/kofuk/premises/backend/runner/fs"
 	"github.com/ulikunitz/xz"
 )
@@ -221,13 +229,94 @@ func (*TarUnarchiver) Unarchive(r io.Reader, c *FileCreator) error {
 	return nil
 }
 
+// BlobFetcher fetches blobs which incremental backups refer to.
+type BlobFetcher interface {
+	// Prepare is called with all blobs to fetch before Fetch is called.
+	Prepare(hashes []string) error
+	// Fetch returns zstd-compressed content of the blob.
+	Fetch(hash string) (io.ReadCloser, error)
+}
+
+type ManifestUnarchiver struct {
+	fetcher BlobFetcher
+}
+
+func NewManifestUnarchiver(fetcher BlobFetcher) *ManifestUnarchiver {
+	return &ManifestUnarchiver{
+		fetcher: fetcher,
+	}
+}
+
+func (u *ManifestUnarchiver) createFile(f manifest.File, c *FileCreator) error {
+	body, err := u.fetcher.Fetch(f.Hash)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	zstdr, err := zstd.NewReader(body)
+	if err != nil {
+		return err
+	}
+	defer zstdr.Close()
+
+	hash := sha256.New()
+	r := io.TeeReader(zstdr, hash)
+	if err := c.CreateFile(f.Path, false, r); err != nil {
+		return err
+	}
+	// FileCreator doesn't read files outside the world.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != f.Hash {
+		return fmt.Errorf("blob is corrupted: %s: expected %s, got %s", f.Path, f.Hash, actual)
+	}
+	return nil
+}
+
+func (u *ManifestUnarchiver) Unarchive(r io.Reader, c *FileCreator) error {
+	m, err := manifest.Read(r)
+	if err != nil {
+		return err
+	}
+
+	if err := u.fetcher.Prepare(m.Blobs()); err != nil {
+		return err
+	}
+
+	for _, f := range m.Files {
+		if f.Dir {
+			if err := c.CreateFile(f.Path, true, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := u.createFile(f, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
 type ExtractionPipeline struct {
 	D Decompressor
 	U Unarchiver
 	C *FileCreator
+	// Checksum is the expected hex-encoded SHA-256 of the input. Empty means it is not verified.
+	Checksum string
 }
 
-func (p *ExtractionPipeline) Run(r io.Reader) error {
+func (p *ExtractionPipeline) Run(ctx context.Context, r io.Reader) error {
+	hash := sha256.New()
+	r = io.TeeReader(r, hash)
+	raw := r
+
 	if p.D != nil {
 		var err error
 		r, err = p.D.ToDecompressed(r)
@@ -243,6 +332,23 @@ func (p *ExtractionPipeline) Run(r io.Reader) error {
 		return err
 	}
 
+	if p.Checksum != "" {
+		// Unarchivers may stop reading before the end of the input.
+		if _, err := io.Copy(io.Discard, raw); err != nil {
+			return err
+		}
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != p.Checksum {
+			slog.ErrorContext(ctx, "World checksum mismatch", slog.String("expected", p.Checksum), slog.String("actual", actual))
+			exterior.DispatchEvent(ctx, runner.Event{
+				Type: runner.EventStatus,
+				Status: &runner.StatusExtra{
+					EventCode: entity.EventWorldErr,
+				},
+			})
+			return ErrChecksumMismatch
+		}
+	}
+
 	if err := p.C.Finalize(); err != nil {
 		return err
 	}
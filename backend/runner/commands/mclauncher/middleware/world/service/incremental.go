@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/manifest"
+	"github.com/kofuk/premises/backend/runner/exterior"
+)
+
+// Number of blobs requested to the control plane at once.
+const blobBatchSize = 1000
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// buildManifest creates a manifest of files in baseDir/dir and returns it with local paths of the blobs.
+func buildManifest(baseDir, dir string) (*manifest.Manifest, map[string]string, error) {
+	m := &manifest.Manifest{
+		Version: manifest.Version,
+	}
+	blobPaths := make(map[string]string)
+
+	var levelDat *manifest.File
+	err := fs.WalkDir(os.DirFS(filepath.Join(baseDir, dir)), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(filepath.Join(dir, path))
+		switch {
+		case d.Type().IsDir():
+			m.Files = append(m.Files, manifest.File{
+				Path: name,
+				Dir:  true,
+			})
+
+		case d.Type().IsRegular():
+			localPath := filepath.Join(baseDir, dir, path)
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			hash, err := hashFile(localPath)
+			if err != nil {
+				return err
+			}
+			blobPaths[hash] = localPath
+
+			file := manifest.File{
+				Path: name,
+				Size: info.Size(),
+				Hash: hash,
+			}
+			if path == "level.dat" {
+				levelDat = &file
+			} else {
+				m.Files = append(m.Files, file)
+			}
+
+		default:
+			return errors.New("unsupported file type")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Put level.dat right after the world directory so that the world is found before other files are extracted.
+	if levelDat != nil {
+		m.Files = append(m.Files[:1], append([]manifest.File{*levelDat}, m.Files[1:]...)...)
+	}
+
+	return m, blobPaths, nil
+}
+
+func batches(hashes []string) [][]string {
+	var result [][]string
+	for len(hashes) > blobBatchSize {
+		result = append(result, hashes[:blobBatchSize])
+		hashes = hashes[blobBatchSize:]
+	}
+	if len(hashes) > 0 {
+		result = append(result, hashes)
+	}
+	return result
+}
+
+// uploadBlob compresses and uploads the file, and returns the size of the original file.
+func (w *WorldService) uploadBlob(ctx context.Context, url, path string) (int64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if err != nil {
+		return 0, err
+	}
+	compressed := encoder.EncodeAll(content, nil)
+	encoder.Close()
+
+	if err := w.put(ctx, url, "application/zstd", bytes.NewReader(compressed), int64(len(compressed))); err != nil {
+		return 0, err
+	}
+	return int64(len(content)), nil
+}
+
+func (w *WorldService) uploadIncremental(ctx context.Context, worldName, baseDir string, autoBackup bool) (string, error) {
+	m, blobPaths, err := buildManifest(baseDir, "world")
+	if err != nil {
+		return "", fmt.Errorf("failed to create manifest: %w", err)
+	}
+
+	urls := make(map[string]string)
+	for _, hashes := range batches(m.Blobs()) {
+		batch, err := w.client.CreateBlobUploadURLs(ctx, hashes)
+		if err != nil {
+			return "", err
+		}
+		for hash, url := range batch {
+			urls[hash] = url
+		}
+	}
+
+	slog.InfoContext(ctx, "Uploading changed files", slog.Int("changed", len(urls)), slog.Int("total", len(blobPaths)))
+
+	var uploadSize, uploaded int64
+	for hash := range urls {
+		if info, err := os.Stat(blobPaths[hash]); err == nil {
+			uploadSize += info.Size()
+		}
+	}
+
+	var prevUpdate time.Time
+	for hash, url := range urls {
+		size, err := w.uploadBlob(ctx, url, blobPaths[hash])
+		if err != nil {
+			return "", fmt.Errorf("failed to upload blob: %w", err)
+		}
+
+		uploaded += size
+		if !autoBackup && time.Since(prevUpdate) >= time.Second && uploadSize != 0 {
+			prevUpdate = time.Now()
+			exterior.SendEvent(ctx, runner.Event{
+				Type: runner.EventStatus,
+				Status: &runner.StatusExtra{
+					EventCode: entity.EventWorldUpload,
+					Progress:  int(min(uploaded*100/uploadSize, 100)),
+				},
+			})
+		}
+	}
+
+	body, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	checksum := sha256.Sum256(body)
+
+	uploadURLResp, err := w.client.CreateWorldUploadURL(ctx, worldName, runner.BackupFormatIncremental)
+	if err != nil {
+		return "", err
+	}
+
+	if err := w.put(ctx, uploadURLResp.URL, "application/json", bytes.NewReader(body), int64(len(body))); err != nil {
+		return "", err
+	}
+
+	req := web.CompleteWorldUploadRequest{
+		WorldID:    uploadURLResp.WorldID,
+		Size:       int64(len(body)),
+		AutoBackup: autoBackup,
+		Checksum:   hex.EncodeToString(checksum[:]),
+		Level:      readLevelInfo(ctx, baseDir),
+	}
+	if err := w.client.CompleteWorldUpload(ctx, req); err != nil {
+		// The world has been uploaded anyway. Control plane will find it later.
+		slog.ErrorContext(ctx, "Failed to notify completion of upload", slog.Any("error", err))
+	}
+
+	return uploadURLResp.WorldID, nil
+}
+
+type remoteBlobFetcher struct {
+	ctx        context.Context
+	w          *WorldService
+	urls       map[string]string
+	httpClient *http.Client
+}
+
+func (w *WorldService) newBlobFetcher(ctx context.Context) *remoteBlobFetcher {
+	return &remoteBlobFetcher{
+		ctx:        ctx,
+		w:          w,
+		urls:       make(map[string]string),
+		httpClient: w.httpClient,
+	}
+}
+
+func (f *remoteBlobFetcher) Prepare(hashes []string) error {
+	for _, batch := range batches(hashes) {
+		urls, err := f.w.client.CreateBlobDownloadURLs(f.ctx, batch)
+		if err != nil {
+			return err
+		}
+		for hash, url := range urls {
+			f.urls[hash] = url
+		}
+	}
+	return nil
+}
+
+func (f *remoteBlobFetcher) Fetch(hash string) (io.ReadCloser, error) {
+	url, ok := f.urls[hash]
+	if !ok {
+		return nil, fmt.Errorf("blob is not prepared: %s", hash)
+	}
+
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download blob: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
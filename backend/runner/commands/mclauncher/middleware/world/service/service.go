@@ -5,10 +5,13 @@ package service
 import (
 	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -18,6 +21,10 @@ import (
 
 	"github.com/klauspost/compress/zstd"
 	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/common/entity/web"
+	"github.com/kofuk/premises/backend/common/manifest"
+	"github.com/kofuk/premises/backend/common/mc/leveldat"
 	"github.com/kofuk/premises/backend/runner/api"
 	"github.com/kofuk/premises/backend/runner/env"
 	"github.com/kofuk/premises/backend/runner/util"
@@ -27,22 +34,39 @@ type WorldServiceInterface interface {
 	GetLatestResourceID(ctx context.Context, worldName string) (string, error)
 	DownloadWorld(ctx context.Context, resourceID string, envProvider env.EnvProvider) error
 	UploadWorld(ctx context.Context, worldName string, envProvider env.EnvProvider) (string, error)
+	// UploadAutoBackup uploads the world in baseDir/world as an auto-backup generation.
+	UploadAutoBackup(ctx context.Context, worldName, baseDir string, envProvider env.EnvProvider) (string, error)
 }
 
 type WorldService struct {
-	client     *api.Client
-	httpClient *http.Client
+	client       *api.Client
+	httpClient   *http.Client
+	backupFormat string
 }
 
 var _ WorldServiceInterface = (*WorldService)(nil)
 
-func NewWorldService(endpoint, authKey string, httpClient *http.Client) *WorldService {
-	return &WorldService{
-		client:     api.NewClient(endpoint, authKey, httpClient),
-		httpClient: httpClient,
+type Option func(*WorldService)
+
+// WithBackupFormat sets the format used to upload worlds. The default is runner.BackupFormatArchive.
+func WithBackupFormat(format string) Option {
+	return func(w *WorldService) {
+		w.backupFormat = format
 	}
 }
 
+func NewWorldService(endpoint, authKey string, httpClient *http.Client, opts ...Option) *WorldService {
+	w := &WorldService{
+		client:       api.NewClient(endpoint, authKey, httpClient),
+		httpClient:   httpClient,
+		backupFormat: runner.BackupFormatArchive,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
 func (w *WorldService) GetLatestResourceID(ctx context.Context, worldName string) (string, error) {
 	resp, err := w.client.GetLatestWorldID(ctx, worldName)
 	if err != nil {
@@ -51,7 +75,7 @@ func (w *WorldService) GetLatestResourceID(ctx context.Context, worldName string
 	return resp.WorldID, nil
 }
 
-func (w *WorldService) getExtractionPipeline(resourceID string, envProvider env.EnvProvider) (*ExtractionPipeline, error) {
+func (w *WorldService) getExtractionPipeline(ctx context.Context, resourceID string, envProvider env.EnvProvider) (*ExtractionPipeline, error) {
 	// TODO: Don't use resourceID directly, use a separate struct to indicate file type.
 	tmpDir, err := env.MkdirTemp(envProvider)
 	if err != nil {
@@ -82,12 +106,17 @@ func (w *WorldService) getExtractionPipeline(resourceID string, envProvider env.
 			U: NewTarUnarchiver(),
 			C: c,
 		}, nil
+	case strings.HasSuffix(resourceID, manifest.Extension):
+		return &ExtractionPipeline{
+			U: NewManifestUnarchiver(w.newBlobFetcher(ctx)),
+			C: c,
+		}, nil
 	}
 	return nil, errors.New("unsupported archive type")
 }
 
 func (w *WorldService) DownloadWorld(ctx context.Context, resourceID string, envProvider env.EnvProvider) error {
-	pipeline, err := w.getExtractionPipeline(resourceID, envProvider)
+	pipeline, err := w.getExtractionPipeline(ctx, resourceID, envProvider)
 	if err != nil {
 		return err
 	}
@@ -114,7 +143,8 @@ func (w *WorldService) DownloadWorld(ctx context.Context, resourceID string, env
 
 	reader := util.NewProgressReader(ctx, resp.Body, entity.EventWorldDownload, int(resp.ContentLength))
 
-	if err := pipeline.Run(reader); err != nil {
+	pipeline.Checksum = downloadURLResp.Checksum
+	if err := pipeline.Run(ctx, reader); err != nil {
 		return err
 	}
 
@@ -247,33 +277,84 @@ func writeTar(to io.Writer, baseDir string, dirs ...string) error {
 	return nil
 }
 
-func (w *WorldService) createArchive(envProvider env.EnvProvider) (string, error) {
+// createArchive archives baseDir/world and returns the path to the archive with its SHA-256 checksum.
+func (w *WorldService) createArchive(envProvider env.EnvProvider, baseDir string) (string, string, error) {
 	tmpDir, err := env.MkdirTemp(envProvider)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	outFile, err := os.Create(filepath.Join(tmpDir, "world.tar.zst"))
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer outFile.Close()
 
-	zstWriter, err := zstd.NewWriter(outFile, zstd.WithEncoderConcurrency(runtime.NumCPU()), zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	hash := sha256.New()
+	zstWriter, err := zstd.NewWriter(io.MultiWriter(outFile, hash), zstd.WithEncoderConcurrency(runtime.NumCPU()), zstd.WithEncoderLevel(zstd.SpeedBestCompression))
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	defer zstWriter.Close()
 
-	if err := writeTar(zstWriter, envProvider.GetDataPath("gamedata"), "world"); err != nil {
-		return "", fmt.Errorf("failed to create tar: %w", err)
+	if err := writeTar(zstWriter, baseDir, "world"); err != nil {
+		zstWriter.Close()
+		return "", "", fmt.Errorf("failed to create tar: %w", err)
+	}
+	if err := zstWriter.Close(); err != nil {
+		return "", "", err
 	}
 
-	return outFile.Name(), nil
+	return outFile.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (w *WorldService) put(ctx context.Context, url, contentType string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("upload failed: %s", resp.Status)
+	}
+
+	io.CopyN(io.Discard, resp.Body, 10*1024)
+
+	return nil
+}
+
+// readLevelInfo returns metadata of baseDir/world, or nil if it can't be read.
+func readLevelInfo(ctx context.Context, baseDir string) *web.LevelInfo {
+	levelDat, err := leveldat.ReadFile(filepath.Join(baseDir, "world", "level.dat"))
+	if err != nil {
+		slog.WarnContext(ctx, "Unable to read level.dat", slog.Any("error", err))
+		return nil
+	}
+	return levelDat.Info()
 }
 
 func (w *WorldService) UploadWorld(ctx context.Context, worldName string, envProvider env.EnvProvider) (string, error) {
-	archivePath, err := w.createArchive(envProvider)
+	return w.upload(ctx, worldName, envProvider.GetDataPath("gamedata"), false, envProvider)
+}
+
+func (w *WorldService) UploadAutoBackup(ctx context.Context, worldName, baseDir string, envProvider env.EnvProvider) (string, error) {
+	return w.upload(ctx, worldName, baseDir, true, envProvider)
+}
+
+// upload uploads baseDir/world. Auto-backups don't report upload progress since the server keeps running.
+func (w *WorldService) upload(ctx context.Context, worldName, baseDir string, autoBackup bool, envProvider env.EnvProvider) (string, error) {
+	if w.backupFormat == runner.BackupFormatIncremental {
+		return w.uploadIncremental(ctx, worldName, baseDir, autoBackup)
+	}
+
+	archivePath, checksum, err := w.createArchive(envProvider, baseDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to create archive: %w", err)
 	}
@@ -290,31 +371,30 @@ func (w *WorldService) UploadWorld(ctx context.Context, worldName string, envPro
 		return "", err
 	}
 
-	uploadURLResp, err := w.client.CreateWorldUploadURL(ctx, worldName)
+	uploadURLResp, err := w.client.CreateWorldUploadURL(ctx, worldName, runner.BackupFormatArchive)
 	if err != nil {
 		return "", err
 	}
 
-	reader := util.NewProgressReader(ctx, file, entity.EventWorldUpload, int(fileInfo.Size())).ToSeekable()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURLResp.URL, reader)
-	if err != nil {
-		return "", err
+	var reader io.ReadSeeker = file
+	if !autoBackup {
+		reader = util.NewProgressReader(ctx, file, entity.EventWorldUpload, int(fileInfo.Size())).ToSeekable()
 	}
-	req.ContentLength = fileInfo.Size()
-	req.Header.Set("Content-Type", "application/zstd")
-
-	resp, err := w.httpClient.Do(req)
-	if err != nil {
+	if err := w.put(ctx, uploadURLResp.URL, "application/zstd", reader, fileInfo.Size()); err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("upload failed: %s", resp.Status)
+	req := web.CompleteWorldUploadRequest{
+		WorldID:    uploadURLResp.WorldID,
+		Size:       fileInfo.Size(),
+		AutoBackup: autoBackup,
+		Checksum:   checksum,
+		Level:      readLevelInfo(ctx, baseDir),
+	}
+	if err := w.client.CompleteWorldUpload(ctx, req); err != nil {
+		// The world has been uploaded anyway. Control plane will find it later.
+		slog.ErrorContext(ctx, "Failed to notify completion of upload", slog.Any("error", err))
 	}
-
-	io.CopyN(io.Discard, resp.Body, 10*1024)
 
 	return uploadURLResp.WorldID, nil
 }
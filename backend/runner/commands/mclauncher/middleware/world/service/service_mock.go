@@ -70,6 +70,21 @@ func (mr *MockWorldServiceInterfaceMockRecorder) GetLatestResourceID(ctx, worldN
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestResourceID", reflect.TypeOf((*MockWorldServiceInterface)(nil).GetLatestResourceID), ctx, worldName)
 }
 
+// UploadAutoBackup mocks base method.
+func (m *MockWorldServiceInterface) UploadAutoBackup(ctx context.Context, worldName, baseDir string, envProvider env.EnvProvider) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAutoBackup", ctx, worldName, baseDir, envProvider)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAutoBackup indicates an expected call of UploadAutoBackup.
+func (mr *MockWorldServiceInterfaceMockRecorder) UploadAutoBackup(ctx, worldName, baseDir, envProvider any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAutoBackup", reflect.TypeOf((*MockWorldServiceInterface)(nil).UploadAutoBackup), ctx, worldName, baseDir, envProvider)
+}
+
 // UploadWorld mocks base method.
 func (m *MockWorldServiceInterface) UploadWorld(ctx context.Context, worldName string, envProvider env.EnvProvider) (string, error) {
 	m.ctrl.T.Helper()
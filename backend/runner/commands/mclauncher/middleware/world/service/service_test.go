@@ -1,12 +1,19 @@
 package service_test
 
 import (
+	"crypto/sha256"
 	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/jarcoal/httpmock"
+	"github.com/kofuk/premises/backend/common/entity/runner"
 	"github.com/kofuk/premises/backend/common/entity/web"
 	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/world/service"
 	"github.com/kofuk/premises/backend/runner/env"
@@ -85,6 +92,32 @@ var _ = Describe("WorldService", func() {
 			Expect(filepath.Join(dataDir, "gamedata/world/level.dat")).To(BeARegularFile())
 		})
 
+		DescribeTable("should verify checksum of the world",
+			func(checksum string, expectedErr error) {
+				httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/download-url",
+					httpmock.NewJsonResponderOrPanic(http.StatusCreated, web.SuccessfulResponse[any]{
+						Success: true,
+						Data: web.CreateWorldDownloadURLResponse{
+							URL:      "https://s3.premises.local/download",
+							Checksum: checksum,
+						},
+					}),
+				)
+				httpmock.RegisterResponder(http.MethodGet, "https://s3.premises.local/download",
+					httpmock.NewBytesResponder(http.StatusOK, worldArchive),
+				)
+
+				err := sut.DownloadWorld(GinkgoT().Context(), "latest-world.tar.zst", envProvider)
+				if expectedErr == nil {
+					Expect(err).ShouldNot(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError(expectedErr))
+				}
+			},
+			Entry("matching", fmt.Sprintf("%x", sha256.Sum256(worldArchive)), nil),
+			Entry("mismatching", strings.Repeat("0", 64), service.ErrChecksumMismatch),
+		)
+
 		It("should archive and upload world", func() {
 			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/upload-url",
 				httpmock.NewJsonResponderOrPanic(http.StatusCreated, web.SuccessfulResponse[any]{
@@ -95,8 +128,21 @@ var _ = Describe("WorldService", func() {
 					},
 				}),
 			)
+			var uploaded []byte
 			httpmock.RegisterResponder(http.MethodPut, "https://s3.premises.local/upload",
-				httpmock.NewStringResponder(http.StatusOK, ""),
+				func(req *http.Request) (*http.Response, error) {
+					uploaded, _ = io.ReadAll(req.Body)
+					return httpmock.NewStringResponse(http.StatusOK, ""), nil
+				},
+			)
+			var completeReq web.CompleteWorldUploadRequest
+			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/upload-complete",
+				func(req *http.Request) (*http.Response, error) {
+					json.NewDecoder(req.Body).Decode(&completeReq)
+					return httpmock.NewJsonResponse(http.StatusOK, web.SuccessfulResponse[any]{
+						Success: true,
+					})
+				},
 			)
 			os.WriteFile(filepath.Join(dataDir, "gamedata/world/level.dat"), []byte("level"), 0o644)
 			os.WriteFile(filepath.Join(dataDir, "gamedata/world/foo.txt"), []byte("foo"), 0o644)
@@ -104,6 +150,89 @@ var _ = Describe("WorldService", func() {
 			resourceID, err := sut.UploadWorld(GinkgoT().Context(), "foo", envProvider)
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(resourceID).To(Equal("uploaded-world.tar.zst"))
+			Expect(httpmock.GetCallCountInfo()).To(HaveKeyWithValue("POST https://premises.local/_/world/upload-complete", 1))
+			Expect(completeReq.Checksum).To(Equal(fmt.Sprintf("%x", sha256.Sum256(uploaded))))
+		})
+		It("should upload and restore an incremental backup", func() {
+			sut = service.NewWorldService("https://premises.local", "key", http.DefaultClient, service.WithBackupFormat(runner.BackupFormatIncremental))
+
+			objects := make(map[string][]byte)
+			blobURLs := func(req *http.Request) (*http.Response, error) {
+				var body web.CreateBlobURLsRequest
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+				urls := make(map[string]string)
+				for _, hash := range body.Hashes {
+					urls[hash] = "https://s3.premises.local/blobs/" + hash
+				}
+				return httpmock.NewJsonResponse(http.StatusOK, web.SuccessfulResponse[any]{
+					Success: true,
+					Data:    web.CreateBlobURLsResponse{URLs: urls},
+				})
+			}
+			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/blobs/upload-urls", blobURLs)
+			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/blobs/download-urls", blobURLs)
+			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/upload-url",
+				httpmock.NewJsonResponderOrPanic(http.StatusCreated, web.SuccessfulResponse[any]{
+					Success: true,
+					Data: web.CreateWorldUploadURLResponse{
+						URL:     "https://s3.premises.local/world",
+						WorldID: "uploaded-world.manifest.json",
+					},
+				}),
+			)
+			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/download-url",
+				httpmock.NewJsonResponderOrPanic(http.StatusCreated, web.SuccessfulResponse[any]{
+					Success: true,
+					Data: web.CreateWorldDownloadURLResponse{
+						URL: "https://s3.premises.local/world",
+					},
+				}),
+			)
+			httpmock.RegisterResponder(http.MethodPost, "https://premises.local/_/world/upload-complete",
+				httpmock.NewJsonResponderOrPanic(http.StatusOK, web.SuccessfulResponse[any]{
+					Success: true,
+				}),
+			)
+			httpmock.RegisterRegexpResponder(http.MethodPut, regexp.MustCompile(`^https://s3\.premises\.local/`),
+				func(req *http.Request) (*http.Response, error) {
+					body, err := io.ReadAll(req.Body)
+					if err != nil {
+						return nil, err
+					}
+					objects[req.URL.Path] = body
+					return httpmock.NewStringResponse(http.StatusOK, ""), nil
+				},
+			)
+			httpmock.RegisterRegexpResponder(http.MethodGet, regexp.MustCompile(`^https://s3\.premises\.local/`),
+				func(req *http.Request) (*http.Response, error) {
+					body, ok := objects[req.URL.Path]
+					if !ok {
+						return httpmock.NewStringResponse(http.StatusNotFound, ""), nil
+					}
+					return httpmock.NewBytesResponse(http.StatusOK, body), nil
+				},
+			)
+
+			os.MkdirAll(filepath.Join(dataDir, "gamedata/world/region"), 0o755)
+			os.WriteFile(filepath.Join(dataDir, "gamedata/world/level.dat"), []byte("level"), 0o644)
+			os.WriteFile(filepath.Join(dataDir, "gamedata/world/region/r.0.0.mca"), []byte("region"), 0o644)
+			os.WriteFile(filepath.Join(dataDir, "gamedata/world/region/r.0.1.mca"), []byte("region"), 0o644)
+
+			resourceID, err := sut.UploadWorld(GinkgoT().Context(), "foo", envProvider)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resourceID).To(Equal("uploaded-world.manifest.json"))
+			// Identical files share a single blob.
+			Expect(httpmock.GetCallCountInfo()).To(HaveKeyWithValue("PUT =~^https://s3\\.premises\\.local/", 3))
+
+			os.RemoveAll(filepath.Join(dataDir, "gamedata/world"))
+
+			err = sut.DownloadWorld(GinkgoT().Context(), resourceID, envProvider)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(os.ReadFile(filepath.Join(dataDir, "gamedata/world/level.dat"))).To(Equal([]byte("level")))
+			Expect(os.ReadFile(filepath.Join(dataDir, "gamedata/world/region/r.0.1.mca"))).To(Equal([]byte("region")))
 		})
 	})
 })
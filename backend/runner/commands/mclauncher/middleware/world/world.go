@@ -79,7 +79,9 @@ func (m *WorldMiddleware) Wrap(next core.HandlerFunc) core.HandlerFunc {
 
 		innerError := next(c)
 
-		if innerError == nil || errors.Is(innerError, core.ErrRestart) {
+		// The world is uploaded after crash loops too, as progress since the last backup would be lost otherwise.
+		// It is a new generation, so the previous ones are still available if the world is broken.
+		if innerError == nil || errors.Is(innerError, core.ErrRestart) || errors.Is(innerError, core.ErrCrashLoop) {
 			slog.InfoContext(c.Context(), "Uploading world to remote")
 			worldKey, err := m.worldService.UploadWorld(c.Context(), worldName, c.Env())
 			if err != nil {
@@ -225,6 +225,27 @@ var _ = Describe("WorldMiddleware", func() {
 
 		Expect(filepath.Join(tempDir, "gamedata/world/levdel.dat")).NotTo(BeAnExistingFile())
 	})
+
+	It("should upload world if the server crashed repeatedly", func() {
+		settingsRepository.EXPECT().IsNewWorld().Return(true)
+		gomock.InOrder(
+			stateRepository.EXPECT().RemoveState(gomock.Any(), world.StateKeyWorldKey),
+			stateRepository.EXPECT().SetState(gomock.Any(), world.StateKeyWorldKey, "res-id-1"),
+		)
+		envProvider.EXPECT().GetDataPath("gamedata/world").Return(filepath.Join(tempDir, "gamedata/world"))
+
+		worldService.EXPECT().UploadWorld(gomock.Any(), "foo", gomock.Any()).Return("res-id-1", nil)
+
+		sut := world.NewWorldMiddleware(worldService)
+
+		launcher.Use(&ErrorMiddleware{
+			err: core.ErrCrashLoop,
+		})
+		launcher.Use(sut)
+
+		err := launcher.Start(GinkgoT().Context())
+		Expect(err).To(MatchError(core.ErrCrashLoop))
+	})
 })
 
 func Test(t *testing.T) {
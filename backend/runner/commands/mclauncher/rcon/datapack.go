@@ -0,0 +1,57 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type DatapackListOutput struct {
+	Enabled   []string
+	Available []string
+}
+
+var (
+	// Both sections of the output may be in a single line.
+	datapackAvailableRegexp = regexp.MustCompile(`There are (?:no more data packs available|[0-9]+ data pack\(s\) available:)`)
+	// Packs are listed as "[vanilla (built-in)]" or "[file/foo.zip (world)]".
+	datapackEntryRegexp = regexp.MustCompile(`\[(.+?)(?: \([^()]*\))?\]`)
+)
+
+func parseDatapackEntries(section string) []string {
+	var ids []string
+	for _, match := range datapackEntryRegexp.FindAllStringSubmatch(section, -1) {
+		ids = append(ids, match[1])
+	}
+	return ids
+}
+
+func ParseDatapackListOutput(output string) (*DatapackListOutput, error) {
+	loc := datapackAvailableRegexp.FindStringIndex(output)
+	if loc == nil {
+		return nil, errors.New("invalid /datapack list output")
+	}
+
+	return &DatapackListOutput{
+		Enabled:   parseDatapackEntries(output[:loc[0]]),
+		Available: parseDatapackEntries(output[loc[0]:]),
+	}, nil
+}
+
+func (r *Rcon) DatapackList(ctx context.Context) (*DatapackListOutput, error) {
+	resp, err := r.executor.Exec(ctx, "datapack list")
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDatapackListOutput(strings.TrimSpace(resp))
+}
+
+func (r *Rcon) EnableDatapack(ctx context.Context, id string) error {
+	if _, err := r.executor.Exec(ctx, fmt.Sprintf("datapack enable \"%s\"", id)); err != nil {
+		return fmt.Errorf("failed to enable datapack %s: %w", id, err)
+	}
+	return nil
+}
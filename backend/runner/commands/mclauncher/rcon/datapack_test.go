@@ -0,0 +1,41 @@
+package rcon_test
+
+import (
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Datapack list command", func() {
+	DescribeTable("should parse output",
+		func(input string, expected *rcon.DatapackListOutput) {
+			output, err := rcon.ParseDatapackListOutput(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal(expected))
+		},
+		Entry("enabled and available",
+			"There are 2 data pack(s) enabled: [vanilla (built-in)], [file/foo.zip (world)]There are 1 data pack(s) available: [file/bar baz.zip (world)]",
+			&rcon.DatapackListOutput{
+				Enabled:   []string{"vanilla", "file/foo.zip"},
+				Available: []string{"file/bar baz.zip"},
+			},
+		),
+		Entry("in multiple lines",
+			"There are 1 data pack(s) enabled: [vanilla (built-in)]\nThere are no more data packs available",
+			&rcon.DatapackListOutput{
+				Enabled: []string{"vanilla"},
+			},
+		),
+		Entry("nothing enabled",
+			"There are no data packs enabledThere are 1 data pack(s) available: [file/foo.zip (world)]",
+			&rcon.DatapackListOutput{
+				Available: []string{"file/foo.zip"},
+			},
+		),
+	)
+
+	It("should return an error for unknown output", func() {
+		_, err := rcon.ParseDatapackListOutput("Unknown or incomplete command")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -3,8 +3,14 @@ package rcon
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
+// Execute runs an arbitrary command and returns its output.
+func (r *Rcon) Execute(ctx context.Context, command string) (string, error) {
+	return r.executor.Exec(ctx, strings.TrimPrefix(command, "/"))
+}
+
 func (r *Rcon) SaveAll(ctx context.Context) error {
 	if _, err := r.executor.Exec(ctx, "save-all"); err != nil {
 		return err
@@ -12,6 +18,29 @@ func (r *Rcon) SaveAll(ctx context.Context) error {
 	return nil
 }
 
+// SaveAllFlush saves the world and waits until all chunks are written to disk.
+func (r *Rcon) SaveAllFlush(ctx context.Context) error {
+	if _, err := r.executor.Exec(ctx, "save-all flush"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveOff disables automatic saving so that world files don't change during a backup.
+func (r *Rcon) SaveOff(ctx context.Context) error {
+	if _, err := r.executor.Exec(ctx, "save-off"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Rcon) SaveOn(ctx context.Context) error {
+	if _, err := r.executor.Exec(ctx, "save-on"); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *Rcon) AddToWhiteList(ctx context.Context, player string) error {
 	if _, err := r.executor.Exec(ctx, fmt.Sprintf("whitelist add %s", player)); err != nil {
 		return fmt.Errorf("failed to add %s to whitelist: %w", player, err)
@@ -19,6 +48,13 @@ func (r *Rcon) AddToWhiteList(ctx context.Context, player string) error {
 	return nil
 }
 
+func (r *Rcon) RemoveFromWhiteList(ctx context.Context, player string) error {
+	if _, err := r.executor.Exec(ctx, fmt.Sprintf("whitelist remove %s", player)); err != nil {
+		return fmt.Errorf("failed to remove %s from whitelist: %w", player, err)
+	}
+	return nil
+}
+
 func (r *Rcon) AddToOp(ctx context.Context, player string) error {
 	if _, err := r.executor.Exec(ctx, fmt.Sprintf("op %s", player)); err != nil {
 		return fmt.Errorf("failed to add %s to op: %w", player, err)
@@ -26,6 +62,20 @@ func (r *Rcon) AddToOp(ctx context.Context, player string) error {
 	return nil
 }
 
+func (r *Rcon) RemoveFromOp(ctx context.Context, player string) error {
+	if _, err := r.executor.Exec(ctx, fmt.Sprintf("deop %s", player)); err != nil {
+		return fmt.Errorf("failed to remove %s from op: %w", player, err)
+	}
+	return nil
+}
+
+func (r *Rcon) SetDifficulty(ctx context.Context, difficulty string) error {
+	if _, err := r.executor.Exec(ctx, fmt.Sprintf("difficulty %s", difficulty)); err != nil {
+		return fmt.Errorf("failed to set difficulty: %w", err)
+	}
+	return nil
+}
+
 func (r *Rcon) Say(ctx context.Context, message string) error {
 	if _, err := r.executor.Exec(ctx, fmt.Sprintf("tellraw @a \"%s\"", message)); err != nil {
 		return err
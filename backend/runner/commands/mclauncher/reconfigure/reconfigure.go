@@ -0,0 +1,197 @@
+package reconfigure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/core"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/middleware/serverproperties"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+)
+
+var playerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]{1,16}$`)
+
+var validDifficulties = []string{"peaceful", "easy", "normal", "hard"}
+
+type ReconfigureService struct {
+	rcon     *rcon.Rcon
+	settings core.SettingsRepository
+	launcher *core.LauncherCore
+
+	m                       sync.Mutex
+	operators               []string
+	whitelist               []string
+	rewriteServerProperties bool
+}
+
+func NewReconfigureService(rconClient *rcon.Rcon, settings core.SettingsRepository, operators, whitelist []string) *ReconfigureService {
+	return &ReconfigureService{
+		rcon:      rconClient,
+		settings:  settings,
+		operators: slices.Clone(operators),
+		// Operators are always whitelisted on launch.
+		whitelist: mergePlayers(whitelist, operators),
+	}
+}
+
+func (s *ReconfigureService) BeforeLaunch(c core.LauncherContext) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !s.rewriteServerProperties {
+		return nil
+	}
+	s.rewriteServerProperties = false
+
+	return serverproperties.WriteServerPropertiesFile(c)
+}
+
+func (s *ReconfigureService) Register(launcher *core.LauncherCore) {
+	s.launcher = launcher
+	launcher.AddBeforeLaunchListener(s.BeforeLaunch)
+}
+
+func mergePlayers(a, b []string) []string {
+	result := slices.Clone(a)
+	for _, player := range b {
+		if !slices.Contains(result, player) {
+			result = append(result, player)
+		}
+	}
+	return result
+}
+
+// diffPlayers returns players which should be added to and removed from current to get desired.
+func diffPlayers(current, desired []string) ([]string, []string) {
+	var added, removed []string
+	for _, player := range desired {
+		if !slices.Contains(current, player) {
+			added = append(added, player)
+		}
+	}
+	for _, player := range current {
+		if !slices.Contains(desired, player) {
+			removed = append(removed, player)
+		}
+	}
+	return added, removed
+}
+
+func validate(config *runner.GameConfig) error {
+	for _, player := range slices.Concat(config.Operators, config.Whitelist) {
+		if !playerNameRegexp.MatchString(player) {
+			return fmt.Errorf("invalid player name: %s", player)
+		}
+	}
+	if config.World.Difficulty != "" && !slices.Contains(validDifficulties, config.World.Difficulty) {
+		return fmt.Errorf("unknown difficulty: %s", config.World.Difficulty)
+	}
+	return nil
+}
+
+func (s *ReconfigureService) syncPlayers(ctx context.Context, current, desired []string, add, remove func(ctx context.Context, player string) error) ([]string, error) {
+	added, removed := diffPlayers(current, desired)
+
+	result := slices.Clone(current)
+	var errs []error
+	for _, player := range added {
+		if err := add(ctx, player); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, player)
+	}
+	for _, player := range removed {
+		if err := remove(ctx, player); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result = slices.DeleteFunc(result, func(p string) bool { return p == player })
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// Apply applies changes in config to the running server.
+// Empty fields in config are treated as unchanged. Operators and whitelist are replaced as a whole if they are non-nil.
+// Whitelist, operators and difficulty are applied live using rcon, but other changes need server.properties to be
+// rewritten, so the server is restarted in that case.
+func (s *ReconfigureService) Apply(ctx context.Context, config *runner.GameConfig) error {
+	if err := validate(config); err != nil {
+		return err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var errs []error
+
+	if config.Whitelist != nil || config.Operators != nil {
+		desiredWhitelist := s.whitelist
+		if config.Whitelist != nil {
+			desiredWhitelist = config.Whitelist
+		}
+		if config.Operators != nil {
+			desiredWhitelist = mergePlayers(desiredWhitelist, config.Operators)
+		} else {
+			desiredWhitelist = mergePlayers(desiredWhitelist, s.operators)
+		}
+
+		whitelist, err := s.syncPlayers(ctx, s.whitelist, desiredWhitelist, s.rcon.AddToWhiteList, s.rcon.RemoveFromWhiteList)
+		s.whitelist = whitelist
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if config.Operators != nil {
+		operators, err := s.syncPlayers(ctx, s.operators, config.Operators, s.rcon.AddToOp, s.rcon.RemoveFromOp)
+		s.operators = operators
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if config.World.Difficulty != "" && config.World.Difficulty != s.settings.GetDifficulty() {
+		if err := s.rcon.SetDifficulty(ctx, config.World.Difficulty); err != nil {
+			errs = append(errs, err)
+		} else {
+			// Keep server.properties consistent with the actual difficulty when the server is restarted.
+			s.settings.SetDifficulty(config.World.Difficulty)
+		}
+	}
+
+	needsRestart := false
+
+	// There's no command to change MOTD at runtime.
+	if config.Motd != "" && config.Motd != s.settings.GetMotd() {
+		s.settings.SetMotd(config.Motd)
+		needsRestart = true
+	}
+
+	if config.Server.ServerPropOverride != nil && !maps.Equal(config.Server.ServerPropOverride, s.settings.ServerPropertiesOverrides()) {
+		s.settings.SetServerPropertiesOverrides(config.Server.ServerPropOverride)
+		needsRestart = true
+	}
+
+	if needsRestart {
+		slog.InfoContext(ctx, "Restarting server to apply new server.properties")
+
+		s.rewriteServerProperties = true
+		s.launcher.RequestRestart()
+
+		if err := s.rcon.Stop(ctx); err != nil {
+			s.launcher.CancelRestart()
+			errs = append(errs, fmt.Errorf("failed to restart server: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
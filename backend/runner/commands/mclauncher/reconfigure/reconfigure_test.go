@@ -0,0 +1,27 @@
+package reconfigure
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reconfigure", func() {
+	DescribeTable("diffPlayers", func(current, desired, expectedAdded, expectedRemoved []string) {
+		added, removed := diffPlayers(current, desired)
+		Expect(added).To(Equal(expectedAdded))
+		Expect(removed).To(Equal(expectedRemoved))
+	},
+		Entry("no change", []string{"a", "b"}, []string{"b", "a"}, nil, nil),
+		Entry("add", []string{"a"}, []string{"a", "b"}, []string{"b"}, nil),
+		Entry("remove", []string{"a", "b"}, []string{"a"}, nil, []string{"b"}),
+		Entry("replace", []string{"a"}, []string{"b"}, []string{"b"}, []string{"a"}),
+		Entry("clear", []string{"a"}, []string{}, nil, []string{"a"}),
+	)
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reconfigure Suite")
+}
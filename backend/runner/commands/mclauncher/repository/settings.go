@@ -14,7 +14,10 @@ import (
 
 type ConfigJSONSettingsRepository struct {
 	serverPath                string
+	launchArgs                []string
 	minecraftVersion          string
+	serverFlavor              string
+	modSet                    string
 	autoVersionEnabled        bool
 	worldName                 string
 	worldResourceID           string
@@ -26,6 +29,10 @@ type ConfigJSONSettingsRepository struct {
 	serverPropertiesOverrides map[string]string
 	otlpEndpoint              string
 	metricExportIntervalMs    int
+	maxCrashLoops             int
+	jvmProfile                string
+	jvmArgs                   []string
+	commandLine               []string
 }
 
 var _ core.SettingsRepository = (*ConfigJSONSettingsRepository)(nil)
@@ -38,6 +45,8 @@ func NewConfigJSONSettingsRepository(config *runner.Config) *ConfigJSONSettingsR
 
 func (r *ConfigJSONSettingsRepository) initialize(config *runner.Config) {
 	r.minecraftVersion = config.GameConfig.Server.Version
+	r.serverFlavor = config.GameConfig.Server.Flavor
+	r.modSet = config.GameConfig.Server.ModSet
 	r.autoVersionEnabled = config.GameConfig.Server.PreferDetected
 	r.worldName = config.GameConfig.World.Name
 	r.worldResourceID = config.GameConfig.World.GenerationId
@@ -50,6 +59,9 @@ func (r *ConfigJSONSettingsRepository) initialize(config *runner.Config) {
 	maps.Copy(r.serverPropertiesOverrides, config.GameConfig.Server.ServerPropOverride)
 	r.otlpEndpoint = config.Observability.OtlpEndpoint
 	r.metricExportIntervalMs = config.Observability.MetricExportIntervalMs
+	r.maxCrashLoops = config.GameConfig.Server.MaxCrashLoops
+	r.jvmProfile = config.GameConfig.Server.JVMProfile
+	r.jvmArgs = config.GameConfig.Server.JVMArgs
 }
 
 func getAllowedSizeMiB(ctx context.Context) int {
@@ -77,6 +89,14 @@ func (r *ConfigJSONSettingsRepository) SetServerPath(path string) {
 	r.serverPath = path
 }
 
+func (r *ConfigJSONSettingsRepository) GetLaunchArgs() []string {
+	return r.launchArgs
+}
+
+func (r *ConfigJSONSettingsRepository) SetLaunchArgs(args []string) {
+	r.launchArgs = args
+}
+
 func (r *ConfigJSONSettingsRepository) GetMinecraftVersion() string {
 	return r.minecraftVersion
 }
@@ -85,6 +105,14 @@ func (r *ConfigJSONSettingsRepository) SetMinecraftVersion(version string) {
 	r.minecraftVersion = version
 }
 
+func (r *ConfigJSONSettingsRepository) GetServerFlavor() string {
+	return r.serverFlavor
+}
+
+func (r *ConfigJSONSettingsRepository) GetModSet() string {
+	return r.modSet
+}
+
 func (r *ConfigJSONSettingsRepository) AutoVersionEnabled() bool {
 	return r.autoVersionEnabled
 }
@@ -109,10 +137,18 @@ func (r *ConfigJSONSettingsRepository) GetMotd() string {
 	return r.motd
 }
 
+func (r *ConfigJSONSettingsRepository) SetMotd(motd string) {
+	r.motd = motd
+}
+
 func (r *ConfigJSONSettingsRepository) GetDifficulty() string {
 	return r.difficulty
 }
 
+func (r *ConfigJSONSettingsRepository) SetDifficulty(difficulty string) {
+	r.difficulty = difficulty
+}
+
 func (r *ConfigJSONSettingsRepository) GetLevelType() string {
 	return r.levelType
 }
@@ -125,6 +161,11 @@ func (r *ConfigJSONSettingsRepository) ServerPropertiesOverrides() map[string]st
 	return r.serverPropertiesOverrides
 }
 
+func (r *ConfigJSONSettingsRepository) SetServerPropertiesOverrides(overrides map[string]string) {
+	r.serverPropertiesOverrides = make(map[string]string)
+	maps.Copy(r.serverPropertiesOverrides, overrides)
+}
+
 func (r *ConfigJSONSettingsRepository) GetOtlpEndpoint() string {
 	return r.otlpEndpoint
 }
@@ -132,3 +173,23 @@ func (r *ConfigJSONSettingsRepository) GetOtlpEndpoint() string {
 func (r *ConfigJSONSettingsRepository) GetMetricExportIntervalMs() int {
 	return r.metricExportIntervalMs
 }
+
+func (r *ConfigJSONSettingsRepository) GetMaxCrashLoops() int {
+	return r.maxCrashLoops
+}
+
+func (r *ConfigJSONSettingsRepository) GetJVMProfile() string {
+	return r.jvmProfile
+}
+
+func (r *ConfigJSONSettingsRepository) GetJVMArgs() []string {
+	return r.jvmArgs
+}
+
+func (r *ConfigJSONSettingsRepository) GetCommandLine() []string {
+	return r.commandLine
+}
+
+func (r *ConfigJSONSettingsRepository) SetCommandLine(commandLine []string) {
+	r.commandLine = commandLine
+}
@@ -0,0 +1,164 @@
+package serverlog
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/runner/exterior"
+)
+
+const (
+	defaultCapacity  = 1000
+	defaultBatchSize = 100
+	flushInterval    = 2 * time.Second
+	// Lines longer than this are truncated to keep events reasonably small.
+	maxLineLength = 4096
+)
+
+// e.g. "[12:34:56] [Server thread/INFO]: Done (1.234s)!"
+var levelRegexp = regexp.MustCompile(`^\[[^\]]*\] \[[^\]]*/([A-Z]+)\]`)
+
+func parseLevel(line string) string {
+	match := levelRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	switch match[1] {
+	case "WARNING":
+		return "WARN"
+	case "SEVERE":
+		return "ERROR"
+	default:
+		return match[1]
+	}
+}
+
+// LogStreamer receives output of Minecraft server and ships it to the control plane in batches.
+// Lines are kept in a bounded ring buffer so that old lines are dropped when they can't be sent fast enough.
+type LogStreamer struct {
+	m         sync.Mutex
+	partial   []byte
+	lastLevel string
+	buffer    []runner.LogLine
+	start     int
+	size      int
+	batchSize int
+	send      func(ctx context.Context, lines []runner.LogLine)
+}
+
+func NewLogStreamer() *LogStreamer {
+	return &LogStreamer{
+		buffer:    make([]runner.LogLine, defaultCapacity),
+		batchSize: defaultBatchSize,
+		send: func(ctx context.Context, lines []runner.LogLine) {
+			exterior.SendEvent(ctx, runner.Event{
+				Type: runner.EventLogs,
+				Logs: &runner.LogsExtra{
+					Lines: lines,
+				},
+			})
+		},
+	}
+}
+
+func (s *LogStreamer) push(line string) {
+	if len(line) > maxLineLength {
+		line = line[:maxLineLength]
+	}
+
+	level := parseLevel(line)
+	if level == "" {
+		// Lines without header (e.g. stack traces) belong to the previous line.
+		level = s.lastLevel
+	} else {
+		s.lastLevel = level
+	}
+
+	entry := runner.LogLine{
+		Time:    time.Now().UnixMilli(),
+		Level:   level,
+		Message: line,
+	}
+
+	if s.size == len(s.buffer) {
+		// Drop the oldest line.
+		s.buffer[s.start] = entry
+		s.start = (s.start + 1) % len(s.buffer)
+		return
+	}
+	s.buffer[(s.start+s.size)%len(s.buffer)] = entry
+	s.size++
+}
+
+func (s *LogStreamer) Write(p []byte) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	data := append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		s.push(strings.TrimRight(string(data[:i]), "\r"))
+		data = data[i+1:]
+	}
+	if len(data) > maxLineLength {
+		s.push(string(data))
+		data = nil
+	}
+	s.partial = bytes.Clone(data)
+
+	return len(p), nil
+}
+
+// take removes at most batchSize lines from the buffer.
+func (s *LogStreamer) take() []runner.LogLine {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	n := min(s.size, s.batchSize)
+	if n == 0 {
+		return nil
+	}
+
+	lines := make([]runner.LogLine, 0, n)
+	for i := range n {
+		lines = append(lines, s.buffer[(s.start+i)%len(s.buffer)])
+	}
+	s.start = (s.start + n) % len(s.buffer)
+	s.size -= n
+
+	return lines
+}
+
+// Flush sends all buffered lines.
+func (s *LogStreamer) Flush(ctx context.Context) {
+	for {
+		lines := s.take()
+		if len(lines) == 0 {
+			return
+		}
+		s.send(ctx, lines)
+	}
+}
+
+// Run sends buffered lines periodically until ctx is canceled.
+func (s *LogStreamer) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Flush(ctx)
+		}
+	}
+}
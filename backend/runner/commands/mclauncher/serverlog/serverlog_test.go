@@ -0,0 +1,70 @@
+package serverlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func messages(lines []runner.LogLine) []string {
+	var result []string
+	for _, l := range lines {
+		result = append(result, l.Message)
+	}
+	return result
+}
+
+var _ = Describe("LogStreamer", func() {
+	DescribeTable("parseLevel", func(line, level string) {
+		Expect(parseLevel(line)).To(Equal(level))
+	},
+		Entry("info", "[12:34:56] [Server thread/INFO]: Done (1.234s)!", "INFO"),
+		Entry("warn", "[12:34:56] [Server thread/WARN]: Can't keep up!", "WARN"),
+		Entry("legacy severe", "[12:34:56] [Server thread/SEVERE]: Oops", "ERROR"),
+		Entry("no header", "\tat java.base/java.lang.Thread.run(Thread.java:1583)", ""),
+	)
+
+	It("should split output into lines", func() {
+		s := NewLogStreamer()
+		s.Write([]byte("[00:00:00] [main/INFO]: foo\n[00:00:00] [main/ERR"))
+		s.Write([]byte("OR]: bar\n\tat baz\npartial"))
+
+		lines := s.take()
+		Expect(messages(lines)).To(Equal([]string{
+			"[00:00:00] [main/INFO]: foo",
+			"[00:00:00] [main/ERROR]: bar",
+			"\tat baz",
+		}))
+		Expect(lines[2].Level).To(Equal("ERROR"))
+	})
+
+	It("should drop old lines when the buffer is full", func() {
+		s := NewLogStreamer()
+		s.buffer = make([]runner.LogLine, 2)
+		s.Write([]byte("a\nb\nc\n"))
+
+		Expect(messages(s.take())).To(Equal([]string{"b", "c"}))
+		Expect(s.take()).To(BeNil())
+	})
+
+	It("should send lines in batches", func() {
+		s := NewLogStreamer()
+		s.batchSize = 2
+		var batches [][]string
+		s.send = func(ctx context.Context, lines []runner.LogLine) {
+			batches = append(batches, messages(lines))
+		}
+		s.Write([]byte("a\nb\nc\n"))
+		s.Flush(context.Background())
+
+		Expect(batches).To(Equal([][]string{{"a", "b"}, {"c"}}))
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ServerLog Suite")
+}
@@ -0,0 +1,196 @@
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kofuk/premises/backend/common/entity"
+	"github.com/kofuk/premises/backend/common/entity/runner"
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	"github.com/kofuk/premises/backend/runner/exterior"
+)
+
+// milestones are the remaining times at which players are notified of the stop, in addition to when it is scheduled.
+var milestones = []time.Duration{5 * time.Minute, time.Minute, 10 * time.Second}
+
+type Reason int
+
+const (
+	// ReasonRequested is a stop requested by a user or a schedule.
+	ReasonRequested Reason = iota
+	// ReasonInactive is a stop because no players were online for a while. It is cancelled when a player joins.
+	ReasonInactive
+)
+
+type countdown struct {
+	reason Reason
+	cancel chan struct{}
+}
+
+type ShutdownService struct {
+	rcon  *rcon.Rcon
+	after func(d time.Duration) <-chan time.Time
+
+	m       sync.Mutex
+	pending *countdown
+}
+
+func NewShutdownService(rconClient *rcon.Rcon) *ShutdownService {
+	return &ShutdownService{
+		rcon:  rconClient,
+		after: time.After,
+	}
+}
+
+func formatRemaining(d time.Duration) string {
+	if d >= time.Minute && d%time.Minute == 0 {
+		if d == time.Minute {
+			return "1 minute"
+		}
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	}
+	if d == time.Second {
+		return "1 second"
+	}
+	return fmt.Sprintf("%d seconds", d/time.Second)
+}
+
+func (s *ShutdownService) announce(ctx context.Context, message string) {
+	if err := s.rcon.Say(ctx, "[Premises] "+message); err != nil {
+		slog.WarnContext(ctx, "Unable to notify players of the stop", slog.Any("error", err))
+	}
+}
+
+func dispatchInfo(ctx context.Context, infoCode entity.InfoCode, actor int) {
+	exterior.DispatchEvent(ctx, runner.Event{
+		Type: runner.EventInfo,
+		Info: &runner.InfoExtra{
+			InfoCode: infoCode,
+			Actor:    actor,
+			IsError:  false,
+		},
+	})
+}
+
+func (s *ShutdownService) stop(ctx context.Context) error {
+	if err := s.rcon.SaveAll(ctx); err != nil {
+		// The server saves the world on stop anyway.
+		slog.WarnContext(ctx, "Failed to run save-all", slog.Any("error", err))
+	}
+
+	if err := s.rcon.Stop(ctx); err != nil {
+		return err
+	}
+
+	exterior.DispatchEvent(ctx, runner.Event{
+		Type: runner.EventStatus,
+		Status: &runner.StatusExtra{
+			EventCode: entity.EventStopping,
+		},
+	})
+
+	return nil
+}
+
+// Schedule stops the server after delay, broadcasting the remaining time in game.
+// A stop which is already scheduled is replaced. If delay is zero, the server is stopped immediately.
+func (s *ShutdownService) Schedule(ctx context.Context, delay time.Duration, reason Reason, actor int) error {
+	s.m.Lock()
+	if s.pending != nil {
+		close(s.pending.cancel)
+		s.pending = nil
+	}
+	if delay <= 0 {
+		s.m.Unlock()
+		return s.stop(ctx)
+	}
+	cd := &countdown{
+		reason: reason,
+		cancel: make(chan struct{}),
+	}
+	s.pending = cd
+	s.m.Unlock()
+
+	slog.InfoContext(ctx, "Stop scheduled", slog.Duration("delay", delay))
+	dispatchInfo(ctx, entity.InfoStopScheduled, actor)
+
+	go s.run(context.WithoutCancel(ctx), cd, delay)
+
+	return nil
+}
+
+func (s *ShutdownService) run(ctx context.Context, cd *countdown, delay time.Duration) {
+	remaining := delay
+	s.announce(ctx, "The server will stop in "+formatRemaining(remaining))
+
+	for _, milestone := range milestones {
+		if milestone >= remaining {
+			continue
+		}
+		select {
+		case <-cd.cancel:
+			return
+		case <-s.after(remaining - milestone):
+		}
+		remaining = milestone
+		s.announce(ctx, "The server will stop in "+formatRemaining(remaining))
+	}
+
+	select {
+	case <-cd.cancel:
+		return
+	case <-s.after(remaining):
+	}
+
+	s.m.Lock()
+	if s.pending != cd {
+		// Cancelled at the last moment.
+		s.m.Unlock()
+		return
+	}
+	s.pending = nil
+	s.m.Unlock()
+
+	s.announce(ctx, "Stopping the server")
+	if err := s.stop(ctx); err != nil {
+		slog.ErrorContext(ctx, "Failed to stop the server", slog.Any("error", err))
+	}
+}
+
+func (s *ShutdownService) cancel(ctx context.Context, actor int, onlyInactive bool) bool {
+	s.m.Lock()
+	if s.pending == nil || (onlyInactive && s.pending.reason != ReasonInactive) {
+		s.m.Unlock()
+		return false
+	}
+	close(s.pending.cancel)
+	s.pending = nil
+	s.m.Unlock()
+
+	slog.InfoContext(ctx, "Stop cancelled")
+	s.announce(ctx, "The stop of the server was cancelled")
+	dispatchInfo(ctx, entity.InfoStopCancelled, actor)
+
+	return true
+}
+
+// Cancel cancels the scheduled stop. It returns false if no stop is scheduled.
+func (s *ShutdownService) Cancel(ctx context.Context, actor int) bool {
+	return s.cancel(ctx, actor, false)
+}
+
+// CancelInactivityStop cancels the scheduled stop only if it was scheduled because of inactivity.
+func (s *ShutdownService) CancelInactivityStop(ctx context.Context) bool {
+	return s.cancel(ctx, 0, true)
+}
+
+// IsPending reports whether a stop is scheduled.
+func (s *ShutdownService) IsPending() bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.pending != nil
+}
@@ -0,0 +1,146 @@
+package shutdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kofuk/premises/backend/runner/commands/mclauncher/rcon"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("ShutdownService", func() {
+	var (
+		ctrl     *gomock.Controller
+		executor *rcon.MockRconExecutorInterface
+		service  *ShutdownService
+		timers   chan chan time.Time
+		waits    chan time.Duration
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		executor = rcon.NewMockRconExecutorInterface(ctrl)
+		service = NewShutdownService(rcon.NewRcon(executor))
+
+		timers = make(chan chan time.Time, 10)
+		waits = make(chan time.Duration, 10)
+		service.after = func(d time.Duration) <-chan time.Time {
+			ch := make(chan time.Time, 1)
+			waits <- d
+			timers <- ch
+			return ch
+		}
+	})
+
+	// fire lets the countdown proceed to the next step, and returns how long it waited.
+	fire := func() time.Duration {
+		var d time.Duration
+		Eventually(waits).Should(Receive(&d))
+		var timer chan time.Time
+		Eventually(timers).Should(Receive(&timer))
+		timer <- time.Now()
+		return d
+	}
+
+	It("should stop immediately without delay", func() {
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), "save-all").Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "stop").Return("", nil),
+		)
+
+		Expect(service.Schedule(GinkgoT().Context(), 0, ReasonRequested, 1)).To(Succeed())
+		Expect(service.IsPending()).To(BeFalse())
+	})
+
+	It("should count down to the stop", func() {
+		done := make(chan struct{})
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 6 minutes"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 5 minutes"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 1 minute"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 10 seconds"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] Stopping the server"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "save-all").Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "stop").DoAndReturn(func(any, string) (string, error) {
+				close(done)
+				return "", nil
+			}),
+		)
+
+		Expect(service.Schedule(GinkgoT().Context(), 6*time.Minute, ReasonRequested, 1)).To(Succeed())
+		Expect(service.IsPending()).To(BeTrue())
+
+		Expect(fire()).To(Equal(time.Minute))
+		Expect(fire()).To(Equal(4 * time.Minute))
+		Expect(fire()).To(Equal(50 * time.Second))
+		Expect(fire()).To(Equal(10 * time.Second))
+
+		Eventually(done).Should(BeClosed())
+		Expect(service.IsPending()).To(BeFalse())
+	})
+
+	It("should not announce milestones longer than the delay", func() {
+		done := make(chan struct{})
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 30 seconds"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 10 seconds"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] Stopping the server"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "save-all").Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "stop").DoAndReturn(func(any, string) (string, error) {
+				close(done)
+				return "", nil
+			}),
+		)
+
+		Expect(service.Schedule(GinkgoT().Context(), 30*time.Second, ReasonRequested, 1)).To(Succeed())
+
+		Expect(fire()).To(Equal(20 * time.Second))
+		Expect(fire()).To(Equal(10 * time.Second))
+
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should cancel the countdown", func() {
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 1 minute"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The stop of the server was cancelled"`).Return("", nil),
+		)
+
+		Expect(service.Schedule(GinkgoT().Context(), time.Minute, ReasonRequested, 1)).To(Succeed())
+		Eventually(waits).Should(Receive())
+
+		Expect(service.CancelInactivityStop(GinkgoT().Context())).To(BeFalse())
+		Expect(service.IsPending()).To(BeTrue())
+
+		Expect(service.Cancel(GinkgoT().Context(), 1)).To(BeTrue())
+		Expect(service.IsPending()).To(BeFalse())
+		Expect(service.Cancel(GinkgoT().Context(), 1)).To(BeFalse())
+
+		// The countdown must not stop the server after it was cancelled.
+		var timer chan time.Time
+		Eventually(timers).Should(Receive(&timer))
+		timer <- time.Now()
+		Consistently(waits, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should replace the pending stop", func() {
+		gomock.InOrder(
+			executor.EXPECT().Exec(gomock.Any(), `tellraw @a "[Premises] The server will stop in 1 minute"`).Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "save-all").Return("", nil),
+			executor.EXPECT().Exec(gomock.Any(), "stop").Return("", nil),
+		)
+
+		Expect(service.Schedule(GinkgoT().Context(), time.Minute, ReasonInactive, 0)).To(Succeed())
+		Eventually(waits).Should(Receive())
+
+		Expect(service.Schedule(GinkgoT().Context(), 0, ReasonRequested, 1)).To(Succeed())
+		Expect(service.IsPending()).To(BeFalse())
+	})
+})
+
+func Test(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Shutdown Suite")
+}
@@ -77,6 +77,18 @@ func Run(ctx context.Context, config *runner.Config, args []string) int {
 			Path: info.Path,
 		}, nil
 	})
+	rpc.DefaultServer.RegisterMethod("snapshot/backup", func(ctx context.Context, req *rpc.AbstractRequest) (any, error) {
+		// There is only one backup snapshot, which is replaced every time.
+		info, err := takeFsSnapshot(ctx, "backup")
+		if err != nil {
+			return nil, err
+		}
+
+		return types.SnapshotHelperOutput{
+			ID:   info.ID,
+			Path: info.Path,
+		}, nil
+	})
 	rpc.DefaultServer.RegisterMethod("snapshot/stat", func(ctx context.Context, req *rpc.AbstractRequest) (any, error) {
 		var ss types.SnapshotHelperInput
 		if err := req.Bind(&ss); err != nil {
@@ -8,7 +8,6 @@ require (
 	github.com/jarcoal/httpmock v1.4.1
 	github.com/klauspost/compress v1.18.5
 	github.com/kofuk/go-queryalternatives v0.2.1
-	github.com/kofuk/go-structnbt v0.0.2
 	github.com/kofuk/premises/backend/common v0.0.0-00010101000000-000000000000
 	github.com/onsi/ginkgo/v2 v2.28.1
 	github.com/onsi/gomega v1.39.1
@@ -41,3 +41,26 @@ type RegisterMeterTargetInput struct {
 type UnregisterMeterTargetInput struct {
 	Pid int `json:"pid"`
 }
+
+type ConsoleInput struct {
+	Command string `json:"command"`
+}
+
+type ConsoleOutput struct {
+	Response string `json:"response"`
+}
+
+type ReconfigureInput struct {
+	Config runner.GameConfig `json:"config"`
+	Actor  int               `json:"actor"`
+}
+
+type StopInput struct {
+	// Delay is the number of seconds to count down before stopping the server.
+	Delay int `json:"delay"`
+	Actor int `json:"actor"`
+}
+
+type CancelStopInput struct {
+	Actor int `json:"actor"`
+}
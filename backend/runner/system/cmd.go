@@ -4,6 +4,7 @@ package system
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -24,9 +25,35 @@ var logNum uint64
 
 type CommandHandle struct {
 	Pid int
+	// cmd is set if the command was started by SimpleExecutor.
+	cmd *exec.Cmd
+	// log is closed after the command exited, as the output may still be copied into it until then.
+	log   io.Closer
+	state *os.ProcessState
 }
 
+// ProcessState returns the exit status of the command. It is available after Wait returns.
+func (h *CommandHandle) ProcessState() *os.ProcessState {
+	return h.state
+}
+
+// Wait waits for the command to exit. Unsuccessful exit status is not an error; see ProcessState for it.
 func (h *CommandHandle) Wait() error {
+	if h.cmd != nil {
+		// This also waits for the output to be copied to the writers.
+		err := h.cmd.Wait()
+		if h.log != nil {
+			h.log.Close()
+		}
+
+		h.state = h.cmd.ProcessState
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return err
+	}
+
 	if h.Pid == 0 {
 		return nil
 	}
@@ -35,7 +62,7 @@ func (h *CommandHandle) Wait() error {
 	if err != nil {
 		return err
 	}
-	_, err = proc.Wait()
+	h.state, err = proc.Wait()
 	return err
 }
 
@@ -44,13 +71,19 @@ type CommandExecutor interface {
 	Start(ctx context.Context, path string, args []string, options ...CmdOption) (*CommandHandle, error)
 }
 
-type SimpleExecutor struct{}
+type SimpleExecutor struct {
+	// logDir is where output of commands is written. Empty means the temporary directory of the runner.
+	logDir string
+}
 
 var DefaultExecutor CommandExecutor = new(SimpleExecutor)
 
-func createLog(ctx context.Context) (io.Writer, string, error) {
+func createLog(ctx context.Context, logDir string) (io.Writer, string, error) {
+	if logDir == "" {
+		logDir = env.GetTempDir()
+	}
 	for {
-		logPath := filepath.Join(env.GetTempDir(), fmt.Sprintf("command-%d.log", atomic.AddUint64(&logNum, 1)-1))
+		logPath := filepath.Join(logDir, fmt.Sprintf("command-%d.log", atomic.AddUint64(&logNum, 1)-1))
 		log, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
 		if err != nil {
 			if os.IsExist(err) {
@@ -83,6 +116,22 @@ func WithOutput(w io.Writer) CmdOption {
 	}
 }
 
+// WithOutputTee copies stdout and stderr of the command to w, in addition to the default destination.
+func WithOutputTee(w io.Writer) CmdOption {
+	return func(cmd *exec.Cmd) {
+		if cmd.Stdout == nil {
+			cmd.Stdout = w
+		} else {
+			cmd.Stdout = io.MultiWriter(cmd.Stdout, w)
+		}
+		if cmd.Stderr == nil {
+			cmd.Stderr = w
+		} else {
+			cmd.Stderr = io.MultiWriter(cmd.Stderr, w)
+		}
+	}
+}
+
 func (e *SimpleExecutor) Run(ctx context.Context, path string, args []string, options ...CmdOption) error {
 	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(ScopeName)
 	ctx, span := tracer.Start(ctx, fmt.Sprintf("EXEC %s", path))
@@ -105,13 +154,11 @@ func (e *SimpleExecutor) Run(ctx context.Context, path string, args []string, op
 }
 
 func (e *SimpleExecutor) Start(ctx context.Context, path string, args []string, options ...CmdOption) (*CommandHandle, error) {
-	log, logPath, err := createLog(ctx)
+	log, logPath, err := createLog(ctx, e.logDir)
 	if err != nil {
 		return nil, err
 	}
-	if closer, ok := log.(io.Closer); ok {
-		defer closer.Close()
-	}
+	closer, _ := log.(io.Closer)
 
 	slog.InfoContext(ctx, "Execute system command", slog.String("command", path), slog.Any("args", args), slog.String("command_output", logPath))
 
@@ -124,11 +171,14 @@ func (e *SimpleExecutor) Start(ctx context.Context, path string, args []string,
 	}
 
 	if err := cmd.Start(); err != nil {
+		if closer != nil {
+			closer.Close()
+		}
 		slog.ErrorContext(ctx, "Command failed", slog.Any("error", err))
 		return nil, err
 	}
 
-	return &CommandHandle{Pid: cmd.Process.Pid}, nil
+	return &CommandHandle{Pid: cmd.Process.Pid, cmd: cmd, log: closer}, nil
 }
 
 func RunWithOutput(ctx context.Context, executor CommandExecutor, path string, args []string, options ...CmdOption) (string, error) {
@@ -0,0 +1,66 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SimpleExecutor", func() {
+	var (
+		logDir   string
+		executor *SimpleExecutor
+	)
+
+	BeforeEach(func() {
+		logDir = GinkgoT().TempDir()
+		executor = &SimpleExecutor{logDir: logDir}
+	})
+
+	readLog := func() string {
+		logs, err := filepath.Glob(filepath.Join(logDir, "command-*.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logs).To(HaveLen(1))
+		data, err := os.ReadFile(logs[0])
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	It("should copy the output to both the log and the tee", func() {
+		tee := new(strings.Builder)
+		handle, err := executor.Start(GinkgoT().Context(), "sh", []string{"-c", "echo out; echo err >&2"}, WithOutputTee(tee))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(handle.Wait()).To(Succeed())
+		Expect(tee.String()).To(ContainSubstring("out\n"))
+		Expect(tee.String()).To(ContainSubstring("err\n"))
+		Expect(readLog()).To(And(ContainSubstring("out\n"), ContainSubstring("err\n")))
+	})
+
+	It("should keep the output written after a while", func() {
+		tee := new(strings.Builder)
+		handle, err := executor.Start(GinkgoT().Context(), "sh", []string{"-c", "sleep 0.2; echo late"}, WithOutputTee(tee))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(handle.Wait()).To(Succeed())
+		Expect(tee.String()).To(Equal("late\n"))
+	})
+
+	It("should report the exit status", func() {
+		handle, err := executor.Start(GinkgoT().Context(), "sh", []string{"-c", "exit 3"})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Callers of Run have never treated the exit status as an error.
+		Expect(handle.Wait()).To(Succeed())
+		Expect(handle.ProcessState().ExitCode()).To(Equal(3))
+	})
+
+	It("should capture the output", func() {
+		output, err := RunWithOutput(GinkgoT().Context(), executor, "echo", []string{"hello"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output).To(Equal("hello\n"))
+	})
+})
@@ -7,6 +7,26 @@ export type WorldGeneration = {
   gen: string;
   id: string;
   timestamp: number;
+  size: number;
+  format: string;
+  minecraftVersion?: string;
+  createdBy?: string;
+  pinned: boolean;
+  autoBackup: boolean;
+  corrupt: boolean;
+  level?: LevelInfo;
+};
+
+export type LevelInfo = {
+  dataVersion: number;
+  minecraftVersion: string;
+  seed: string;
+  gameMode: string;
+  difficulty: string;
+  hardcore: boolean;
+  lastPlayed: number;
+  spawn: {x: number; y: number; z: number};
+  datapacks: string[];
 };
 
 export type World = {
@@ -39,12 +59,19 @@ export type SystemInfo = {
   premisesVersion: string;
   hostOs: string;
   ipAddr: string | null;
+  commandLine: string[] | null;
+};
+
+export type InstalledDatapack = {
+  name: string;
+  enabled: boolean;
 };
 
 export type WorldInfo = {
   version: string;
   worldName: string;
   seed: string;
+  datapacks: InstalledDatapack[];
 };
 
 export type StatusExtraData = {
@@ -59,6 +86,8 @@ export type SnapshotConfiguration = {
 export type PendingConfig = {
   machineType?: string;
   serverVersion?: string;
+  serverFlavor?: string;
+  modSet?: string;
   guessServerVersion?: boolean;
   worldSource?: string;
   worldName?: string;
@@ -70,6 +99,11 @@ export type PendingConfig = {
   inactiveTimeout?: number;
   otlpEndpoint?: string;
   metricExportIntervalSec?: number;
+  backupFormat?: string;
+  autoBackupInterval?: number;
+  maxCrashLoops?: number;
+  jvmProfile?: string;
+  jvmArgs?: string[];
 };
 
 export type ConfigAndValidity = {
@@ -90,6 +124,104 @@ export type DelegatedURL = {
   url: string;
 };
 
+export type WorldUploadLink = {
+  url: string;
+  id: string;
+};
+
+export type CompleteWorldUploadLinkReq = {
+  id: string;
+};
+
+export type WorldUploadStatus = {
+  status: 'validating' | 'completed' | 'failed';
+  generation?: WorldGeneration;
+  errorCode?: number;
+};
+
 export type DeleteWorldInput = {
   id: string;
 };
+
+export type CopyWorldReq = {
+  worldName: string;
+  newWorldName: string;
+};
+
+export type ForkWorldReq = {
+  id: string;
+  newWorldName: string;
+};
+
+export type Datapack = {
+  name: string;
+  size: number;
+  timestamp: number;
+};
+
+export type CreateDatapackUploadLinkReq = {
+  name: string;
+};
+
+export type DatapackUploadLink = {
+  url: string;
+  id: string;
+};
+
+export type CompleteDatapackUploadLinkReq = {
+  id: string;
+};
+
+export type DeleteDatapackReq = {
+  name: string;
+};
+
+export type WorldDatapacks = {
+  worldName: string;
+  datapacks: string[];
+};
+
+export type ModSetFile = {
+  name: string;
+  size: number;
+  sha256: string;
+  timestamp: number;
+};
+
+export type ModSet = {
+  name: string;
+  files: ModSetFile[];
+};
+
+export type CreateModSetUploadLinkReq = {
+  modSet: string;
+  name: string;
+};
+
+export type ModSetUploadLink = {
+  url: string;
+  id: string;
+};
+
+export type CompleteModSetUploadLinkReq = {
+  id: string;
+};
+
+export type DeleteModSetFileReq = {
+  modSet: string;
+  name: string;
+};
+
+export type DeleteModSetReq = {
+  name: string;
+};
+
+export type StopReq = {
+  delay: number;
+};
+
+export type CrashReport = {
+  id: string;
+  url: string;
+  timestamp: number;
+};
@@ -3,21 +3,41 @@ import useSWR, {type KeyedMutator} from 'swr';
 import useSWRImmutable from 'swr/immutable';
 
 import type {
+  CompleteDatapackUploadLinkReq,
+  CompleteModSetUploadLinkReq,
+  CompleteWorldUploadLinkReq,
   ConfigAndValidity,
+  CopyWorldReq,
+  CrashReport,
+  CreateDatapackUploadLinkReq,
+  CreateModSetUploadLinkReq,
   CreateWorldDownloadLinkReq,
   CreateWorldUploadLinkReq,
+  Datapack,
+  DatapackUploadLink,
   DelegatedURL,
+  DeleteDatapackReq,
+  DeleteModSetFileReq,
+  DeleteModSetReq,
   DeleteWorldInput,
+  ForkWorldReq,
   MCVersion,
+  ModSet,
+  ModSetFile,
+  ModSetUploadLink,
   PasswordCredential,
   PendingConfig,
   SessionData,
   SessionState,
   SnapshotConfiguration,
+  StopReq,
   SystemInfo,
   UpdatePassword,
   World,
-  WorldInfo
+  WorldDatapacks,
+  WorldInfo,
+  WorldUploadLink,
+  WorldUploadStatus
 } from './entities';
 
 const domain = process.env.NODE_ENV === 'test' ? 'http://localhost' : '';
@@ -67,15 +87,32 @@ export const changePassword = declareApi<UpdatePassword, null>('/api/v1/users/ch
 export const addUser = declareApi<PasswordCredential, null>('/api/v1/users/add', 'post');
 export const getSystemInfo = declareApi<null, SystemInfo>('/api/v1/systeminfo');
 export const getWorldInfo = declareApi<null, WorldInfo>('/api/v1/worldinfo');
+export const getCrashReport = declareApi<null, CrashReport>('/api/v1/crashreport');
 export const takeQuickSnapshot = declareApi<SnapshotConfiguration, null>('/api/v1/quickundo/snapshot', 'post');
 export const undoQuickSnapshot = declareApi<SnapshotConfiguration, null>('/api/v1/quickundo/undo', 'post');
 export const getConfig = declareApi<null, ConfigAndValidity>('/api/v1/config');
 export const updateConfig = declareApi<PendingConfig, ConfigAndValidity>('/api/v1/config', 'put');
 export const launch = declareApi<null, null>('/api/v1/launch', 'post');
-export const stop = declareApi<null, null>('/api/v1/stop', 'post');
+export const stop = declareApi<StopReq, null>('/api/v1/stop', 'post');
+export const cancelStop = declareApi<null, null>('/api/v1/stop/cancel', 'post');
 export const createWorldDownloadLink = declareApi<CreateWorldDownloadLinkReq, DelegatedURL>('/api/v1/world-link/download', 'post');
-export const createWorldUploadLink = declareApi<CreateWorldUploadLinkReq, DelegatedURL>('/api/v1/world-link/upload', 'post');
+export const createWorldUploadLink = declareApi<CreateWorldUploadLinkReq, WorldUploadLink>('/api/v1/world-link/upload', 'post');
+export const completeWorldUpload = declareApi<CompleteWorldUploadLinkReq, WorldUploadStatus>('/api/v1/world-link/upload/complete', 'post');
+export const getWorldUploadStatus = declareApi<CompleteWorldUploadLinkReq, WorldUploadStatus>('/api/v1/world-link/upload/status', 'post');
 export const deleteWorld = declareApi<DeleteWorldInput, null>('/api/v1/worlds', 'delete');
+export const renameWorld = declareApi<CopyWorldReq, null>('/api/v1/worlds/rename', 'post');
+export const duplicateWorld = declareApi<CopyWorldReq, null>('/api/v1/worlds/duplicate', 'post');
+export const forkWorld = declareApi<ForkWorldReq, null>('/api/v1/worlds/fork', 'post');
+export const listDatapacks = declareApi<null, Datapack[]>('/api/v1/datapacks');
+export const createDatapackUploadLink = declareApi<CreateDatapackUploadLinkReq, DatapackUploadLink>('/api/v1/datapacks/upload', 'post');
+export const completeDatapackUpload = declareApi<CompleteDatapackUploadLinkReq, Datapack>('/api/v1/datapacks/upload/complete', 'post');
+export const deleteDatapack = declareApi<DeleteDatapackReq, null>('/api/v1/datapacks', 'delete');
+export const updateWorldDatapacks = declareApi<WorldDatapacks, WorldDatapacks>('/api/v1/worlds/datapacks', 'put');
+export const listModSets = declareApi<null, ModSet[]>('/api/v1/modsets');
+export const createModSetUploadLink = declareApi<CreateModSetUploadLinkReq, ModSetUploadLink>('/api/v1/modsets/upload', 'post');
+export const completeModSetUpload = declareApi<CompleteModSetUploadLinkReq, ModSetFile>('/api/v1/modsets/upload/complete', 'post');
+export const deleteModSetFile = declareApi<DeleteModSetFileReq, null>('/api/v1/modsets/files', 'delete');
+export const deleteModSet = declareApi<DeleteModSetReq, null>('/api/v1/modsets', 'delete');
 
 export type ImmutableUseResponse<T> = {
   data: T | undefined;
@@ -107,6 +144,16 @@ export const useWorlds = (accessToken: string | null): MutableUseResponse<World[
   };
 };
 
+export const useModSets = (accessToken: string | null): MutableUseResponse<ModSet[]> => {
+  const {data, error, isLoading, mutate} = useSWR('/api/modsets', () => listModSets(accessToken));
+  return {
+    data,
+    error,
+    isLoading,
+    mutate
+  };
+};
+
 export const useMCVersions = (accessToken: string | null): ImmutableUseResponse<MCVersion[]> => {
   const {data, error, isLoading} = useSWRImmutable('/api/mcversions', () => getMCVersions(accessToken));
   return {
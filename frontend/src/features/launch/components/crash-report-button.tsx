@@ -0,0 +1,46 @@
+import {BugReport as CrashReportIcon} from '@mui/icons-material';
+import {Button} from '@mui/material';
+import {useEffect, useState} from 'react';
+import {useTranslation} from 'react-i18next';
+
+import {getCrashReport} from '@/api';
+import type {CrashReport} from '@/api/entities';
+import {useAuth} from '@/utils/auth';
+
+type Props = {
+  // Crash reports older than this (in milliseconds) are not shown.
+  maxAge?: number;
+  // The report is fetched again when this changes.
+  reportId?: string;
+};
+
+const CrashReportButton = ({maxAge, reportId}: Props) => {
+  const [t] = useTranslation();
+
+  const {accessToken} = useAuth();
+
+  const [report, setReport] = useState<CrashReport | null>(null);
+
+  useEffect(() => {
+    (async () => {
+      try {
+        setReport(await getCrashReport(accessToken));
+      } catch (err) {
+        // The server has never crashed, or the report has expired.
+        setReport(null);
+      }
+    })();
+  }, [reportId]);
+
+  if (!report || (maxAge && report.timestamp < Date.now() - maxAge)) {
+    return null;
+  }
+
+  return (
+    <Button href={report.url} rel="noopener noreferrer" startIcon={<CrashReportIcon />} sx={{mx: 1}} target="_blank" variant="outlined">
+      {t('launch.crash_report')}
+    </Button>
+  );
+};
+
+export default CrashReportButton;
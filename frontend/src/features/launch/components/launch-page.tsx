@@ -2,6 +2,7 @@ import {PlayArrow as StartIcon} from '@mui/icons-material';
 import {Box, Button, Card} from '@mui/material';
 import {useTranslation} from 'react-i18next';
 
+import CrashReportButton from './crash-report-button';
 import {useLaunchConfig} from './launch-config';
 import MenuContainer from './menu-container';
 import {create as gameConfigMenu} from './menus/game-config';
@@ -10,6 +11,9 @@ import {create as machineTypeMenu} from './menus/machine-type';
 import {create as newWorldSettingsMenu} from './menus/new-world-settings';
 import {create as worldMenu} from './menus/world';
 
+// Crash reports are shown on the launch page only for a while, as they are usually irrelevant to the next launch.
+const CRASH_REPORT_MAX_AGE = 24 * 60 * 60 * 1000;
+
 const LaunchPage = () => {
   const [t] = useTranslation();
 
@@ -31,6 +35,7 @@ const LaunchPage = () => {
         items={[machineTypeMenu(), gameConfigMenu(), extraGameConfigMenu(), worldMenu(), newWorldSettingsMenu()]}
         menuFooter={
           <Box sx={{textAlign: 'end'}}>
+            <CrashReportButton maxAge={CRASH_REPORT_MAX_AGE} />
             <Button disabled={!isValid} onClick={handleStart} startIcon={<StartIcon />} sx={{mx: 1}} type="button" variant="contained">
               {t('launch.launch')}
             </Button>
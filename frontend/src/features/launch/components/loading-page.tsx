@@ -1,10 +1,24 @@
 import {CircularProgress} from '@mui/material';
 import {Box} from '@mui/system';
 
+import {useRunnerStatus} from '@/utils/runner-status';
+
+import CrashReportButton from './crash-report-button';
+
+// EVENT_CRASHED is the event code with which the runner reports crashes of the server.
+const EVENT_CRASHED = 10;
+
 const LoadingPage = () => {
+  const {statusCode, extra} = useRunnerStatus();
+
   return (
     <Box sx={{mt: 12, textAlign: 'center'}}>
       <CircularProgress />
+      {statusCode === EVENT_CRASHED && extra.textData && (
+        <Box sx={{mt: 4}}>
+          <CrashReportButton reportId={extra.textData} />
+        </Box>
+      )}
     </Box>
   );
 };
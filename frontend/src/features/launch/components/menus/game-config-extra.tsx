@@ -5,13 +5,17 @@ import {
   Dialog,
   DialogContent,
   DialogTitle,
+  FormControl,
   IconButton,
+  InputLabel,
   List,
   ListItem,
   ListItemButton,
   ListItemIcon,
   ListItemText,
   ListSubheader,
+  MenuItem as MUIMenuItem,
+  Select,
   Stack,
   Switch,
   Tooltip
@@ -24,10 +28,20 @@ import {useLaunchConfig} from '../launch-config';
 import type {MenuItem} from '../menu-container';
 import ServerPropsDialog from '../server-props-dialog';
 
+// DEFAULT_MAX_CRASH_LOOPS is the number of crash loops which the runner allows if it's not configured.
+const DEFAULT_MAX_CRASH_LOOPS = 5;
+
+const jvmProfiles = ['default', 'aikar', 'zgc', 'custom'];
+
+// ZGC_MIN_MACHINE_MEMORY is the memory in GiB of the smallest machine type which can use ZGC.
+const ZGC_MIN_MACHINE_MEMORY = 12;
+
 enum OpenedDialog {
   NONE,
   MOTD,
   INACTIVE_TIMEOUT,
+  MAX_CRASH_LOOPS,
+  JVM_PROFILE,
   O11Y,
   SERVER_PROPS
 }
@@ -43,6 +57,10 @@ export const create = (): MenuItem => {
   const inactiveTimeout = config.inactiveTimeout || -1;
   const otlpEndpoint = config.otlpEndpoint || '';
   const metricExportIntervalSec = config.metricExportIntervalSec || 10;
+  const maxCrashLoops = config.maxCrashLoops || DEFAULT_MAX_CRASH_LOOPS;
+  const jvmProfile = config.jvmProfile || 'default';
+  const jvmArgs = config.jvmArgs || [];
+  const zgcAvailable = parseInt(config.machineType || '0', 10) >= ZGC_MIN_MACHINE_MEMORY;
 
   const [openedDialog, setOpenedDialog] = useState(OpenedDialog.NONE);
 
@@ -72,6 +90,18 @@ export const create = (): MenuItem => {
     updateConfig({inactiveTimeout: parseInt(minutes, 10)});
   };
 
+  const setMaxCrashLoops = (count: string) => {
+    updateConfig({maxCrashLoops: parseInt(count, 10)});
+  };
+
+  const setJvmProfile = (profile: string) => {
+    updateConfig({jvmProfile: profile});
+  };
+
+  const setJvmArgs = (args: string) => {
+    updateConfig({jvmArgs: args.split(/\s+/).filter((arg) => arg !== '')});
+  };
+
   const setOtlpEndpoint = (otlpEndpoint: string) => {
     if (otlpEndpoint === '' || otlpEndpoint.match(/^https?:\/\/[-a-zA-Z0-9.]{1,253}:[0-9]{1,5}/)) {
       updateConfig({otlpEndpoint: otlpEndpoint});
@@ -121,6 +151,31 @@ export const create = (): MenuItem => {
             </ListItemButton>
           </ListItem>
 
+          <ListItem>
+            <ListItemButton disableGutters onClick={() => setOpenedDialog(OpenedDialog.MAX_CRASH_LOOPS)}>
+              <ListItemText
+                primary={
+                  <>
+                    {t('launch.server_extra.max_crash_loops')}
+                    <Tooltip title={t('launch.server_extra.max_crash_loops.notice')}>
+                      <InfoIcon sx={{opacity: 0.6}} />
+                    </Tooltip>
+                  </>
+                }
+                secondary={t('launch.server_extra.max_crash_loops.times', {count: maxCrashLoops})}
+              />
+            </ListItemButton>
+          </ListItem>
+
+          <ListItem>
+            <ListItemButton disableGutters onClick={() => setOpenedDialog(OpenedDialog.JVM_PROFILE)}>
+              <ListItemText
+                primary={t('launch.server_extra.jvm_profile')}
+                secondary={jvmProfile === 'custom' && jvmArgs.length > 0 ? jvmArgs.join(' ') : t(`launch.server_extra.jvm_profile.${jvmProfile}`)}
+              />
+            </ListItemButton>
+          </ListItem>
+
           <ListItem>
             <ListItemButton disableGutters onClick={() => setOpenedDialog(OpenedDialog.O11Y)}>
               <ListItemText primary={t('launch.server_extra.o11y')} secondary={otlpEndpoint || <em>{t('launch.server_extra.o11y.not_set')}</em>} />
@@ -199,6 +254,58 @@ export const create = (): MenuItem => {
           </DialogContent>
         </Dialog>
 
+        <Dialog onClose={() => setOpenedDialog(OpenedDialog.NONE)} open={openedDialog === OpenedDialog.MAX_CRASH_LOOPS}>
+          <DialogTitle>{t('launch.server_extra.max_crash_loops')}</DialogTitle>
+          <DialogContent sx={{mb: 1}}>
+            <Box sx={{mt: 1}}>
+              <SaveInput
+                fullWidth
+                initValue={maxCrashLoops.toString()}
+                label={t('launch.server_extra.max_crash_loops.input_label')}
+                onSave={(value) => {
+                  setMaxCrashLoops(value);
+                  setOpenedDialog(OpenedDialog.NONE);
+                }}
+                type="number"
+              />
+            </Box>
+          </DialogContent>
+        </Dialog>
+
+        <Dialog onClose={() => setOpenedDialog(OpenedDialog.NONE)} open={openedDialog === OpenedDialog.JVM_PROFILE}>
+          <DialogTitle>{t('launch.server_extra.jvm_profile')}</DialogTitle>
+          <DialogContent sx={{mb: 1}}>
+            <Stack spacing={2} sx={{mt: 1, minWidth: 500}}>
+              <FormControl fullWidth>
+                <InputLabel id="jvm-profile-select-label">{t('launch.server_extra.jvm_profile')}</InputLabel>
+                <Select
+                  label={t('launch.server_extra.jvm_profile')}
+                  labelId="jvm-profile-select-label"
+                  onChange={(e) => setJvmProfile(e.target.value)}
+                  value={jvmProfile}
+                >
+                  {jvmProfiles.map((profile) => (
+                    <MUIMenuItem disabled={profile === 'zgc' && !zgcAvailable} key={profile} value={profile}>
+                      {t(`launch.server_extra.jvm_profile.${profile}`)}
+                    </MUIMenuItem>
+                  ))}
+                </Select>
+              </FormControl>
+              {jvmProfile === 'custom' && (
+                <SaveInput
+                  fullWidth
+                  initValue={jvmArgs.join(' ')}
+                  label={t('launch.server_extra.jvm_profile.args_input_label')}
+                  onSave={(value) => {
+                    setJvmArgs(value);
+                  }}
+                  type="text"
+                />
+              )}
+            </Stack>
+          </DialogContent>
+        </Dialog>
+
         <Dialog onClose={() => setOpenedDialog(OpenedDialog.NONE)} open={openedDialog === OpenedDialog.O11Y}>
           <DialogTitle>{t('launch.server_extra.o11y')}</DialogTitle>
           <DialogContent sx={{mb: 1}}>
@@ -2,13 +2,15 @@ import {InfoOutlined as InfoIcon} from '@mui/icons-material';
 import {Box, FormControl, FormControlLabel, FormGroup, InputLabel, MenuItem as MUIMenuItem, Select, Switch, Tooltip} from '@mui/material';
 import {useState} from 'react';
 import {useTranslation} from 'react-i18next';
-import {useMCVersions} from '@/api';
+import {useMCVersions, useModSets} from '@/api';
 import Loading from '@/components/loading';
 import {useAuth} from '@/utils/auth';
 import {useLaunchConfig} from '../launch-config';
 import type {MenuItem} from '../menu-container';
 import {valueLabel} from './common';
 
+const serverFlavors = ['vanilla', 'fabric', 'quilt', 'paper', 'forge', 'neoforge'];
+
 export const create = (): MenuItem => {
   const [t] = useTranslation();
   const {config, updateConfig} = useLaunchConfig();
@@ -17,6 +19,8 @@ export const create = (): MenuItem => {
 
   const serverVersion = config.serverVersion || '';
   const guessServerVersion = !!config.guessServerVersion;
+  const serverFlavor = config.serverFlavor || 'vanilla';
+  const modSet = config.modSet || '';
 
   const setGuessServerVersion = (enable: boolean) => {
     updateConfig({
@@ -30,6 +34,20 @@ export const create = (): MenuItem => {
     });
   };
 
+  const setServerFlavor = (flavor: string) => {
+    updateConfig({
+      serverFlavor: flavor
+    });
+  };
+
+  const setModSet = (name: string) => {
+    updateConfig({
+      modSet: name
+    });
+  };
+
+  const {data: modSets} = useModSets(accessToken);
+
   const [showStable, setShowStable] = useState(true);
   const [showSnapshot, setShowSnapshot] = useState(false);
   const [showAlpha, setShowAlpha] = useState(false);
@@ -100,9 +118,45 @@ export const create = (): MenuItem => {
             label={t('launch.server_version.alpha')}
           />
         </FormGroup>
+
+        <FormControl fullWidth sx={{mt: 3}}>
+          <InputLabel id="server-flavor-select-label">{t('launch.server_version.flavor')}</InputLabel>
+          <Select
+            label={t('launch.server_version.flavor')}
+            labelId="server-flavor-select-label"
+            onChange={(e) => setServerFlavor(e.target.value)}
+            value={serverFlavor}
+          >
+            {serverFlavors.map((flavor) => (
+              <MUIMenuItem key={flavor} value={flavor}>
+                {t(`launch.server_version.flavor.${flavor}`)}
+              </MUIMenuItem>
+            ))}
+          </Select>
+        </FormControl>
+        {serverFlavor !== 'vanilla' && (
+          <FormControl fullWidth sx={{mt: 3}}>
+            <InputLabel id="mod-set-select-label">{t(serverFlavor === 'paper' ? 'launch.server_version.plugin_set' : 'launch.server_version.mod_set')}</InputLabel>
+            <Select
+              label={t(serverFlavor === 'paper' ? 'launch.server_version.plugin_set' : 'launch.server_version.mod_set')}
+              labelId="mod-set-select-label"
+              onChange={(e) => setModSet(e.target.value)}
+              value={modSet}
+            >
+              <MUIMenuItem value="">{t('launch.server_version.mod_set.none')}</MUIMenuItem>
+              {modSets?.map((e) => (
+                <MUIMenuItem key={e.name} value={e.name}>
+                  {t('launch.server_version.mod_set.item', {name: e.name, count: e.files.length})}
+                </MUIMenuItem>
+              ))}
+            </Select>
+          </FormControl>
+        )}
       </>
     ),
-    detail: valueLabel(config.serverVersion),
+    detail: valueLabel(config.serverVersion, (version) =>
+      serverFlavor === 'vanilla' ? version : `${version} (${t(`launch.server_version.flavor.${serverFlavor}`)})`
+    ),
     variant: 'dialog',
     cancellable: true
   };
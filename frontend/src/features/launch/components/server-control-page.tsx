@@ -1,7 +1,8 @@
 import {Info as InfoIcon, Stop as StopIcon, History as UndoIcon, Public as WorldIcon} from '@mui/icons-material';
-import {Box, Button, Card, Stack} from '@mui/material';
+import {Button, Card, MenuItem, Stack, TextField} from '@mui/material';
+import {useState} from 'react';
 import {useTranslation} from 'react-i18next';
-import {stop} from '@/api';
+import {cancelStop, stop} from '@/api';
 import {useAuth} from '@/utils/auth';
 import MenuContainer from './menu-container';
 import QuickUndo from './quickundo';
@@ -13,6 +14,8 @@ const ServerControlPane = () => {
 
   const {accessToken} = useAuth();
 
+  const [stopDelay, setStopDelay] = useState(0);
+
   return (
     <Card sx={{p: 2, mt: 6}} variant="outlined">
       <MenuContainer
@@ -41,17 +44,38 @@ const ServerControlPane = () => {
         ]}
         menuFooter={
           <Stack spacing={1}>
-            <Box sx={{textAlign: 'end'}}>
+            <Stack direction="row" justifyContent="flex-end" spacing={1}>
+              <TextField
+                label={t('launch.stop.delay')}
+                onChange={(e) => setStopDelay(Number(e.target.value))}
+                select
+                size="small"
+                value={stopDelay}
+              >
+                {[0, 60, 300, 600].map((delay) => (
+                  <MenuItem key={delay} value={delay}>
+                    {delay === 0 ? t('launch.stop.delay.now') : t('launch.stop.delay.minutes', {minutes: delay / 60})}
+                  </MenuItem>
+                ))}
+              </TextField>
+              <Button
+                onClick={() => {
+                  cancelStop(accessToken);
+                }}
+                variant="outlined"
+              >
+                {t('launch.stop.cancel')}
+              </Button>
               <Button
                 onClick={() => {
-                  stop(accessToken);
+                  stop(accessToken, {delay: stopDelay});
                 }}
                 startIcon={<StopIcon />}
                 variant="contained"
               >
                 {t('launch.stop')}
               </Button>
-            </Box>
+            </Stack>
           </Stack>
         }
       />
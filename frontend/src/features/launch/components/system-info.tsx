@@ -37,6 +37,9 @@ const SystemInfo = () => {
         <CopyableListItem title={t('launch.system_info.runner_build')}>
           {systemInfo ? systemInfo.premisesVersion : <DelayedSkeleton width="25%" />}
         </CopyableListItem>
+        {systemInfo?.commandLine && systemInfo.commandLine.length > 0 && (
+          <CopyableListItem title={t('launch.system_info.command_line')}>{systemInfo.commandLine.join(' ')}</CopyableListItem>
+        )}
       </List>
     </Box>
   );
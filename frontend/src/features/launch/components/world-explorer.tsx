@@ -7,7 +7,7 @@ import {
   Upload as UploadIcon,
   Public as WorldIcon
 } from '@mui/icons-material';
-import {Button, ButtonGroup, colors, IconButton, ListItemIcon, ListItemText, Menu, MenuItem, Stack, TextField} from '@mui/material';
+import {Button, ButtonGroup, colors, IconButton, ListItemIcon, ListItemText, Menu, MenuItem, Stack, TextField, Tooltip} from '@mui/material';
 import {SimpleTreeView, TreeItem} from '@mui/x-tree-view';
 import type React from 'react';
 import {useState} from 'react';
@@ -15,8 +15,8 @@ import {useForm} from 'react-hook-form';
 import {useTranslation} from 'react-i18next';
 import {toast} from 'react-toastify';
 
-import {APIError, createWorldDownloadLink, createWorldUploadLink, deleteWorld} from '@/api';
-import type {World, WorldGeneration} from '@/api/entities';
+import {APIError, completeWorldUpload, createWorldDownloadLink, createWorldUploadLink, deleteWorld, getWorldUploadStatus} from '@/api';
+import type {LevelInfo, World, WorldGeneration} from '@/api/entities';
 import {useAuth} from '@/utils/auth';
 
 type Selection = {
@@ -41,6 +41,22 @@ const getWorldLabel = (worldGen: WorldGeneration): string => {
   return label;
 };
 
+const LevelDetails = ({level}: {level: LevelInfo}) => {
+  const [t] = useTranslation();
+
+  return (
+    <Stack>
+      <div>{t('launch.world.level.game_version', {version: level.minecraftVersion, dataVersion: level.dataVersion})}</div>
+      <div>{t('launch.world.level.seed', {seed: level.seed})}</div>
+      <div>{t('launch.world.level.game_mode', {gameMode: level.gameMode, difficulty: level.difficulty})}</div>
+      {level.hardcore && <div>{t('launch.world.level.hardcore')}</div>}
+      {level.lastPlayed > 0 && <div>{t('launch.world.level.last_played', {lastPlayed: new Date(level.lastPlayed).toLocaleString()})}</div>}
+      <div>{t('launch.world.level.spawn', level.spawn)}</div>
+      {level.datapacks.length > 0 && <div>{t('launch.world.level.datapacks', {datapacks: level.datapacks.join(', ')})}</div>}
+    </Stack>
+  );
+};
+
 const WorldExplorer = ({worlds, selection, onChange, refresh}: Props) => {
   const [t] = useTranslation();
 
@@ -107,7 +123,15 @@ const WorldExplorer = ({worlds, selection, onChange, refresh}: Props) => {
   const items = worlds?.map((world) => (
     <TreeItem key={world.worldName} itemId={world.worldName} label={world.worldName}>
       {world.generations.map((gen) => (
-        <TreeItem key={gen.id} itemId={gen.id} label={<div onContextMenu={handleContextMenu(gen.id)}>{getWorldLabel(gen)}</div>} />
+        <TreeItem
+          key={gen.id}
+          itemId={gen.id}
+          label={
+            <Tooltip placement="right" title={gen.level && <LevelDetails level={gen.level} />}>
+              <div onContextMenu={handleContextMenu(gen.id)}>{getWorldLabel(gen)}</div>
+            </Tooltip>
+          }
+        />
       ))}
     </TreeItem>
   ));
@@ -130,11 +154,23 @@ const WorldExplorer = ({worlds, selection, onChange, refresh}: Props) => {
       }
 
       try {
-        const {url} = await createWorldUploadLink(accessToken, {worldName, mimeType: file.type});
-        await fetch(url, {
+        const {url, id} = await createWorldUploadLink(accessToken, {worldName, mimeType: file.type});
+        const resp = await fetch(url, {
           method: 'PUT',
           body: file
         });
+        if (!resp.ok) {
+          throw new APIError(t('error.code_2'));
+        }
+        // The archive is validated in background.
+        let status = await completeWorldUpload(accessToken, {id});
+        while (status.status === 'validating') {
+          await new Promise((resolve) => setTimeout(resolve, 3000));
+          status = await getWorldUploadStatus(accessToken, {id});
+        }
+        if (status.status === 'failed') {
+          throw new APIError(t(`error.code_${status.errorCode}`));
+        }
       } catch (err) {
         if (err instanceof APIError) {
           toast.error(err.message);
@@ -165,7 +201,7 @@ const WorldExplorer = ({worlds, selection, onChange, refresh}: Props) => {
                 variant="outlined"
                 {...register('worldName', {
                   required: true,
-                  validate: (val: string) => !val.includes('/') && !val.includes('\\') && !val.includes('@')
+                  validate: (val: string) => val !== '.' && val !== '..' && !val.includes('/') && !val.includes('\\') && !val.includes('@')
                 })}
               />
               <Button loading={isUploading} type="submit" variant="outlined">
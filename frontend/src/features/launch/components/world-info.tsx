@@ -28,6 +28,13 @@ const WorldInfo = () => {
     })();
   }, []);
 
+  const datapacks = (worldInfo: WorldInfoEntity) => {
+    if (!worldInfo.datapacks || worldInfo.datapacks.length === 0) {
+      return t('launch.world_info.datapacks.none');
+    }
+    return worldInfo.datapacks.map((d) => (d.enabled ? d.name : t('launch.world_info.datapacks.disabled', {name: d.name}))).join(', ');
+  };
+
   return (
     <Box>
       <List disablePadding>
@@ -40,6 +47,9 @@ const WorldInfo = () => {
         <CopyableListItem key="seed" title={t('launch.world_info.seed')}>
           {worldInfo ? worldInfo.seed : <DelayedSkeleton width="25%" />}
         </CopyableListItem>
+        <CopyableListItem key="datapacks" title={t('launch.world_info.datapacks')}>
+          {worldInfo ? datapacks(worldInfo) : <DelayedSkeleton width="25%" />}
+        </CopyableListItem>
       </List>
     </Box>
   );